@@ -0,0 +1,146 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+	"github.com/spf13/cobra"
+)
+
+var statementCmdOpts struct {
+	Mini       bool
+	From       string
+	To         string
+	Cursor     string
+	Limit      uint32
+	Operations []string
+}
+
+// balanceCmd represents the balance command
+var balanceCmd = &cobra.Command{
+	Use:          "balance",
+	Short:        "Prints the balance of the account of the profile",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: withProfile(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewWalletOperationsClient(conn)
+
+		res, err := client.Balance(ctx, &v1.BalanceRequest{})
+		if err != nil {
+			return callError(err)
+		}
+
+		return printMessage(res, table{
+			Header: []string{"ACCOUNT", "BALANCE", "OVERDRAFT LIMIT", "OVERDRAFT"},
+			Rows: [][]string{{
+				res.AccountNumber,
+				formatRupees(res.Balance),
+				formatRupees(res.OverdraftLimit),
+				formatRupees(res.OverdraftBalance),
+			}},
+		})
+	}),
+}
+
+// statementCmd represents the statement command
+var statementCmd = &cobra.Command{
+	Use:   "statement",
+	Short: "Prints the transactions on the account of the profile",
+	Long: `Prints a page of the transactions on the account of the profile in a period,
+newest first. The next page is printed by passing the cursor of the page before.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: withProfile(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewWalletOperationsClient(conn)
+
+		if statementCmdOpts.Mini {
+			res, err := client.MiniStatement(ctx, &v1.MiniStatementRequest{})
+			if err != nil {
+				return callError(err)
+			}
+
+			return printMessage(res, statementTable(res.Entries))
+		}
+
+		res, err := client.Statement(ctx, &v1.StatementRequest{
+			From:       statementCmdOpts.From,
+			To:         statementCmdOpts.To,
+			Cursor:     statementCmdOpts.Cursor,
+			Limit:      statementCmdOpts.Limit,
+			Operations: statementCmdOpts.Operations,
+		})
+		if err != nil {
+			return callError(err)
+		}
+
+		if rootCmdOpts.Output != outputJSON {
+			fmt.Fprintf(os.Stderr, "Opening balance %s, closing balance %s\n", formatRupees(res.OpeningBalance), formatRupees(res.ClosingBalance))
+			if res.NextCursor != "" {
+				fmt.Fprintf(os.Stderr, "More transactions with --cursor %s\n", res.NextCursor)
+			}
+		}
+
+		return printMessage(res, statementTable(res.Entries))
+	}),
+}
+
+func statementTable(entries []*v1.StatementEntry) table {
+	tbl := table{Header: []string{"DATE", "OPERATION", "CREDIT", "DEBIT", "BALANCE", "COUNTERPARTY"}}
+	for _, entry := range entries {
+		var counterparty string
+		if cp := entry.Counterparty; cp != nil {
+			counterparty = fmt.Sprintf("%s (%s)", cp.UserId, cp.UserType)
+		}
+		tbl.Rows = append(tbl.Rows, []string{
+			entry.CreatedAt.AsTime().Local().Format("2006-01-02 15:04:05"),
+			entry.Operation,
+			formatRupees(entry.CreditedAmount),
+			formatRupees(entry.DebitedAmount),
+			formatRupees(entry.RunningBalance),
+			counterparty,
+		})
+	}
+	return tbl
+}
+
+func formatRupees(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+func init() {
+	statementCmd.Flags().BoolVar(&statementCmdOpts.Mini, "mini", false, "print the most recent transactions only")
+	statementCmd.Flags().StringVar(&statementCmdOpts.From, "from", "", "first day of the period as YYYY-MM-DD (defaults to 30 days ago)")
+	statementCmd.Flags().StringVar(&statementCmdOpts.To, "to", "", "last day of the period as YYYY-MM-DD (defaults to today)")
+	statementCmd.Flags().StringVar(&statementCmdOpts.Cursor, "cursor", "", "cursor of the page to print, as printed with the page before")
+	statementCmd.Flags().Uint32Var(&statementCmdOpts.Limit, "limit", 0, "transactions per page, at most 100 (defaults to 20)")
+	statementCmd.Flags().StringSliceVar(&statementCmdOpts.Operations, "operation", nil, "only print transactions of these operations, e.g. DEPOSIT,TRANSFER")
+
+	rootCmd.AddCommand(balanceCmd)
+	rootCmd.AddCommand(statementCmd)
+}
@@ -0,0 +1,167 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+	"github.com/spf13/cobra"
+)
+
+// assignFloatCmd represents the assign-float command
+var assignFloatCmd = &cobra.Command{
+	Use:          "assign-float <account-number> <amount>",
+	Short:        "Credits a super agent with rupees of float, as an administrator",
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: withProfile(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+		amount, err := parseRupees(args[1])
+		if err != nil {
+			return err
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewWalletOperationsClient(conn)
+
+		res, err := client.AssignFloat(ctx, &v1.AssignFloatRequest{
+			AccountNumber: args[0],
+			Amount:        amount,
+		})
+		if err != nil {
+			return callError(err)
+		}
+
+		return printMessage(res, table{
+			Header: []string{"ACCOUNT", "BALANCE"},
+			Rows:   [][]string{{args[0], formatRupees(res.Balance)}},
+		})
+	}),
+}
+
+// tariffCmd represents the tariff command
+var tariffCmd = &cobra.Command{
+	Use:   "tariff",
+	Short: "Manages the charges of transactions, as an administrator",
+}
+
+// tariffGetCmd represents the tariff get command
+var tariffGetCmd = &cobra.Command{
+	Use:          "get",
+	Short:        "Prints the charge of every transaction",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: withProfile(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewWalletOperationsClient(conn)
+
+		res, err := client.GetTariff(ctx, &v1.GetTariffRequest{})
+		if err != nil {
+			return callError(err)
+		}
+
+		tbl := table{Header: []string{"ID", "OPERATION", "SOURCE", "DESTINATION", "FEE (PAISAS)"}}
+		for _, charge := range res.Charges {
+			tbl.Rows = append(tbl.Rows, []string{
+				charge.Id,
+				charge.Operation,
+				charge.SourceUserType,
+				charge.DestinationUserType,
+				strconv.FormatUint(charge.Fee, 10),
+			})
+		}
+		return printMessage(res, tbl)
+	}),
+}
+
+// tariffSetCmd represents the tariff set command
+var tariffSetCmd = &cobra.Command{
+	Use:          "set <charge-id> <fee>",
+	Short:        "Sets the fee of a charge, in paisas",
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: withProfile(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+		fee, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("fee %q should be a whole number of paisas", args[1])
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewWalletOperationsClient(conn)
+
+		res, err := client.UpdateCharge(ctx, &v1.UpdateChargeRequest{ChargeId: args[0], Fee: fee})
+		if err != nil {
+			return callError(err)
+		}
+
+		if rootCmdOpts.Output == outputJSON {
+			return printMessage(res, table{})
+		}
+		fmt.Fprintf(os.Stderr, "Fee of charge %s set to %d paisas\n", args[0], fee)
+		return nil
+	}),
+}
+
+// superAgentCmd represents the super-agent command
+var superAgentCmd = &cobra.Command{
+	Use:   "super-agent",
+	Short: "Manages the super agents, as an administrator",
+}
+
+// superAgentToggleCmd represents the super-agent toggle command
+var superAgentToggleCmd = &cobra.Command{
+	Use:          "toggle <email>",
+	Short:        "Makes an agent a super agent, or a super agent an agent again",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: withProfile(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewWalletOperationsClient(conn)
+
+		res, err := client.UpdateSuperAgentStatus(ctx, &v1.UpdateSuperAgentStatusRequest{Email: args[0]})
+		if err != nil {
+			return callError(err)
+		}
+
+		if rootCmdOpts.Output == outputJSON {
+			return printMessage(res, table{})
+		}
+		fmt.Fprintf(os.Stderr, "Super agent status of %s toggled\n", args[0])
+		return nil
+	}),
+}
+
+func init() {
+	tariffCmd.AddCommand(tariffGetCmd)
+	tariffCmd.AddCommand(tariffSetCmd)
+	superAgentCmd.AddCommand(superAgentToggleCmd)
+
+	rootCmd.AddCommand(assignFloatCmd)
+	rootCmd.AddCommand(tariffCmd)
+	rootCmd.AddCommand(superAgentCmd)
+}
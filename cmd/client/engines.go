@@ -0,0 +1,129 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+	"github.com/bhojpur/wallet/pkg/filter"
+	"github.com/spf13/cobra"
+)
+
+var enginesListCmdOpts struct {
+	Filters []string
+	Order   []string
+	Start   int32
+	Limit   int32
+}
+
+// enginesCmd represents the engines command
+var enginesCmd = &cobra.Command{
+	Use:   "engines",
+	Short: "Works with the engines started on the server",
+}
+
+// enginesListCmd represents the engines list command
+var enginesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the engines that match a filter",
+	Long: `Lists the engines that match every --filter, the most recently created first.
+It needs a profile logged in as an administrator.
+A filter is made of terms separated by |, any of which should match. A term is a
+field, an operator and a value. The operators are == (equals), ^= (starts with),
+$= (ends with) and *= (contains), negated by a leading !, as in != or !^=. A field
+on its own has to be set, !field not.
+
+Fields are named by their path in the engine status, such as name, phase,
+details, metadata.owner or conditions.success. spec, owner, trigger, created,
+finished, success, failure_count, can_replay and did_execute are short for
+their metadata and conditions fields, annotation.<key> is an annotation.`,
+	Example: `  wallet engines list --filter phase==done --filter success==false
+  wallet engines list --filter "spec^=interest-|spec==reconcile" --order created:asc
+  wallet engines list --filter annotation.month==2020-11 --limit 10`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: withProfile(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+		req := &v1.ListEnginesRequest{
+			Start: enginesListCmdOpts.Start,
+			Limit: enginesListCmdOpts.Limit,
+		}
+		for _, text := range enginesListCmdOpts.Filters {
+			expr, err := filter.Parse(text)
+			if err != nil {
+				return err
+			}
+			req.Filter = append(req.Filter, expr)
+		}
+		for _, text := range enginesListCmdOpts.Order {
+			order, err := filter.ParseOrder(text)
+			if err != nil {
+				return err
+			}
+			req.Order = append(req.Order, order)
+		}
+		if err := filter.Validate(req.Filter, req.Order); err != nil {
+			return err
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewWalletServiceClient(conn)
+
+		res, err := client.ListEngines(ctx, req)
+		if err != nil {
+			return callError(err)
+		}
+
+		tbl := table{Header: []string{"NAME", "SPEC", "PHASE", "SUCCESS", "CREATED", "FINISHED"}}
+		for _, engine := range res.Result {
+			row := []string{engine.Name}
+			for _, field := range []string{"spec", "phase", "success", "created", "finished"} {
+				value, _, _ := filter.Value(engine, field)
+				row = append(row, value)
+			}
+			// times are shown to the second
+			for _, i := range []int{4, 5} {
+				if len(row[i]) > 19 {
+					row[i] = row[i][:19]
+				}
+			}
+			tbl.Rows = append(tbl.Rows, row)
+		}
+
+		if rootCmdOpts.Output != outputJSON && int(res.Total) > len(res.Result) {
+			fmt.Fprintf(os.Stderr, "%d of %d engines\n", len(res.Result), res.Total)
+		}
+		return printMessage(res, tbl)
+	}),
+}
+
+func init() {
+	enginesListCmd.Flags().StringArrayVar(&enginesListCmdOpts.Filters, "filter", nil, "filter the engines should match, can be given more than once")
+	enginesListCmd.Flags().StringArrayVar(&enginesListCmdOpts.Order, "order", nil, "field to order the engines by, as field:asc or field:desc, can be given more than once")
+	enginesListCmd.Flags().Int32Var(&enginesListCmdOpts.Start, "start", 0, "number of engines to skip")
+	enginesListCmd.Flags().Int32Var(&enginesListCmdOpts.Limit, "limit", 0, "most engines to list (defaults to all)")
+
+	enginesCmd.AddCommand(enginesListCmd)
+	rootCmd.AddCommand(enginesCmd)
+}
@@ -0,0 +1,447 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bhojpur/wallet/pkg/engine"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var keysCmdOpts struct {
+	NodeID           string
+	MnemonicPassword string
+	Private          bool
+	Mnemonic         bool
+	Count            uint32
+	Address          string
+	PublicKey        string
+}
+
+// keyResponse is a wallet of the keystore as it is printed
+type keyResponse struct {
+	Address   string `json:"address"`
+	Path      string `json:"path,omitempty"`
+	PublicKey string `json:"publicKey"`
+}
+
+func newKeyResponse(address string, wallet engine.Wallet) keyResponse {
+	return keyResponse{
+		Address:   address,
+		Path:      wallet.Path,
+		PublicKey: hex.EncodeToString(wallet.PublicKeySEC1(true)),
+	}
+}
+
+func keysTable(keys ...keyResponse) table {
+	tbl := table{Header: []string{"ADDRESS", "PATH", "PUBLIC KEY"}}
+	for _, key := range keys {
+		path := key.Path
+		if path == "" {
+			path = "imported"
+		}
+		tbl.Rows = append(tbl.Rows, []string{key.Address, path, key.PublicKey})
+	}
+	return tbl
+}
+
+// keysCmd represents the keys command
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manages the wallet keys in the local keystore of a node",
+	Long: `Manages the wallet keys in the local keystore of a node. The keystore is kept in the
+current directory and is encrypted with a passphrase, read from the
+WALLET_KEYSTORE_PASSPHRASE env var or prompted for.`,
+}
+
+// keysCreateCmd represents the keys create command
+var keysCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Derives a new address, the first one creates the mnemonic of the keystore",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ws, err := openKeystore()
+		if err != nil {
+			return err
+		}
+
+		// the mnemonic is only ever shown once, when it is created
+		var mnemonic string
+		if !ws.HasMnemonic() {
+			mnemonic, err = engine.NewMnemonic()
+			if err != nil {
+				return err
+			}
+			if err := ws.SetMnemonic(mnemonic, keysCmdOpts.MnemonicPassword); err != nil {
+				return err
+			}
+		}
+
+		address, err := ws.CreateWallet()
+		if err != nil {
+			return err
+		}
+		if err := ws.SaveToFile(keysCmdOpts.NodeID); err != nil {
+			return err
+		}
+
+		wallet, _ := ws.GetWallet(address)
+		key := newKeyResponse(address, wallet)
+
+		if mnemonic != "" && rootCmdOpts.Output != outputJSON {
+			fmt.Fprintf(os.Stderr, "Write down this mnemonic, it is the only backup of the keys derived from it:\n\n  %s\n\n", mnemonic)
+		}
+
+		return printOutput(struct {
+			keyResponse
+			Mnemonic string `json:"mnemonic,omitempty"`
+		}{key, mnemonic}, keysTable(key))
+	},
+}
+
+// keysListCmd represents the keys list command
+var keysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the addresses in the keystore",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ws, err := openKeystore()
+		if err != nil {
+			return err
+		}
+
+		addresses := ws.GetAddresses()
+		sort.Strings(addresses)
+
+		keys := []keyResponse{}
+		for _, address := range addresses {
+			wallet, err := ws.GetWallet(address)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, newKeyResponse(address, wallet))
+		}
+
+		return printOutput(keys, keysTable(keys...))
+	},
+}
+
+// keysExportCmd represents the keys export command
+var keysExportCmd = &cobra.Command{
+	Use:   "export <address>",
+	Short: "Exports the PEM encoded public key, or private key, of an address",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ws, err := openKeystore()
+		if err != nil {
+			return err
+		}
+
+		wallet, err := ws.GetWallet(args[0])
+		if err != nil {
+			return err
+		}
+
+		var content []byte
+		if keysCmdOpts.Private {
+			content, err = wallet.PrivateKeyPEM()
+		} else {
+			content, err = wallet.PublicKeyPEM()
+		}
+		if err != nil {
+			return err
+		}
+
+		if rootCmdOpts.Output == outputJSON {
+			return printOutput(struct {
+				keyResponse
+				PEM string `json:"pem"`
+			}{newKeyResponse(args[0], wallet), string(content)}, table{})
+		}
+
+		_, err = os.Stdout.Write(content)
+		return err
+	},
+}
+
+// keysImportCmd represents the keys import command
+var keysImportCmd = &cobra.Command{
+	Use:   "import [<private-key.pem>]",
+	Short: "Imports a PEM encoded P-256 private key, or restores the addresses of a mnemonic",
+	Long: `Imports a PEM encoded P-256 private key into the keystore. With --mnemonic the
+mnemonic is read from stdin instead, and the first --count addresses derived
+from it are restored.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if keysCmdOpts.Mnemonic == (len(args) == 1) {
+			return errors.New("give either a private key file or --mnemonic")
+		}
+
+		ws, err := openKeystore()
+		if err != nil {
+			return err
+		}
+
+		var addresses []string
+		if keysCmdOpts.Mnemonic {
+			mnemonic, err := readMnemonic()
+			if err != nil {
+				return err
+			}
+			if err := ws.Restore(mnemonic, keysCmdOpts.MnemonicPassword, keysCmdOpts.Count); err != nil {
+				return err
+			}
+			addresses = ws.GetAddresses()
+		} else {
+			content, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			private, err := engine.ParsePrivateKeyPEM(content)
+			if err != nil {
+				return err
+			}
+			wallet, err := engine.NewWalletFromKey(private)
+			if err != nil {
+				return err
+			}
+			addresses = append(addresses, ws.ImportWallet(wallet))
+		}
+
+		if err := ws.SaveToFile(keysCmdOpts.NodeID); err != nil {
+			return err
+		}
+
+		sort.Strings(addresses)
+		keys := []keyResponse{}
+		for _, address := range addresses {
+			wallet, _ := ws.GetWallet(address)
+			keys = append(keys, newKeyResponse(address, wallet))
+		}
+
+		return printOutput(keys, keysTable(keys...))
+	},
+}
+
+// signatureResponse is a signature as it is printed
+type signatureResponse struct {
+	Address   string `json:"address,omitempty"`
+	Digest    string `json:"digest"`
+	Signature string `json:"signature"`
+	Valid     *bool  `json:"valid,omitempty"`
+}
+
+// keysSignCmd represents the keys sign command
+var keysSignCmd = &cobra.Command{
+	Use:   "sign <address> <file>",
+	Short: "Signs the SHA-256 hash of a file, or of stdin when the file is -, with the key of an address",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		digest, err := digestOf(args[1])
+		if err != nil {
+			return err
+		}
+
+		ws, err := openKeystore()
+		if err != nil {
+			return err
+		}
+
+		wallet, err := ws.GetWallet(args[0])
+		if err != nil {
+			return err
+		}
+
+		sig, err := wallet.Sign(digest)
+		if err != nil {
+			return err
+		}
+
+		resp := signatureResponse{Address: args[0], Digest: hex.EncodeToString(digest), Signature: hex.EncodeToString(sig)}
+		return printOutput(resp, table{
+			Header: []string{"ADDRESS", "DIGEST", "SIGNATURE"},
+			Rows:   [][]string{{resp.Address, resp.Digest, resp.Signature}},
+		})
+	},
+}
+
+// keysVerifyCmd represents the keys verify command
+var keysVerifyCmd = &cobra.Command{
+	Use:   "verify <file> <signature>",
+	Short: "Verifies a hex encoded signature of a file, or of stdin when the file is -",
+	Long: `Verifies a hex encoded signature of a file, or of stdin when the file is -. The
+signer is either an --address in the keystore, or a --public-key, which needs
+no keystore.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if (keysCmdOpts.Address == "") == (keysCmdOpts.PublicKey == "") {
+			return errors.New("give either --address or --public-key")
+		}
+
+		digest, err := digestOf(args[0])
+		if err != nil {
+			return err
+		}
+		sig, err := hex.DecodeString(strings.TrimSpace(args[1]))
+		if err != nil {
+			return fmt.Errorf("signature should be hex encoded: %w", err)
+		}
+
+		var valid bool
+		if keysCmdOpts.Address != "" {
+			ws, err := openKeystore()
+			if err != nil {
+				return err
+			}
+			wallet, err := ws.GetWallet(keysCmdOpts.Address)
+			if err != nil {
+				return err
+			}
+			valid = wallet.Verify(digest, sig)
+		} else {
+			publicKey, err := readPublicKey(keysCmdOpts.PublicKey)
+			if err != nil {
+				return err
+			}
+			if valid, err = engine.Verify(publicKey, digest, sig); err != nil {
+				return err
+			}
+		}
+
+		resp := signatureResponse{Address: keysCmdOpts.Address, Digest: hex.EncodeToString(digest), Signature: args[1], Valid: &valid}
+		if err := printOutput(resp, table{
+			Header: []string{"DIGEST", "VALID"},
+			Rows:   [][]string{{resp.Digest, fmt.Sprint(valid)}},
+		}); err != nil {
+			return err
+		}
+		if !valid {
+			return errors.New("signature is not valid")
+		}
+		return nil
+	},
+}
+
+// openKeystore opens the keystore of the node
+func openKeystore() (*engine.Wallets, error) {
+	passphrase, err := keystorePassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.NewWallets(keysCmdOpts.NodeID, passphrase)
+}
+
+// keystorePassphrase reads the passphrase of the keystore from the environment,
+// or prompts for it when the client runs in a terminal
+func keystorePassphrase() ([]byte, error) {
+	return readSecret("WALLET_KEYSTORE_PASSPHRASE", "Keystore passphrase")
+}
+
+// readSecret reads a secret from an env var, or prompts for it without echoing
+// when the client runs in a terminal
+func readSecret(env, prompt string) ([]byte, error) {
+	if secret := os.Getenv(env); secret != "" {
+		return []byte(secret), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("set %s or run in a terminal to be prompted for the %s", env, strings.ToLower(prompt))
+	}
+
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+	secret, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+
+	return secret, err
+}
+
+// readMnemonic reads the words of a mnemonic from a line of stdin
+func readMnemonic() (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprint(os.Stderr, "Mnemonic: ")
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("cannot read mnemonic: %w", err)
+	}
+
+	return strings.Join(strings.Fields(line), " "), nil
+}
+
+// readPublicKey reads a public key given as hex, or as the name of a file holding a PEM encoded key
+func readPublicKey(value string) ([]byte, error) {
+	if key, err := hex.DecodeString(strings.TrimSpace(value)); err == nil {
+		return key, nil
+	}
+
+	return ioutil.ReadFile(value)
+}
+
+// digestOf returns the SHA-256 hash of a file, or of stdin when the name is -
+func digestOf(name string) ([]byte, error) {
+	var content []byte
+	var err error
+	if name == "-" {
+		content, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		content, err = ioutil.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.Sha256(content), nil
+}
+
+func init() {
+	nodeID := os.Getenv("WALLET_NODE_ID")
+	if nodeID == "" {
+		nodeID = "default"
+	}
+
+	keysCmd.PersistentFlags().StringVar(&keysCmdOpts.NodeID, "node", nodeID, "ID of the node whose keystore is used (defaults to WALLET_NODE_ID env var)")
+	keysCmd.PersistentFlags().StringVar(&keysCmdOpts.MnemonicPassword, "mnemonic-password", "", "optional BIP39 password extending the mnemonic")
+
+	keysExportCmd.Flags().BoolVar(&keysCmdOpts.Private, "private", false, "export the private key instead of the public key")
+	keysImportCmd.Flags().BoolVar(&keysCmdOpts.Mnemonic, "mnemonic", false, "restore the addresses of a mnemonic read from stdin")
+	keysImportCmd.Flags().Uint32Var(&keysCmdOpts.Count, "count", 1, "number of addresses to restore from the mnemonic")
+	keysVerifyCmd.Flags().StringVar(&keysCmdOpts.Address, "address", "", "address in the keystore that signed")
+	keysVerifyCmd.Flags().StringVar(&keysCmdOpts.PublicKey, "public-key", "", "hex encoded public key that signed, or a file holding a PEM encoded one")
+
+	keysCmd.AddCommand(keysCreateCmd, keysListCmd, keysExportCmd, keysImportCmd, keysSignCmd, keysVerifyCmd)
+	// errors past the arguments are not usage errors
+	for _, cmd := range keysCmd.Commands() {
+		cmd.SilenceUsage = true
+	}
+	rootCmd.AddCommand(keysCmd)
+}
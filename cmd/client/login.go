@@ -0,0 +1,102 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+	"github.com/spf13/cobra"
+)
+
+var loginCmdOpts struct {
+	Email string
+}
+
+// loginCmd represents the login command
+var loginCmd = &cobra.Command{
+	Use:   "login <administrator|agent|merchant|subscriber>",
+	Short: "Logs in as a user and keeps the credentials in the profile",
+	Long: `Logs in as a user and keeps the credentials in the profile given by --profile.
+The password is read from the WALLET_PASSWORD env var or prompted for. Profiles
+are kept in the config file of the client, WALLET_CONFIG overrides where it is.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		password, err := readSecret("WALLET_PASSWORD", "Password")
+		if err != nil {
+			return err
+		}
+
+		cfg, err := loadClientConfig()
+		if err != nil {
+			return err
+		}
+		// logging in to a profile again goes to the host it used before
+		useProfileHost(cfg.Profiles[rootCmdOpts.Profile])
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewWalletOperationsClient(conn)
+
+		res, err := client.Login(context.Background(), &v1.LoginRequest{
+			UserType: args[0],
+			Email:    loginCmdOpts.Email,
+			Password: string(password),
+		})
+		if err != nil {
+			return callError(err)
+		}
+
+		cfg.Profiles[rootCmdOpts.Profile] = profile{
+			Host:     rootCmdOpts.Host,
+			Email:    loginCmdOpts.Email,
+			UserID:   res.UserId,
+			UserType: res.UserType,
+			Token:    res.Token,
+		}
+		if err := cfg.save(); err != nil {
+			return err
+		}
+
+		if rootCmdOpts.Output != outputJSON {
+			fmt.Fprintf(os.Stderr, "Logged in to profile %q\n", rootCmdOpts.Profile)
+		}
+
+		return printOutput(struct {
+			Profile  string `json:"profile"`
+			UserID   string `json:"userId"`
+			UserType string `json:"userType"`
+		}{rootCmdOpts.Profile, res.UserId, res.UserType}, table{
+			Header: []string{"PROFILE", "USER ID", "USER TYPE"},
+			Rows:   [][]string{{rootCmdOpts.Profile, res.UserId, res.UserType}},
+		})
+	},
+}
+
+func init() {
+	loginCmd.Flags().StringVar(&loginCmdOpts.Email, "email", "", "email of the user")
+	_ = loginCmd.MarkFlagRequired("email")
+
+	rootCmd.AddCommand(loginCmd)
+}
@@ -0,0 +1,98 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+	"github.com/spf13/cobra"
+)
+
+var logsCmdOpts struct {
+	Follow bool
+	Level  string
+}
+
+// logsCmd represents the logs command
+var logsCmd = &cobra.Command{
+	Use:   "logs <engine>",
+	Short: "Prints the log of an engine",
+	Long: `Prints the log of an engine, as lines of text or as JSON lines with -o json.
+The server keeps the most recent entries of every engine. It needs a profile
+logged in as an administrator.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: withProfile(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+		logs := v1.ListenRequestLogs_LOGS_UNSLICED
+		switch rootCmdOpts.Output {
+		case outputJSON:
+			logs = v1.ListenRequestLogs_LOGS_RAW
+		case outputTable:
+		default:
+			return fmt.Errorf("unknown output format %q, valid values are %q or %q", rootCmdOpts.Output, outputTable, outputJSON)
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewWalletServiceClient(conn)
+
+		stream, err := client.Listen(ctx, &v1.ListenRequest{
+			Name:   args[0],
+			Logs:   logs,
+			Follow: logsCmdOpts.Follow,
+			Level:  logsCmdOpts.Level,
+		})
+		if err != nil {
+			return callError(err)
+		}
+
+		for {
+			msg, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			} else if err != nil {
+				return callError(err)
+			}
+
+			slice := msg.GetSlice()
+			switch slice.GetType() {
+			case v1.LogSliceType_SLICE_CONTENT:
+				fmt.Println(slice.Payload)
+			case v1.LogSliceType_SLICE_ABANDONED:
+				fmt.Fprintln(os.Stderr, slice.Payload)
+			case v1.LogSliceType_SLICE_FAIL:
+				return fmt.Errorf("engine %s failed: %s", args[0], slice.Payload)
+			}
+		}
+	}),
+}
+
+func init() {
+	logsCmd.Flags().BoolVarP(&logsCmdOpts.Follow, "follow", "f", false, "keep printing the log until the engine is done")
+	logsCmd.Flags().StringVar(&logsCmdOpts.Level, "level", "", "least severe level to print: debug, info, warn or error (defaults to debug)")
+
+	rootCmd.AddCommand(logsCmd)
+}
@@ -0,0 +1,161 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
+)
+
+// profile holds the credentials a login leaves behind, so that
+// commands can be run as a user without logging in every time
+type profile struct {
+	Host     string `yaml:"host,omitempty"`
+	Email    string `yaml:"email,omitempty"`
+	UserID   string `yaml:"userId,omitempty"`
+	UserType string `yaml:"userType,omitempty"`
+	Token    string `yaml:"token,omitempty"`
+}
+
+// clientConfig is the config file of the client, it keeps a profile per name
+type clientConfig struct {
+	Profiles map[string]profile `yaml:"profiles"`
+}
+
+// clientConfigFile returns the path of the config file, WALLET_CONFIG overrides it
+func clientConfigFile() (string, error) {
+	if fn := os.Getenv("WALLET_CONFIG"); fn != "" {
+		return fn, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine the config directory, set WALLET_CONFIG: %w", err)
+	}
+	return filepath.Join(dir, "bhojpur", "wallet.yaml"), nil
+}
+
+func loadClientConfig() (clientConfig, error) {
+	cfg := clientConfig{Profiles: make(map[string]profile)}
+
+	fn, err := clientConfigFile()
+	if err != nil {
+		return cfg, err
+	}
+
+	data, err := ioutil.ReadFile(fn)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	} else if err != nil {
+		return cfg, err
+	}
+
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("cannot read %s: %w", fn, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]profile)
+	}
+	return cfg, nil
+}
+
+// save writes the config file, it is only readable by the user as it holds tokens
+func (cfg clientConfig) save() error {
+	fn, err := clientConfigFile()
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fn), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fn, data, 0600)
+}
+
+// currentProfile returns the profile selected by the --profile flag, it should have been logged in
+func currentProfile() (profile, error) {
+	cfg, err := loadClientConfig()
+	if err != nil {
+		return profile{}, err
+	}
+
+	p, ok := cfg.Profiles[rootCmdOpts.Profile]
+	if !ok || p.Token == "" {
+		return profile{}, fmt.Errorf("profile %q is not logged in, run wallet login first", rootCmdOpts.Profile)
+	}
+	return p, nil
+}
+
+// useProfileHost dials the host the profile logged in to, unless a host was given
+func useProfileHost(p profile) {
+	if p.Host == "" || os.Getenv("WALLET_HOST") != "" || rootCmd.PersistentFlags().Changed("host") {
+		return
+	}
+	rootCmdOpts.Host = p.Host
+}
+
+// authContext passes the token of the profile as a bearer token in the metadata of calls
+func authContext(ctx context.Context, p profile) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+p.Token)
+}
+
+// callError turns the status of a failed call into an error that reads well on a terminal
+func callError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	switch st.Code() {
+	case codes.Unauthenticated:
+		return fmt.Errorf("%s, run wallet login again", st.Message())
+	case codes.Unavailable:
+		return fmt.Errorf("cannot reach Bhojpur Wallet at %s: %s", rootCmdOpts.Host, st.Message())
+	}
+	return fmt.Errorf("%s (%s)", st.Message(), st.Code())
+}
+
+// withProfile runs a command with the profile it was asked to run as
+func withProfile(run func(ctx context.Context, cmd *cobra.Command, args []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		p, err := currentProfile()
+		if err != nil {
+			return err
+		}
+		useProfileHost(p)
+
+		return run(authContext(context.Background(), p), cmd, args)
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/bhojpur/wallet/pkg/receipt"
+	"github.com/spf13/cobra"
+)
+
+var receiptCmdOpts struct {
+	PublicKey string
+}
+
+// receiptCmd represents the receipt command
+var receiptCmd = &cobra.Command{
+	Use:   "receipt",
+	Short: "Works with the signed receipts of Bhojpur Wallet transactions",
+}
+
+// receiptVerifyCmd represents the receipt verify command
+var receiptVerifyCmd = &cobra.Command{
+	Use:   "verify <receipt.json>",
+	Short: "Verifies a signed transaction receipt offline, using the public key of the engine",
+	Args:  cobra.ExactArgs(1),
+	// a receipt that fails verification is not a usage error
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		publicKey, err := hex.DecodeString(strings.TrimSpace(receiptCmdOpts.PublicKey))
+		if err != nil {
+			return fmt.Errorf("public key should be hex encoded: %w", err)
+		}
+
+		data, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		signed, err := receipt.Verify(data, publicKey)
+		if err != nil {
+			return err
+		}
+
+		rct := signed.Receipt
+		fmt.Println("receipt is valid")
+		fmt.Printf("id:          %s\n", rct.ID)
+		fmt.Printf("operation:   %s\n", rct.Operation)
+		fmt.Printf("source:      %s (%s)\n", rct.Source.UserID, rct.Source.UserType)
+		fmt.Printf("destination: %s (%s)\n", rct.Destination.UserID, rct.Destination.UserType)
+		fmt.Printf("amount:      %.2f %s\n", rct.Amount.ToFloat(), rct.Currency)
+		fmt.Printf("charge:      %.2f %s\n", rct.Charge.ToFloat(), rct.Currency)
+		fmt.Printf("completed:   %s\n", rct.CompletedAt)
+		return nil
+	},
+}
+
+func init() {
+	receiptVerifyCmd.Flags().StringVar(&receiptCmdOpts.PublicKey, "public-key", "", "hex encoded public key of the engine, as served at /api/receipts/public-key")
+	_ = receiptVerifyCmd.MarkFlagRequired("public-key")
+
+	receiptCmd.AddCommand(receiptVerifyCmd)
+	rootCmd.AddCommand(receiptCmd)
+}
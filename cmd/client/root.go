@@ -23,6 +23,8 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"net"
@@ -36,6 +38,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -57,6 +60,12 @@ var rootCmdOpts struct {
 	K8sLabelSelector string
 	K8sPodPort       string
 	DialMode         string
+	Output           string
+	Profile          string
+	TLS              bool
+	TLSCA            string
+	TLSCert          string
+	TLSKey           string
 }
 
 // rootCmd represents the base command when called without any subcommands
@@ -110,6 +119,11 @@ func init() {
 	if walletPodPort == "" {
 		walletPodPort = "7777"
 	}
+	walletProfile := os.Getenv("WALLET_PROFILE")
+	if walletProfile == "" {
+		walletProfile = "default"
+	}
+	walletTLS := os.Getenv("WALLET_TLS") == "true"
 	dialMode := os.Getenv("WALLET_DIAL_MODE")
 	if dialMode == "" {
 		dialMode = string(dialModeHost)
@@ -118,6 +132,12 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&rootCmdOpts.Verbose, "verbose", false, "en/disable verbose logging")
 	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.DialMode, "dial-mode", dialMode, "dial mode that determines how we connect to Bhojpur Wallet. Valid values are \"host\" or \"kubernetes\" (defaults to WALLET_DIAL_MODE env var).")
 	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.Host, "host", walletHost, "[host dial mode] Bhojpur Wallet host to talk to (defaults to WALLET_HOST env var)")
+	rootCmd.PersistentFlags().StringVarP(&rootCmdOpts.Output, "output", "o", outputTable, "output format of commands that print results. Valid values are \"table\" or \"json\"")
+	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.Profile, "profile", walletProfile, "profile of the config file whose credentials commands run with (defaults to WALLET_PROFILE env var)")
+	rootCmd.PersistentFlags().BoolVar(&rootCmdOpts.TLS, "tls", walletTLS, "connect over TLS, also on when a CA or certificate is given (defaults to WALLET_TLS env var)")
+	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.TLSCA, "tls-ca", os.Getenv("WALLET_TLS_CA"), "PEM CA the server certificate is checked against instead of the system CAs (defaults to WALLET_TLS_CA env var)")
+	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.TLSCert, "tls-cert", os.Getenv("WALLET_TLS_CERT"), "PEM client certificate for servers asking for mutual TLS (defaults to WALLET_TLS_CERT env var)")
+	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.TLSKey, "tls-key", os.Getenv("WALLET_TLS_KEY"), "PEM key of the client certificate (defaults to WALLET_TLS_KEY env var)")
 	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.Kubeconfig, "kubeconfig", walletKubeconfig, "[kubernetes dial mode] kubeconfig file to use (defaults to KUEBCONFIG env var)")
 	rootCmd.PersistentFlags().StringVar(&rootCmdOpts.K8sNamespace, "k8s-namespace", walletNamespace, "[kubernetes dial mode] Kubernetes namespace in which to look for the Bhojpur Wallet pods (defaults to WALLET_K8S_NAMESPACE env var, or configured kube context namespace)")
 	// The following are such specific flags that really only matters if one doesn't use the stock helm charts.
@@ -132,12 +152,16 @@ type closableGrpcClientConnInterface interface {
 }
 
 func dial() (res closableGrpcClientConnInterface) {
-	var err error
+	transport, err := transportCredentials()
+	if err != nil {
+		log.WithError(err).Fatal("cannot set up TLS")
+	}
+
 	switch rootCmdOpts.DialMode {
 	case dialModeHost:
-		res, err = grpc.Dial(rootCmdOpts.Host, grpc.WithInsecure())
+		res, err = grpc.Dial(rootCmdOpts.Host, transport)
 	case dialModeKubernetes:
-		res, err = dialKubernetes()
+		res, err = dialKubernetes(transport)
 	default:
 		log.Fatalf("unknown dial mode: %s", rootCmdOpts.DialMode)
 	}
@@ -148,7 +172,35 @@ func dial() (res closableGrpcClientConnInterface) {
 	return
 }
 
-func dialKubernetes() (closableGrpcClientConnInterface, error) {
+// transportCredentials dials with TLS when it is asked for, or a CA or client certificate is given
+func transportCredentials() (grpc.DialOption, error) {
+	if !rootCmdOpts.TLS && rootCmdOpts.TLSCA == "" && rootCmdOpts.TLSCert == "" {
+		return grpc.WithInsecure(), nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if rootCmdOpts.TLSCA != "" {
+		pem, err := os.ReadFile(rootCmdOpts.TLSCA)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA: %w", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA %s holds no PEM certificate", rootCmdOpts.TLSCA)
+		}
+	}
+	if rootCmdOpts.TLSCert != "" {
+		cert, err := tls.LoadX509KeyPair(rootCmdOpts.TLSCert, rootCmdOpts.TLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(cfg)), nil
+}
+
+func dialKubernetes(transport grpc.DialOption) (closableGrpcClientConnInterface, error) {
 	kubecfg, namespace, err := getKubeconfig(rootCmdOpts.Kubeconfig)
 	if err != nil {
 		return nil, fmt.Errorf("cannot load kubeconfig %s: %w", rootCmdOpts.Kubeconfig, err)
@@ -178,7 +230,7 @@ func dialKubernetes() (closableGrpcClientConnInterface, error) {
 	case <-readychan:
 	}
 
-	res, err := grpc.Dial(fmt.Sprintf("localhost:%d", localPort), grpc.WithInsecure())
+	res, err := grpc.Dial(fmt.Sprintf("localhost:%d", localPort), transport)
 	if err != nil {
 		cancel()
 		return nil, fmt.Errorf("cannot dial forwarded connection: %w", err)
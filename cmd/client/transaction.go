@@ -0,0 +1,197 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+	"github.com/spf13/cobra"
+)
+
+var transactionCmdOpts struct {
+	CustomerType string
+}
+
+// depositCmd represents the deposit command
+var depositCmd = &cobra.Command{
+	Use:   "deposit <customer-number> <amount>",
+	Short: "Deposits rupees into the account of a customer",
+	Long: `Deposits rupees into the account of a customer. The customer number is an account
+number, a till number, an agent number, an E.164 phone number or an email.`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: withProfile(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+		amount, err := parseRupees(args[1])
+		if err != nil {
+			return err
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewWalletOperationsClient(conn)
+
+		res, err := client.Deposit(ctx, &v1.DepositRequest{
+			CustomerNumber: args[0],
+			CustomerType:   transactionCmdOpts.CustomerType,
+			Amount:         amount,
+		})
+		if err != nil {
+			return callError(err)
+		}
+
+		return printReceipt(res)
+	}),
+}
+
+// transferCmd represents the transfer command
+var transferCmd = &cobra.Command{
+	Use:   "transfer <account-number> <amount>",
+	Short: "Transfers rupees from the account of the profile to another account",
+	Long: `Transfers rupees from the account of the profile to another account. The account
+number may also be a till number, an agent number, an E.164 phone number or an email.`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: withProfile(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+		amount, err := parseRupees(args[1])
+		if err != nil {
+			return err
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewWalletOperationsClient(conn)
+
+		res, err := client.Transfer(ctx, &v1.TransferRequest{
+			AccountNumber: args[0],
+			CustomerType:  transactionCmdOpts.CustomerType,
+			Amount:        amount,
+		})
+		if err != nil {
+			return callError(err)
+		}
+
+		return printReceipt(res)
+	}),
+}
+
+// withdrawCmd represents the withdraw command
+var withdrawCmd = &cobra.Command{
+	Use:          "withdraw <agent-number> <amount>",
+	Short:        "Withdraws rupees from the account of the profile through an agent",
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: withProfile(func(ctx context.Context, cmd *cobra.Command, args []string) error {
+		amount, err := parseRupees(args[1])
+		if err != nil {
+			return err
+		}
+
+		conn := dial()
+		defer conn.Close()
+		client := v1.NewWalletOperationsClient(conn)
+
+		res, err := client.Withdraw(ctx, &v1.WithdrawRequest{
+			AgentNumber: args[0],
+			Amount:      amount,
+		})
+		if err != nil {
+			return callError(err)
+		}
+
+		return printReceipt(res)
+	}),
+}
+
+// parseRupees parses an amount of whole rupees
+func parseRupees(amount string) (uint64, error) {
+	rupees, err := strconv.ParseUint(amount, 10, 64)
+	if err != nil || rupees == 0 {
+		return 0, fmt.Errorf("amount %q should be a whole number of rupees", amount)
+	}
+	return rupees, nil
+}
+
+// printReceipt prints the receipt of a transaction. As JSON it is the signed receipt
+// document, so that it can be kept and checked with wallet receipt verify. A transaction
+// whose receipt could not be issued is printed without one, it completed all the same.
+func printReceipt(res *v1.TransactionResponse) error {
+	signed := res.Receipt
+	if signed == nil {
+		return printCompleted(res.Transaction, res.ReceiptError)
+	}
+
+	if rootCmdOpts.Output == outputJSON {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, signed.Document, "", "  "); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(os.Stdout, buf.String())
+		return err
+	}
+
+	rct := signed.Receipt
+	return printOutput(nil, table{
+		Header: []string{"RECEIPT", "OPERATION", "SOURCE", "DESTINATION", "AMOUNT", "CHARGE", "COMPLETED"},
+		Rows: [][]string{{
+			rct.Id,
+			rct.Operation,
+			rct.Source.GetUserType(),
+			rct.Destination.GetUserType(),
+			fmt.Sprintf("%.2f %s", float64(rct.Amount)/100, rct.Currency),
+			fmt.Sprintf("%.2f %s", float64(rct.Charge)/100, rct.Currency),
+			rct.CompletedAt.AsTime().Local().Format("2006-01-02 15:04:05"),
+		}},
+	})
+}
+
+// printCompleted prints a transaction that completed without a receipt, and warns
+// not to repeat it
+func printCompleted(txn *v1.CompletedTransaction, receiptError string) error {
+	fmt.Fprintf(os.Stderr, "Transaction completed, but its receipt could not be issued (%s). Do not repeat the transaction.\n", receiptError)
+
+	return printMessage(txn, table{
+		Header: []string{"OPERATION", "SOURCE", "DESTINATION", "AMOUNT", "CHARGE", "COMPLETED"},
+		Rows: [][]string{{
+			txn.Operation,
+			txn.Source.GetUserType(),
+			txn.Destination.GetUserType(),
+			formatRupees(float64(txn.Amount) / 100),
+			formatRupees(float64(txn.Charge) / 100),
+			txn.CompletedAt.AsTime().Local().Format("2006-01-02 15:04:05"),
+		}},
+	})
+}
+
+func init() {
+	depositCmd.Flags().StringVar(&transactionCmdOpts.CustomerType, "customer-type", "", "type of the customer: agent, merchant or subscriber")
+	_ = depositCmd.MarkFlagRequired("customer-type")
+	transferCmd.Flags().StringVar(&transactionCmdOpts.CustomerType, "customer-type", "", "type of the customer, resolved from the account number when left out")
+
+	rootCmd.AddCommand(depositCmd)
+	rootCmd.AddCommand(transferCmd)
+	rootCmd.AddCommand(withdrawCmd)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var estatementCmdOpts struct {
+	Month string
+}
+
+// estatementCmd represents the estatement command
+var estatementCmd = &cobra.Command{
+	Use:   "estatement",
+	Short: "Generates and sends monthly statements",
+}
+
+// estatementGenerateCmd represents the estatement generate command
+var estatementGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generates the statements of a month, it picks up where an interrupted run stopped",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		month := helpers.LastMonth(time.Now())
+		if estatementCmdOpts.Month != "" {
+			var err error
+			month, err = time.ParseInLocation(helpers.MonthLayout, estatementCmdOpts.Month, time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid month %q, expected YYYY-MM", estatementCmdOpts.Month)
+			}
+		}
+
+		_, domain, err := newDomain(cmd)
+		if err != nil {
+			return err
+		}
+		defer domain.Shutdown(context.Background())
+
+		generated, err := domain.EStatement.GenerateMonth(month)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%d statements generated for %s\n", generated, month.Format(helpers.MonthLayout))
+		return nil
+	},
+}
+
+func init() {
+	estatementGenerateCmd.Flags().StringVar(&estatementCmdOpts.Month, "month", "", "month to generate statements for as YYYY-MM (defaults to last month)")
+
+	estatementCmd.AddCommand(estatementGenerateCmd)
+	rootCmd.AddCommand(estatementCmd)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/helpers"
+
+	"github.com/spf13/cobra"
+)
+
+var interestCmdOpts struct {
+	Day   string
+	Month string
+}
+
+// interestCmd represents the interest command
+var interestCmd = &cobra.Command{
+	Use:   "interest",
+	Short: "Accrues and posts interest on savings products",
+}
+
+// interestAccrueCmd represents the interest accrue command
+var interestAccrueCmd = &cobra.Command{
+	Use:   "accrue",
+	Short: "Accrues interest for a day, it is safe to re-run for a day that was missed",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		day := time.Now().AddDate(0, 0, -1)
+		if interestCmdOpts.Day != "" {
+			var err error
+			day, err = time.ParseInLocation(helpers.DateLayout, interestCmdOpts.Day, time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid day %q, expected YYYY-MM-DD", interestCmdOpts.Day)
+			}
+		}
+
+		_, domain, err := newDomain(cmd)
+		if err != nil {
+			return err
+		}
+		defer domain.Shutdown(context.Background())
+
+		recorded, err := domain.Interest.AccrueDay(day)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("interest accrued on %d accounts for %s\n", recorded, day.Format(helpers.DateLayout))
+		return nil
+	},
+}
+
+// interestPostCmd represents the interest post command
+var interestPostCmd = &cobra.Command{
+	Use:   "post",
+	Short: "Credits interest accrued up to the end of a month, accruals already credited are skipped",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		month := helpers.LastMonth(time.Now())
+		if interestCmdOpts.Month != "" {
+			var err error
+			month, err = time.ParseInLocation(helpers.MonthLayout, interestCmdOpts.Month, time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid month %q, expected YYYY-MM", interestCmdOpts.Month)
+			}
+		}
+
+		_, domain, err := newDomain(cmd)
+		if err != nil {
+			return err
+		}
+		defer domain.Shutdown(context.Background())
+
+		credited, err := domain.Interest.PostMonth(month)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("interest credited to %d accounts for %s\n", credited, month.Format(helpers.MonthLayout))
+		return nil
+	},
+}
+
+func init() {
+	interestAccrueCmd.Flags().StringVar(&interestCmdOpts.Day, "day", "", "day to accrue interest for as YYYY-MM-DD (defaults to yesterday)")
+	interestPostCmd.Flags().StringVar(&interestCmdOpts.Month, "month", "", "month to post interest for as YYYY-MM (defaults to last month)")
+
+	interestCmd.AddCommand(interestAccrueCmd)
+	interestCmd.AddCommand(interestPostCmd)
+	rootCmd.AddCommand(interestCmd)
+}
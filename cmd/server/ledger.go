@@ -0,0 +1,93 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// ledgerCmd represents the ledger command
+var ledgerCmd = &cobra.Command{
+	Use:   "ledger",
+	Short: "Checks that the statements ledger has not been tampered with",
+}
+
+// ledgerVerifyCmd represents the ledger verify command
+var ledgerVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Walks the hash chain of every account and reports the first broken link",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, domain, err := newDomain(cmd)
+		if err != nil {
+			return err
+		}
+		defer domain.Shutdown(context.Background())
+
+		verification, err := domain.Auditor.Verify()
+		if err != nil {
+			return err
+		}
+
+		if cp := verification.Checkpoint; cp != nil {
+			fmt.Printf("checked against checkpoint %v of %s\n", cp.ID, cp.CreatedAt.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Printf("%d entries of %d accounts verified\n", verification.Entries, verification.Accounts)
+
+		if broken := verification.Broken; broken != nil {
+			return fmt.Errorf("ledger broken at account %v entry %d (%v): %s",
+				broken.AccountID, broken.Sequence, broken.EntryID, broken.Reason)
+		}
+
+		fmt.Println("ledger intact")
+		return nil
+	},
+}
+
+// ledgerCheckpointCmd represents the ledger checkpoint command
+var ledgerCheckpointCmd = &cobra.Command{
+	Use:   "checkpoint",
+	Short: "Signs the current head of every chain, the server does this hourly",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, domain, err := newDomain(cmd)
+		if err != nil {
+			return err
+		}
+		defer domain.Shutdown(context.Background())
+
+		checkpoint, err := domain.Auditor.Checkpoint()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("checkpoint %v covers %d entries of %d accounts, root %s\n",
+			checkpoint.ID, checkpoint.Entries, checkpoint.Accounts, checkpoint.Root)
+		return nil
+	},
+}
+
+func init() {
+	ledgerCmd.AddCommand(ledgerVerifyCmd)
+	ledgerCmd.AddCommand(ledgerCheckpointCmd)
+	rootCmd.AddCommand(ledgerCmd)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gofrs/uuid"
+	"github.com/spf13/cobra"
+)
+
+var reconcileCmdOpts struct {
+	Repair     bool
+	ApprovedBy string
+}
+
+// reconcileCmd represents the reconcile command
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Checks every account balance against its statement entries",
+	Long: `Works out the balance of every account from its statement entries and
+reports the accounts that don't match. With --repair a correcting entry is
+posted to the ledger of every open mismatch, the admin approving the repair
+is recorded on it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var adminID uuid.UUID
+		if reconcileCmdOpts.Repair {
+			var err error
+			adminID, err = uuid.FromString(reconcileCmdOpts.ApprovedBy)
+			if err != nil {
+				return fmt.Errorf("--repair needs the user id of the approving admin in --approved-by")
+			}
+		}
+
+		_, domain, err := newDomain(cmd)
+		if err != nil {
+			return err
+		}
+		defer domain.Shutdown(context.Background())
+
+		report, err := domain.Reconciler.Reconcile()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%d accounts reconciled, %d mismatches found, %d resolved\n", report.Accounts, len(report.Mismatches), report.Resolved)
+		if len(report.Mismatches) == 0 {
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tACCOUNT\tBALANCE\tLEDGER\tDIFFERENCE\tENTRIES")
+		for _, m := range report.Mismatches {
+			fmt.Fprintf(w, "%v\t%v\t%.2f\t%.2f\t%.2f\t%d\n", m.ID, m.AccountNumber,
+				float64(m.AccountBalance)/100, float64(m.LedgerBalance)/100, float64(m.Difference())/100, m.Entries)
+		}
+		w.Flush()
+
+		if !reconcileCmdOpts.Repair {
+			return nil
+		}
+
+		for _, m := range report.Mismatches {
+			repaired, err := domain.Reconciler.Repair(m.ID, adminID)
+			if err != nil {
+				return fmt.Errorf("repairing account %v: %w", m.AccountNumber, err)
+			}
+			fmt.Printf("account %v %s\n", repaired.AccountNumber, repaired.Status)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	reconcileCmd.Flags().BoolVar(&reconcileCmdOpts.Repair, "repair", false, "post a correcting entry for every mismatch found")
+	reconcileCmd.Flags().StringVar(&reconcileCmdOpts.ApprovedBy, "approved-by", "", "user id of the admin approving the repair")
+
+	rootCmd.AddCommand(reconcileCmd)
+}
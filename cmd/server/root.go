@@ -21,71 +21,150 @@ package cmd
 // THE SOFTWARE.
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/bhojpur/wallet/pkg/config"
+	"github.com/bhojpur/wallet/pkg/engine"
 	"github.com/bhojpur/wallet/pkg/registry"
-	"github.com/bhojpur/wallet/pkg/routing"
 	"github.com/bhojpur/wallet/pkg/storage/postgres"
 
 	logger "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
-var verbose bool
+var (
+	verbose    bool
+	configFile string
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "wallet",
 	Short: "Bhojpur Wallet is a digital wallet processing engine powered by Kubernetes",
+	// errors of running the server are not about its usage, Execute prints them
+	SilenceUsage:  true,
+	SilenceErrors: true,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		if verbose {
 			logger.SetLevel(logger.DebugLevel)
 			logger.Debug("verbose logging enabled")
 		}
 	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, domain, err := newDomain(cmd)
+		if err != nil {
+			return err
+		}
+		if cfg.ReadOnly {
+			logger.Info("serving read-only, changes are refused")
+		}
+
+		// stop on the signal Kubernetes sends a pod, or on Ctrl-C
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		domain.Start()
+		s, err := serve(cfg, domain)
+		if err == nil {
+			select {
+			case <-ctx.Done():
+				logger.Info("shutting down")
+			case err = <-s.errs:
+				logger.WithError(err).Error("shutting down")
+			}
+		}
+		// a second signal stops the server right away
+		stop()
+
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if s != nil {
+			s.shutdown(ctx)
+		}
+		if shutdownErr := domain.Shutdown(ctx); shutdownErr != nil && err == nil {
+			err = shutdownErr
+		}
+		logger.Info("stopped")
 
-	// Uncomment the following line if your bare application
-	// has an action associated with it:
-	//	Run: func(cmd *cobra.Command, args []string) { },
+		return err
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	log.SetFlags(log.Lshortfile | log.LstdFlags)
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	log.SetFlags(log.Lshortfile | log.LstdFlags)
+}
 
-	// read yaml config file. Dont pass path to read
-	// from default path
-	yamlConfig := config.ReadYaml("")
-	config := config.GetConfig(*yamlConfig)
+// loadConfig reads the config file and overrides its settings from the
+// environment and the flags of the command
+func loadConfig(cmd *cobra.Command) (config.Config, error) {
+	yamlConfig, err := config.ReadYaml(configFile)
+	if err != nil {
+		return config.Config{}, err
+	}
 
-	database, err := postgres.NewDatabase(config)
+	err = yamlConfig.Override(os.LookupEnv, func(name string) (string, bool) {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || !flag.Changed {
+			return "", false
+		}
+		return flag.Value.String(), true
+	})
 	if err != nil {
-		log.Printf("database err %s", err)
-		os.Exit(1)
+		return config.Config{}, err
+	}
+
+	cfg := config.GetConfig(*yamlConfig)
+	return cfg, cfg.Validate()
+}
+
+// newDomain connects to the database, runs the migrations and
+// wires up the domain the server and the commands work with
+func newDomain(cmd *cobra.Command) (config.Config, *registry.Domain, error) {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return cfg, nil, err
+	}
+
+	database, err := postgres.NewDatabase(cfg)
+	if err != nil {
+		return cfg, nil, fmt.Errorf("connecting to the database: %w", err)
 	}
 
 	// run migrations; update tables
 	postgres.Migrate(database)
 
-	channels := registry.NewChannels()
-	domain := registry.NewDomain(config, database, channels)
+	signer, err := engine.LoadSigningKey(cfg.EngineKeyFile)
+	if err != nil {
+		return cfg, nil, fmt.Errorf("loading engine key: %w", err)
+	}
 
-	// create the fiber server.
-	server := routing.Router(domain, config) // add endpoints
+	channels := registry.NewChannels()
+	domain := registry.NewDomain(cfg, database, channels, signer)
 
-	// listen and serve
-	port := fmt.Sprintf(":%v", 6700)
-	log.Fatal(server.Listen(port))
+	return cfg, domain, nil
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "en/disable verbose logging")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", os.Getenv("WALLET_CONFIG_FILE"), "config file to read, the WALLET_* env vars and the flags override its settings (defaults to WALLET_CONFIG_FILE env var, or ./config.yml if there is one)")
+	for _, setting := range config.Settings {
+		usage := fmt.Sprintf("%s (or the %s env var)", setting.Usage, setting.Env())
+		if setting.Bool {
+			rootCmd.PersistentFlags().Bool(setting.Name, false, usage)
+			continue
+		}
+		rootCmd.PersistentFlags().String(setting.Name, "", usage)
+	}
 }
@@ -0,0 +1,214 @@
+package cmd
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+	"github.com/bhojpur/wallet/pkg/config"
+	"github.com/bhojpur/wallet/pkg/health"
+	"github.com/bhojpur/wallet/pkg/registry"
+	"github.com/bhojpur/wallet/pkg/routing"
+	"github.com/bhojpur/wallet/pkg/service"
+	"github.com/bhojpur/wallet/pkg/webui"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	logger "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// servers are the REST API, the gRPC API and the web UI of the wallet server
+type servers struct {
+	http          *fiber.App
+	grpc          *grpc.Server
+	ui            *http.Server
+	walletService *service.WalletService
+	healthService *service.HealthService
+	health        *health.Checker
+
+	// a server that fails sends its error
+	errs chan error
+}
+
+// serve starts the servers of the domain
+func serve(cfg config.Config, domain *registry.Domain) (*servers, error) {
+	httpTLS, err := cfg.Server.TLS.ServerConfig()
+	if err != nil {
+		return nil, err
+	}
+	grpcTLS, err := cfg.Server.TLS.GRPCConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// the gRPC server does its own TLS
+	var listeners []net.Listener
+	for _, l := range []struct {
+		port      int
+		tlsConfig *tls.Config
+	}{{cfg.Server.GRPCPort, nil}, {cfg.Server.UIPort, httpTLS}, {cfg.Server.HTTPPort, httpTLS}} {
+		listener, err := listen(l.port, l.tlsConfig)
+		if err != nil {
+			for _, listener := range listeners {
+				_ = listener.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, listener)
+	}
+	grpcListener, uiListener, httpListener := listeners[0], listeners[1], listeners[2]
+
+	// serve the wallet operations and the engines of the domain jobs over gRPC
+	// the WalletService is only for administrators, also through the web UI
+	grpcOpts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(service.AdminUnaryInterceptor(cfg.Secret)),
+		grpc.StreamInterceptor(service.AdminStreamInterceptor(cfg.Secret)),
+	}
+	if grpcTLS != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(grpcTLS)))
+	}
+	specs := service.DomainSpecs(domain)
+	s := &servers{
+		grpc:          grpc.NewServer(grpcOpts...),
+		walletService: service.NewWalletService(specs, cfg.ReadOnly),
+		healthService: service.NewHealthService(domain.Health),
+		health:        domain.Health,
+		errs:          make(chan error, 3),
+	}
+	v1.RegisterWalletOperationsServer(s.grpc, service.NewWalletOperations(domain, cfg))
+	v1.RegisterWalletServiceServer(s.grpc, s.walletService)
+	v1.RegisterWalletUIServer(s.grpc, service.NewWalletUI(specs, cfg.ReadOnly))
+	healthpb.RegisterHealthServer(s.grpc, s.healthService)
+	go func() {
+		if err := s.grpc.Serve(grpcListener); err != nil {
+			s.errs <- fmt.Errorf("grpc server: %w", err)
+		}
+	}()
+
+	// serve the web UI, it calls the gRPC services over gRPC-Web
+	s.ui = &http.Server{Handler: webUIHandler(s.grpc)}
+	go func() {
+		if err := s.ui.Serve(uiListener); err != http.ErrServerClosed {
+			s.errs <- fmt.Errorf("web ui server: %w", err)
+		}
+	}()
+
+	// create the fiber server.
+	s.http = routing.Router(domain, cfg) // add endpoints
+	go func() {
+		if err := s.http.Listener(httpListener); err != nil {
+			s.errs <- fmt.Errorf("http server: %w", err)
+		}
+	}()
+
+	logger.WithFields(logger.Fields{
+		"http": cfg.Server.HTTPPort,
+		"grpc": cfg.Server.GRPCPort,
+		"ui":   cfg.Server.UIPort,
+		"tls":  cfg.Server.TLS.Enabled(),
+		"mtls": grpcTLS != nil && grpcTLS.ClientCAs != nil,
+	}).Info("serving")
+
+	return s, nil
+}
+
+// shutdown stops the servers accepting connections and waits for the requests
+// in flight to finish, those still running when ctx ends are cut off
+func (s *servers) shutdown(ctx context.Context) {
+	// the probes tell that the server goes away
+	s.health.Shutdown()
+	s.healthService.Close()
+
+	// streams following engines would keep the gRPC server waiting, the
+	// engines still running finish before the domain shuts down
+	if err := s.walletService.Close(ctx); err != nil {
+		logger.WithError(err).Warn("engines shutdown")
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		if err := s.http.Shutdown(); err != nil {
+			logger.WithError(err).Warn("http server shutdown")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := s.ui.Shutdown(ctx); err != nil {
+			logger.WithError(err).Warn("web ui server shutdown")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		s.grpc.GracefulStop()
+	}()
+
+	drained := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		logger.Info("requests drained")
+	case <-ctx.Done():
+		logger.Warn("requests still in flight at the shutdown deadline are cut off")
+		s.grpc.Stop()
+		_ = s.ui.Close()
+	}
+}
+
+// listen listens on a port of all interfaces, with TLS when there is a config
+func listen(port int, tlsConfig *tls.Config) (net.Listener, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%v", port))
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	return listener, nil
+}
+
+// webUIHandler serves the web UI and passes its gRPC-Web calls to the gRPC server
+func webUIHandler(grpcServer *grpc.Server) http.Handler {
+	grpcWeb := grpcweb.WrapServer(grpcServer)
+	ui := webui.Handler()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if grpcWeb.IsGrpcWebRequest(r) || grpcWeb.IsAcceptableGrpcCorsRequest(r) {
+			grpcWeb.ServeHTTP(w, r)
+			return
+		}
+		ui.ServeHTTP(w, r)
+	})
+}
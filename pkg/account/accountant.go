@@ -28,9 +28,14 @@ import (
 	"github.com/gofrs/uuid"
 )
 
+// Accountant moves money in and out of accounts, recording each movement on the
+// ledger along with the counterparty the money came from or went to. A movement
+// with no other party, like a pocket deposit, has a zero counterparty.
+// DebitAvailableBalance, unlike DebitAccount, never draws on the overdraft.
 type Accountant interface {
-	DebitAccount(userID uuid.UUID, amount models.Paisas, reason models.TxnOperation) (float64, error)
-	CreditAccount(userID uuid.UUID, amount models.Paisas, reason models.TxnOperation) (float64, error)
+	DebitAccount(userID uuid.UUID, amount models.Paisas, reason models.TxnOperation, counterparty models.TxnCustomer) (float64, error)
+	DebitAvailableBalance(userID uuid.UUID, amount models.Paisas, reason models.TxnOperation, counterparty models.TxnCustomer) (float64, error)
+	CreditAccount(userID uuid.UUID, amount models.Paisas, reason models.TxnOperation, counterparty models.TxnCustomer) (float64, error)
 }
 
 func NewAccountant(accountRepo Repository, ledger statement.Ledger) Accountant {
@@ -59,20 +64,30 @@ func (a accountant) isUserAccAccessible(userID uuid.UUID) (*models.Account, erro
 
 }
 
-func (a accountant) CreditAccount(userID uuid.UUID, amount models.Paisas, reason models.TxnOperation) (float64, error) {
+func (a accountant) CreditAccount(userID uuid.UUID, amount models.Paisas, reason models.TxnOperation, counterparty models.TxnCustomer) (float64, error) {
 	acc, err := a.isUserAccAccessible(userID)
 	if err != nil {
 		return 0, err
 	}
 
-	// update balance with amount: add amount
-	amt := acc.Credit(amount)
-	*acc, err = a.repository.UpdateBalance(amt, userID)
+	// a debit-normal account can't be credited more than has been debited from it
+	if acc.IsDebitNormal() && acc.AvailableBalance < amount {
+		e := errors.ErrNotEnoughBalance{
+			Message: errors.DebitAmountAboveBalance,
+			Amount:  amount.ToRupees(),
+			Balance: acc.Balance(),
+		}
+		return 0, errors.Error{Err: e}
+	}
+
+	// update balance with amount: add amount, an outstanding overdraft is repaid first
+	balance, overdraftUsed := acc.Credit(amount)
+	*acc, err = a.repository.UpdateBalance(balance, overdraftUsed, userID)
 	if err != nil {
 		return 0, err
 	}
 
-	err = a.ledger.Record(userID, *acc, reason, amount.ToRupees(), statement.TypeCredit)
+	err = a.ledger.Record(userID, *acc, reason, amount, statement.TypeCredit, counterparty)
 	if err != nil {
 		return 0, err
 	}
@@ -80,13 +95,13 @@ func (a accountant) CreditAccount(userID uuid.UUID, amount models.Paisas, reason
 	return acc.Balance(), nil
 }
 
-func (a accountant) DebitAccount(userID uuid.UUID, amount models.Paisas, reason models.TxnOperation) (float64, error) {
+func (a accountant) DebitAccount(userID uuid.UUID, amount models.Paisas, reason models.TxnOperation, counterparty models.TxnCustomer) (float64, error) {
 	acc, err := a.isUserAccAccessible(userID)
 	if err != nil {
 		return 0, err
 	}
 
-	// check that balance is more than amount
+	// check that balance, together with any overdraft facility, is more than amount
 	if acc.IsBalanceLessThanAmount(amount) {
 		e := errors.ErrNotEnoughBalance{
 			Message: errors.DebitAmountAboveBalance,
@@ -96,14 +111,35 @@ func (a accountant) DebitAccount(userID uuid.UUID, amount models.Paisas, reason
 		return 0, errors.Error{Err: e}
 	}
 
-	// update balance with amount: subtract amount
-	amt := acc.Debit(amount)
-	*acc, err = a.repository.UpdateBalance(amt, userID)
+	// update balance with amount: subtract amount, what the balance can't cover is drawn from the overdraft
+	balance, overdraftUsed := acc.Debit(amount)
+	*acc, err = a.repository.UpdateBalance(balance, overdraftUsed, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = a.ledger.Record(userID, *acc, reason, amount, statement.TypeDebit, counterparty)
+	if err != nil {
+		return 0, err
+	}
+
+	return acc.Balance(), nil
+}
+
+func (a accountant) DebitAvailableBalance(userID uuid.UUID, amount models.Paisas, reason models.TxnOperation, counterparty models.TxnCustomer) (float64, error) {
+	acc, err := a.isUserAccAccessible(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// the balance is checked by the update itself, so that a debit running alongside
+	// can't leave this one to be covered by the overdraft
+	*acc, err = a.repository.DebitAvailableBalance(userID, amount)
 	if err != nil {
 		return 0, err
 	}
 
-	err = a.ledger.Record(userID, *acc, reason, amount.ToRupees(), statement.TypeDebit)
+	err = a.ledger.Record(userID, *acc, reason, amount, statement.TypeDebit, counterparty)
 	if err != nil {
 		return 0, err
 	}
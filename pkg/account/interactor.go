@@ -27,22 +27,24 @@ import (
 	"github.com/bhojpur/wallet/pkg/data"
 	"github.com/bhojpur/wallet/pkg/errors"
 	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/worker"
 
 	"github.com/gofrs/uuid"
 )
 
 type Interactor interface {
+	GetAccount(userID uuid.UUID) (models.Account, error)
 	GetBalance(userID uuid.UUID) (float64, error)
 }
 
-func NewInteractor(repository Repository, custChan data.ChanNewCustomers, transChan data.ChanNewTransactions) Interactor {
+func NewInteractor(repository Repository, custChan data.ChanNewCustomers, transChan data.ChanNewTransactions, workers *worker.Group) Interactor {
 	intr := &interactor{
 		repository:          repository,
 		customersChannel:    custChan,
 		transactionsChannel: transChan,
 	}
 
-	go intr.listenOnNewUsers()
+	workers.Go("new users listener", intr.listenOnNewUsers)
 
 	return intr
 }
@@ -79,6 +81,17 @@ func (i interactor) CreateAccount(userId uuid.UUID) (models.Account, error) {
 	return acc, nil
 }
 
+// GetAccount fetches the user's account, it is used to tell
+// customers the account number they can receive money with
+func (i interactor) GetAccount(userId uuid.UUID) (models.Account, error) {
+	acc, err := i.isUserAccAccessible(userId)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	return *acc, nil
+}
+
 // GetBalance fetches the user's account balance
 func (i interactor) GetBalance(userId uuid.UUID) (float64, error) {
 	acc, err := i.isUserAccAccessible(userId)
@@ -94,21 +107,29 @@ func (i interactor) postTransactionDetails(userId uuid.UUID, acc models.Account,
 	timestamp := time.Now()
 	newTransaction := parseTransactionDetails(userId, acc, txnOp, timestamp)
 
-	go func() { i.transactionsChannel.Writer <- *newTransaction }()
+	i.transactionsChannel.Send(*newTransaction)
 }
 
-func (i interactor) listenOnNewUsers() {
+func (i interactor) listenOnNewUsers(done <-chan struct{}) {
 	for {
 		select {
 		case customer := <-i.customersChannel.Reader:
-			acc, err := i.CreateAccount(customer.UserID)
-			if err != nil {
-				// we need to log this error
-				log.Printf("error happened while creating account %v", err)
-				continue
-			}
-			// we log the account details if created
-			log.Printf("account with id %v has been created successfully for customerID %v", acc.ID, customer.UserID)
+			i.createCustomerAccount(customer)
+		case <-done:
+			// customers who registered before the server stopped still get their account
+			i.customersChannel.Drain(i.createCustomerAccount)
+			return
 		}
 	}
 }
+
+func (i interactor) createCustomerAccount(customer data.CustomerContract) {
+	acc, err := i.CreateAccount(customer.UserID)
+	if err != nil {
+		// we need to log this error
+		log.Printf("error happened while creating account %v", err)
+		return
+	}
+	// we log the account details if created
+	log.Printf("account with id %v has been created successfully for customerID %v", acc.ID, customer.UserID)
+}
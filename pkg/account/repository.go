@@ -21,6 +21,9 @@ package account
 // THE SOFTWARE.
 
 import (
+	"strings"
+	"time"
+
 	"github.com/bhojpur/wallet/pkg/errors"
 	"github.com/bhojpur/wallet/pkg/models"
 	"github.com/bhojpur/wallet/pkg/storage"
@@ -31,10 +34,20 @@ import (
 )
 
 type Repository interface {
+	GetAccountByID(uuid.UUID) (models.Account, error)
 	GetAccountByUserID(uuid.UUID) (models.Account, error)
-	UpdateBalance(amount models.Paisas, userID uuid.UUID) (models.Account, error)
+	GetAccountByNumber(string) (models.Account, error)
+	UpdateBalance(balance, overdraftUsed models.Paisas, userID uuid.UUID) (models.Account, error)
+	DebitAvailableBalance(userID uuid.UUID, amount models.Paisas) (models.Account, error)
+	UpdateOverdraftLimit(userID uuid.UUID, tier models.CreditTier, limit models.Paisas) (models.Account, error)
+	GetAccountsInOverdraft(accruedBefore time.Time) ([]models.Account, error)
+	AccrueOverdraftCharge(userID uuid.UUID, charge models.Paisas, accruedAt time.Time) (models.Account, error)
+	ReleaseOverdraftCharge(userID uuid.UUID, charge models.Paisas, accruedAt time.Time, previous *time.Time) error
+
+	GetAccountsByType(accType models.AccountType) ([]models.Account, error)
 
 	Create(userId uuid.UUID) (models.Account, error)
+	CreateSystemAccount(userId uuid.UUID, accType models.AccountType) (models.Account, error)
 }
 
 type repository struct {
@@ -45,6 +58,19 @@ func NewRepository(database *storage.Database) Repository {
 	return &repository{db: database}
 }
 
+// GetAccountByID fetches an account by its primary id
+func (r repository) GetAccountByID(id uuid.UUID) (models.Account, error) {
+	var acc models.Account
+	result := r.db.Where(models.Account{ID: id}).First(&acc)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return models.Account{}, errors.Error{Code: errors.ENOTFOUND}
+	} else if result.Error != nil {
+		return models.Account{}, errors.Error{Err: result.Error, Code: errors.EINTERNAL}
+	}
+
+	return acc, nil
+}
+
 // GetAccountByUserID fetches an account tied to a user's id
 func (r repository) GetAccountByUserID(userID uuid.UUID) (models.Account, error) {
 	var acc models.Account
@@ -58,10 +84,27 @@ func (r repository) GetAccountByUserID(userID uuid.UUID) (models.Account, error)
 	return acc, nil
 }
 
-// UpdateBalance
-func (r repository) UpdateBalance(amount models.Paisas, userID uuid.UUID) (models.Account, error) {
+// GetAccountByNumber fetches an account by its account number
+func (r repository) GetAccountByNumber(accountNumber string) (models.Account, error) {
 	var acc models.Account
-	result := r.db.Model(models.Account{}).Where(models.Account{UserID: userID}).Updates(models.Account{AvailableBalance: amount}).Scan(&acc)
+	result := r.db.Where(models.Account{AccountNumber: accountNumber}).First(&acc)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return models.Account{}, errors.Error{Code: errors.ENOTFOUND}
+	} else if result.Error != nil {
+		return models.Account{}, errors.Error{Err: result.Error, Code: errors.EINTERNAL}
+	}
+
+	return acc, nil
+}
+
+// UpdateBalance sets the balance and outstanding overdraft of the user's account
+func (r repository) UpdateBalance(balance, overdraftUsed models.Paisas, userID uuid.UUID) (models.Account, error) {
+	var acc models.Account
+	// a map is used so that zero balances are not skipped by gorm
+	result := r.db.Model(models.Account{}).Where(models.Account{UserID: userID}).Updates(map[string]interface{}{
+		"available_balance": balance,
+		"overdraft_used":    overdraftUsed,
+	}).Scan(&acc)
 	if err := result.Error; err != nil {
 		return models.Account{}, errors.Error{Err: err, Code: errors.EINTERNAL}
 	}
@@ -69,30 +112,153 @@ func (r repository) UpdateBalance(amount models.Paisas, userID uuid.UUID) (model
 	return acc, nil
 }
 
-// Create a now account for userId
-func (r repository) Create(userId uuid.UUID) (models.Account, error) {
-	// check if user has an account and return it, otherwise create an account for user
-	acc := zeroAccount(userId)
-	result := r.db.Where(models.Account{UserID: userId}).FirstOrCreate(&acc)
+// DebitAvailableBalance takes amount from the available balance of the user's account,
+// it fails without changing the balance if the balance alone, without the overdraft,
+// holds less than amount
+func (r repository) DebitAvailableBalance(userID uuid.UUID, amount models.Paisas) (models.Account, error) {
+	result := r.db.Model(models.Account{}).Where("user_id = ? AND available_balance >= ?", userID, amount).
+		Update("available_balance", gorm.Expr("available_balance - ?", amount))
 	if err := result.Error; err != nil {
-		// we check if the error is a postgres unique constraint violation
-		if pgerr, ok := result.Error.(*pgconn.PgError); ok && pgerr.Code == "23505" {
-			return models.Account{}, errors.Error{Code: errors.ECONFLICT, Message: errors.ErrUserHasAccount(userId, acc.ID)}
+		return models.Account{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	acc, err := r.GetAccountByUserID(userID)
+	if err != nil {
+		return models.Account{}, err
+	}
+	if result.RowsAffected == 0 {
+		e := errors.ErrNotEnoughBalance{
+			Message: errors.DebitAmountAboveBalance,
+			Amount:  amount.ToRupees(),
+			Balance: acc.Balance(),
 		}
+		return models.Account{}, errors.Error{Err: e}
+	}
+
+	return acc, nil
+}
+
+// UpdateOverdraftLimit sets the credit tier and overdraft limit of the user's account
+func (r repository) UpdateOverdraftLimit(userID uuid.UUID, tier models.CreditTier, limit models.Paisas) (models.Account, error) {
+	var acc models.Account
+	result := r.db.Model(models.Account{}).Where(models.Account{UserID: userID}).Updates(map[string]interface{}{
+		"credit_tier":     tier,
+		"overdraft_limit": limit,
+	}).Scan(&acc)
+	if err := result.Error; err != nil {
 		return models.Account{}, errors.Error{Err: err, Code: errors.EINTERNAL}
 	}
 
 	return acc, nil
 }
 
-func zeroAccount(userId uuid.UUID) models.Account {
+// GetAccountsInOverdraft fetches accounts with an outstanding overdraft that have
+// not been charged since accruedBefore
+func (r repository) GetAccountsInOverdraft(accruedBefore time.Time) ([]models.Account, error) {
+	var accounts []models.Account
+	result := r.db.Where("overdraft_used > 0").
+		Where("overdraft_accrued_at IS NULL OR overdraft_accrued_at < ?", accruedBefore).
+		Find(&accounts)
+	if err := result.Error; err != nil {
+		return nil, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return accounts, nil
+}
+
+// AccrueOverdraftCharge adds a charge to the outstanding overdraft of the user's account. The
+// charge is only added once for accruedAt, an account already charged returns ENOTFOUND.
+func (r repository) AccrueOverdraftCharge(userID uuid.UUID, charge models.Paisas, accruedAt time.Time) (models.Account, error) {
+	result := r.db.Model(models.Account{}).
+		Where(models.Account{UserID: userID}).
+		Where("overdraft_accrued_at IS NULL OR overdraft_accrued_at < ?", accruedAt).
+		Updates(map[string]interface{}{
+			"overdraft_used":       gorm.Expr("overdraft_used + ?", charge),
+			"overdraft_accrued_at": accruedAt,
+		})
+	if err := result.Error; err != nil {
+		return models.Account{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+	if result.RowsAffected == 0 {
+		return models.Account{}, errors.Error{Code: errors.ENOTFOUND}
+	}
+
+	return r.GetAccountByUserID(userID)
+}
+
+// ReleaseOverdraftCharge undoes a charge accrued for accruedAt that could not be recorded, the
+// account is charged again on the next run. previous is when the account was charged before.
+func (r repository) ReleaseOverdraftCharge(userID uuid.UUID, charge models.Paisas, accruedAt time.Time, previous *time.Time) error {
+	result := r.db.Model(models.Account{}).
+		Where(models.Account{UserID: userID}).
+		Where("overdraft_accrued_at = ?", accruedAt).
+		Updates(map[string]interface{}{
+			"overdraft_used":       gorm.Expr("GREATEST(overdraft_used - ?, 0)", charge),
+			"overdraft_accrued_at": previous,
+		})
+	if err := result.Error; err != nil {
+		return errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return nil
+}
+
+// GetAccountsByType fetches all accounts of a type
+func (r repository) GetAccountsByType(accType models.AccountType) ([]models.Account, error) {
+	var accounts []models.Account
+	result := r.db.Where(models.Account{AccountType: accType}).Find(&accounts)
+	if err := result.Error; err != nil {
+		return nil, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return accounts, nil
+}
+
+// Create a now account for userId
+func (r repository) Create(userId uuid.UUID) (models.Account, error) {
+	return r.create(userId, models.AccTypeCurrent)
+}
+
+// CreateSystemAccount creates an account of the given type for a system user
+// such as models.SystemInterestExpenseUserID, it returns the account if it exists
+func (r repository) CreateSystemAccount(userId uuid.UUID, accType models.AccountType) (models.Account, error) {
+	return r.create(userId, accType)
+}
+
+func (r repository) create(userId uuid.UUID, accType models.AccountType) (models.Account, error) {
+	// check if user has an account and return it, otherwise create an account for user
+	var acc models.Account
+	for attempt := 0; attempt < models.NumberGenerationAttempts; attempt++ {
+		acc = zeroAccount(userId, accType)
+		result := r.db.Where(models.Account{UserID: userId}).FirstOrCreate(&acc)
+		if err := result.Error; err != nil {
+			// we check if the error is a postgres unique constraint violation
+			if pgerr, ok := result.Error.(*pgconn.PgError); ok && pgerr.Code == "23505" {
+				// the generated account number is taken, try another one
+				if strings.Contains(pgerr.ConstraintName, "account_number") {
+					continue
+				}
+				return models.Account{}, errors.Error{Code: errors.ECONFLICT, Message: errors.ErrUserHasAccount(userId, acc.ID)}
+			}
+			return models.Account{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+		}
+
+		return acc, nil
+	}
+
+	return models.Account{}, errors.Error{Code: errors.ECONFLICT, Message: errors.ErrNumberTaken}
+}
+
+func zeroAccount(userId uuid.UUID, accType models.AccountType) models.Account {
 	id, _ := uuid.NewV4()
 
 	return models.Account{
-		ID: id,
+		ID:            id,
+		AccountNumber: models.NewAccountNumber(),
 		// balance:     0, // no need to initialize with zero value, Go will do that for us
 		Status:      models.StatusActive,
-		AccountType: models.AccTypeCurrent,
+		AccountType: accType,
+		CreditTier:  models.TierNone,
 		UserID:      userId,
 	}
 }
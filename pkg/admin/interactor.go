@@ -98,7 +98,7 @@ func (i interactor) Register(params RegistrationParams) (models.Admin, error) {
 // money. It can also be used in subsequent operations to increase the amount of money in
 // the system.
 func (i interactor) AssignFloat(params AssignFloatParams) (float64, error) {
-	agent, err := i.customerFinder.FindAgentByEmail(params.AgentAccountNumber)
+	agent, err := i.customerFinder.FindAgent(params.AgentAccountNumber)
 	if err != nil {
 		return 0, err
 	}
@@ -108,7 +108,7 @@ func (i interactor) AssignFloat(params AssignFloatParams) (float64, error) {
 		return 0, errors.Error{Code: errors.EINVALID, Message: errors.ErrAgentNotSuperAgent}
 	}
 
-	balance, err := i.accountant.CreditAccount(agent.ID, params.Amount.ToPaisas(), models.TxnFloatAssignment)
+	balance, err := i.accountant.CreditAccount(agent.ID, params.Amount.ToPaisas(), models.TxnFloatAssignment, models.TxnCustomer{UserType: models.UserTypAdmin})
 	if err != nil {
 		return 0, err
 	}
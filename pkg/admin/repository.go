@@ -24,9 +24,9 @@ import (
 	"github.com/bhojpur/wallet/pkg/errors"
 	"github.com/bhojpur/wallet/pkg/models"
 	"github.com/bhojpur/wallet/pkg/storage"
+	"github.com/bhojpur/wallet/pkg/user"
 
 	"github.com/gofrs/uuid"
-	"github.com/jackc/pgconn"
 	"gorm.io/gorm"
 )
 
@@ -61,28 +61,31 @@ func (r repository) searchBy(row models.Admin) (models.Admin, error) {
 	return admin, nil
 }
 
-// Add an admin if already not in db.
+// Add an admin if not already in db.
 func (r repository) Add(admin models.Admin) (models.Admin, error) {
-	// add new admin to administrators table, if query return violation of unique key column,
-	// we know that the admin with given record exists and return that admin instead
-	result := r.db.Model(models.Admin{}).Create(&admin)
-	if err := result.Error; err != nil {
-		// we check if the error is a postgres unique constraint violation
-		if pgerr, ok := err.(*pgconn.PgError); ok && pgerr.Code == "23505" {
-			return admin, errors.Error{Code: errors.ECONFLICT, Message: errors.ErrUserExists}
+	err := user.Register(r.db, &admin, func() models.User {
+		return models.NewUser(admin.ID, models.UserTypAdmin, admin.Email, "", "")
+	}, "")
+	if err != nil {
+		if errors.ErrorCode(err) == errors.ECONFLICT {
+			return admin, err
 		}
-		return models.Admin{}, errors.Error{Err: result.Error, Code: errors.EINTERNAL}
+		return models.Admin{}, err
 	}
 
 	return admin, nil
 }
 
-// Delete a user
+// Delete an admin and their entry in the users directory
 func (r repository) Delete(admin models.Admin) error {
-	// TODO("If deleting permanently, delete record from users table too")
-	result := r.db.Delete(&admin)
-	if result.Error != nil {
-		return errors.Error{Err: result.Error, Code: errors.EINTERNAL}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&admin).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", admin.ID).Delete(&models.User{}).Error
+	})
+	if err != nil {
+		return errors.Error{Err: err, Code: errors.EINTERNAL}
 	}
 	return nil
 }
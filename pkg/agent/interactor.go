@@ -70,11 +70,18 @@ func (ui interactor) AuthenticateByEmail(email, password string) (models.Agent,
 
 // Register takes in a agent object and adds the agent to db.
 func (ui interactor) Register(params RegistrationParams) (models.Agent, error) {
+	// phone numbers are kept in E.164 format so that customers
+	// can be found by them regardless of how they were typed
+	phoneNumber, err := helpers.NormalizeOptionalPhoneNumber(params.PhoneNumber)
+	if err != nil {
+		return models.Agent{}, err
+	}
+
 	agent := models.Agent{
 		FirstName:   params.FirstName,
 		LastName:    params.LastName,
 		Email:       params.Email,
-		PhoneNumber: params.PhoneNumber,
+		PhoneNumber: phoneNumber,
 		Password:    params.Password,
 		PassportNo:  params.PassportNo,
 	}
@@ -87,7 +94,17 @@ func (ui interactor) Register(params RegistrationParams) (models.Agent, error) {
 
 	// change password to hashed string
 	agent.Password = passwordHash
-	agt, err := ui.repository.Add(agent)
+
+	// generate a agent number, and try again with another one
+	// in the unlikely case it has already been assigned
+	var agt models.Agent
+	for attempt := 0; attempt < models.NumberGenerationAttempts; attempt++ {
+		agent.AgentNumber = models.NewAgentNumber()
+		agt, err = ui.repository.Add(agent)
+		if errors.ErrorMessage(err) != string(errors.ErrNumberTaken) {
+			break
+		}
+	}
 	if err != nil {
 		return models.Agent{}, err
 	}
@@ -121,5 +138,5 @@ func (ui interactor) UpdateSuperAgentStatus(email string) error {
 // like creating an account for them automatically.
 func (ui interactor) postNewAgentToChannel(agent *models.Agent) {
 	newAgent := parseToNewAgent(*agent)
-	go func() { ui.customersChannel.Writer <- newAgent }()
+	ui.customersChannel.Send(newAgent)
 }
@@ -24,9 +24,9 @@ import (
 	"github.com/bhojpur/wallet/pkg/errors"
 	"github.com/bhojpur/wallet/pkg/models"
 	"github.com/bhojpur/wallet/pkg/storage"
+	"github.com/bhojpur/wallet/pkg/user"
 
 	"github.com/gofrs/uuid"
-	"github.com/jackc/pgconn"
 	"gorm.io/gorm"
 )
 
@@ -36,6 +36,8 @@ type Repository interface {
 	FetchAll() ([]models.Agent, error)
 	FindByID(uuid.UUID) (models.Agent, error)
 	FindByEmail(string) (models.Agent, error)
+	FindByPhoneNumber(string) (models.Agent, error)
+	FindByAgentNumber(string) (models.Agent, error)
 	Update(models.Agent) error
 }
 
@@ -61,27 +63,31 @@ func (r repository) searchBy(row models.Agent) (models.Agent, error) {
 	return agent, nil
 }
 
-// Add an agent if not in db.
+// Add an agent if not already in db.
 func (r repository) Add(agent models.Agent) (models.Agent, error) {
-	// add new agent to agents table, if query return violation of unique key column,
-	// we know that the agent with given record exists and return that agent instead
-	result := r.db.Model(models.Agent{}).Create(&agent)
-	if err := result.Error; err != nil {
-		// we check if the error is a postgres unique constraint violation
-		if pgerr, ok := err.(*pgconn.PgError); ok && pgerr.Code == "23505" {
-			return agent, errors.Error{Code: errors.ECONFLICT, Message: errors.ErrUserExists}
+	err := user.Register(r.db, &agent, func() models.User {
+		return models.NewUser(agent.ID, models.UserTypAgent, agent.Email, agent.PhoneNumber, agent.AgentNumber)
+	}, "agent_number")
+	if err != nil {
+		if errors.ErrorCode(err) == errors.ECONFLICT {
+			return agent, err
 		}
-		return models.Agent{}, errors.Error{Err: result.Error, Code: errors.EINTERNAL}
+		return models.Agent{}, err
 	}
 
 	return agent, nil
 }
 
-// Delete a agent
+// Delete an agent and their entry in the users directory
 func (r repository) Delete(agent models.Agent) error {
-	result := r.db.Delete(&agent)
-	if result.Error != nil {
-		return errors.Error{Err: result.Error, Code: errors.EINTERNAL}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&agent).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", agent.ID).Delete(&models.User{}).Error
+	})
+	if err != nil {
+		return errors.Error{Err: err, Code: errors.EINTERNAL}
 	}
 	return nil
 }
@@ -114,6 +120,18 @@ func (r repository) FindByEmail(email string) (models.Agent, error) {
 	return agent, err
 }
 
+// FindByPhoneNumber searches agent by phone number
+func (r repository) FindByPhoneNumber(phone string) (models.Agent, error) {
+	agent, err := r.searchBy(models.Agent{PhoneNumber: phone})
+	return agent, err
+}
+
+// FindByAgentNumber searches agent by agent number
+func (r repository) FindByAgentNumber(agentNumber string) (models.Agent, error) {
+	agent, err := r.searchBy(models.Agent{AgentNumber: agentNumber})
+	return agent, err
+}
+
 // Update
 func (r repository) Update(agent models.Agent) error {
 	var u models.Agent
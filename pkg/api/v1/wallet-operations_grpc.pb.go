@@ -0,0 +1,483 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// WalletOperationsClient is the client API for WalletOperations service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type WalletOperationsClient interface {
+	// Login authenticates a user by email and returns a bearer token
+	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	// Balance returns the balance of the account of the user
+	Balance(ctx context.Context, in *BalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error)
+	// MiniStatement returns the most recent transactions on the account of the user
+	MiniStatement(ctx context.Context, in *MiniStatementRequest, opts ...grpc.CallOption) (*MiniStatementResponse, error)
+	// Statement returns a page of the transactions on the account of the user in a period
+	Statement(ctx context.Context, in *StatementRequest, opts ...grpc.CallOption) (*StatementResponse, error)
+	// Deposit credits the account of a customer
+	Deposit(ctx context.Context, in *DepositRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
+	// Transfer moves money from the account of the user to another account
+	Transfer(ctx context.Context, in *TransferRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
+	// Withdraw debits the account of the user through an agent
+	Withdraw(ctx context.Context, in *WithdrawRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
+	// AssignFloat credits a super agent with float, it is an administrator only call
+	AssignFloat(ctx context.Context, in *AssignFloatRequest, opts ...grpc.CallOption) (*AssignFloatResponse, error)
+	// GetTariff returns the charges of every transaction, it is an administrator only call
+	GetTariff(ctx context.Context, in *GetTariffRequest, opts ...grpc.CallOption) (*GetTariffResponse, error)
+	// UpdateCharge changes the fee of a charge, it is an administrator only call
+	UpdateCharge(ctx context.Context, in *UpdateChargeRequest, opts ...grpc.CallOption) (*UpdateChargeResponse, error)
+	// UpdateSuperAgentStatus toggles whether an agent is a super agent, it is an administrator only call
+	UpdateSuperAgentStatus(ctx context.Context, in *UpdateSuperAgentStatusRequest, opts ...grpc.CallOption) (*UpdateSuperAgentStatusResponse, error)
+}
+
+type walletOperationsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWalletOperationsClient(cc grpc.ClientConnInterface) WalletOperationsClient {
+	return &walletOperationsClient{cc}
+}
+
+func (c *walletOperationsClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, "/v1.WalletOperations/Login", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletOperationsClient) Balance(ctx context.Context, in *BalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error) {
+	out := new(BalanceResponse)
+	err := c.cc.Invoke(ctx, "/v1.WalletOperations/Balance", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletOperationsClient) MiniStatement(ctx context.Context, in *MiniStatementRequest, opts ...grpc.CallOption) (*MiniStatementResponse, error) {
+	out := new(MiniStatementResponse)
+	err := c.cc.Invoke(ctx, "/v1.WalletOperations/MiniStatement", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletOperationsClient) Statement(ctx context.Context, in *StatementRequest, opts ...grpc.CallOption) (*StatementResponse, error) {
+	out := new(StatementResponse)
+	err := c.cc.Invoke(ctx, "/v1.WalletOperations/Statement", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletOperationsClient) Deposit(ctx context.Context, in *DepositRequest, opts ...grpc.CallOption) (*TransactionResponse, error) {
+	out := new(TransactionResponse)
+	err := c.cc.Invoke(ctx, "/v1.WalletOperations/Deposit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletOperationsClient) Transfer(ctx context.Context, in *TransferRequest, opts ...grpc.CallOption) (*TransactionResponse, error) {
+	out := new(TransactionResponse)
+	err := c.cc.Invoke(ctx, "/v1.WalletOperations/Transfer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletOperationsClient) Withdraw(ctx context.Context, in *WithdrawRequest, opts ...grpc.CallOption) (*TransactionResponse, error) {
+	out := new(TransactionResponse)
+	err := c.cc.Invoke(ctx, "/v1.WalletOperations/Withdraw", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletOperationsClient) AssignFloat(ctx context.Context, in *AssignFloatRequest, opts ...grpc.CallOption) (*AssignFloatResponse, error) {
+	out := new(AssignFloatResponse)
+	err := c.cc.Invoke(ctx, "/v1.WalletOperations/AssignFloat", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletOperationsClient) GetTariff(ctx context.Context, in *GetTariffRequest, opts ...grpc.CallOption) (*GetTariffResponse, error) {
+	out := new(GetTariffResponse)
+	err := c.cc.Invoke(ctx, "/v1.WalletOperations/GetTariff", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletOperationsClient) UpdateCharge(ctx context.Context, in *UpdateChargeRequest, opts ...grpc.CallOption) (*UpdateChargeResponse, error) {
+	out := new(UpdateChargeResponse)
+	err := c.cc.Invoke(ctx, "/v1.WalletOperations/UpdateCharge", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *walletOperationsClient) UpdateSuperAgentStatus(ctx context.Context, in *UpdateSuperAgentStatusRequest, opts ...grpc.CallOption) (*UpdateSuperAgentStatusResponse, error) {
+	out := new(UpdateSuperAgentStatusResponse)
+	err := c.cc.Invoke(ctx, "/v1.WalletOperations/UpdateSuperAgentStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WalletOperationsServer is the server API for WalletOperations service.
+// All implementations must embed UnimplementedWalletOperationsServer
+// for forward compatibility
+type WalletOperationsServer interface {
+	// Login authenticates a user by email and returns a bearer token
+	Login(context.Context, *LoginRequest) (*LoginResponse, error)
+	// Balance returns the balance of the account of the user
+	Balance(context.Context, *BalanceRequest) (*BalanceResponse, error)
+	// MiniStatement returns the most recent transactions on the account of the user
+	MiniStatement(context.Context, *MiniStatementRequest) (*MiniStatementResponse, error)
+	// Statement returns a page of the transactions on the account of the user in a period
+	Statement(context.Context, *StatementRequest) (*StatementResponse, error)
+	// Deposit credits the account of a customer
+	Deposit(context.Context, *DepositRequest) (*TransactionResponse, error)
+	// Transfer moves money from the account of the user to another account
+	Transfer(context.Context, *TransferRequest) (*TransactionResponse, error)
+	// Withdraw debits the account of the user through an agent
+	Withdraw(context.Context, *WithdrawRequest) (*TransactionResponse, error)
+	// AssignFloat credits a super agent with float, it is an administrator only call
+	AssignFloat(context.Context, *AssignFloatRequest) (*AssignFloatResponse, error)
+	// GetTariff returns the charges of every transaction, it is an administrator only call
+	GetTariff(context.Context, *GetTariffRequest) (*GetTariffResponse, error)
+	// UpdateCharge changes the fee of a charge, it is an administrator only call
+	UpdateCharge(context.Context, *UpdateChargeRequest) (*UpdateChargeResponse, error)
+	// UpdateSuperAgentStatus toggles whether an agent is a super agent, it is an administrator only call
+	UpdateSuperAgentStatus(context.Context, *UpdateSuperAgentStatusRequest) (*UpdateSuperAgentStatusResponse, error)
+	mustEmbedUnimplementedWalletOperationsServer()
+}
+
+// UnimplementedWalletOperationsServer must be embedded to have forward compatible implementations.
+type UnimplementedWalletOperationsServer struct {
+}
+
+func (UnimplementedWalletOperationsServer) Login(context.Context, *LoginRequest) (*LoginResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
+}
+func (UnimplementedWalletOperationsServer) Balance(context.Context, *BalanceRequest) (*BalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Balance not implemented")
+}
+func (UnimplementedWalletOperationsServer) MiniStatement(context.Context, *MiniStatementRequest) (*MiniStatementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MiniStatement not implemented")
+}
+func (UnimplementedWalletOperationsServer) Statement(context.Context, *StatementRequest) (*StatementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Statement not implemented")
+}
+func (UnimplementedWalletOperationsServer) Deposit(context.Context, *DepositRequest) (*TransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Deposit not implemented")
+}
+func (UnimplementedWalletOperationsServer) Transfer(context.Context, *TransferRequest) (*TransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transfer not implemented")
+}
+func (UnimplementedWalletOperationsServer) Withdraw(context.Context, *WithdrawRequest) (*TransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Withdraw not implemented")
+}
+func (UnimplementedWalletOperationsServer) AssignFloat(context.Context, *AssignFloatRequest) (*AssignFloatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AssignFloat not implemented")
+}
+func (UnimplementedWalletOperationsServer) GetTariff(context.Context, *GetTariffRequest) (*GetTariffResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTariff not implemented")
+}
+func (UnimplementedWalletOperationsServer) UpdateCharge(context.Context, *UpdateChargeRequest) (*UpdateChargeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCharge not implemented")
+}
+func (UnimplementedWalletOperationsServer) UpdateSuperAgentStatus(context.Context, *UpdateSuperAgentStatusRequest) (*UpdateSuperAgentStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSuperAgentStatus not implemented")
+}
+func (UnimplementedWalletOperationsServer) mustEmbedUnimplementedWalletOperationsServer() {}
+
+// UnsafeWalletOperationsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WalletOperationsServer will
+// result in compilation errors.
+type UnsafeWalletOperationsServer interface {
+	mustEmbedUnimplementedWalletOperationsServer()
+}
+
+func RegisterWalletOperationsServer(s grpc.ServiceRegistrar, srv WalletOperationsServer) {
+	s.RegisterService(&WalletOperations_ServiceDesc, srv)
+}
+
+func _WalletOperations_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletOperationsServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WalletOperations/Login",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletOperationsServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletOperations_Balance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletOperationsServer).Balance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WalletOperations/Balance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletOperationsServer).Balance(ctx, req.(*BalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletOperations_MiniStatement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MiniStatementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletOperationsServer).MiniStatement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WalletOperations/MiniStatement",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletOperationsServer).MiniStatement(ctx, req.(*MiniStatementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletOperations_Statement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletOperationsServer).Statement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WalletOperations/Statement",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletOperationsServer).Statement(ctx, req.(*StatementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletOperations_Deposit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DepositRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletOperationsServer).Deposit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WalletOperations/Deposit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletOperationsServer).Deposit(ctx, req.(*DepositRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletOperations_Transfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletOperationsServer).Transfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WalletOperations/Transfer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletOperationsServer).Transfer(ctx, req.(*TransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletOperations_Withdraw_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WithdrawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletOperationsServer).Withdraw(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WalletOperations/Withdraw",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletOperationsServer).Withdraw(ctx, req.(*WithdrawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletOperations_AssignFloat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AssignFloatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletOperationsServer).AssignFloat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WalletOperations/AssignFloat",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletOperationsServer).AssignFloat(ctx, req.(*AssignFloatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletOperations_GetTariff_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTariffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletOperationsServer).GetTariff(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WalletOperations/GetTariff",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletOperationsServer).GetTariff(ctx, req.(*GetTariffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletOperations_UpdateCharge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateChargeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletOperationsServer).UpdateCharge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WalletOperations/UpdateCharge",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletOperationsServer).UpdateCharge(ctx, req.(*UpdateChargeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WalletOperations_UpdateSuperAgentStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSuperAgentStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WalletOperationsServer).UpdateSuperAgentStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1.WalletOperations/UpdateSuperAgentStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WalletOperationsServer).UpdateSuperAgentStatus(ctx, req.(*UpdateSuperAgentStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// WalletOperations_ServiceDesc is the grpc.ServiceDesc for WalletOperations service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WalletOperations_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "v1.WalletOperations",
+	HandlerType: (*WalletOperationsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Login",
+			Handler:    _WalletOperations_Login_Handler,
+		},
+		{
+			MethodName: "Balance",
+			Handler:    _WalletOperations_Balance_Handler,
+		},
+		{
+			MethodName: "MiniStatement",
+			Handler:    _WalletOperations_MiniStatement_Handler,
+		},
+		{
+			MethodName: "Statement",
+			Handler:    _WalletOperations_Statement_Handler,
+		},
+		{
+			MethodName: "Deposit",
+			Handler:    _WalletOperations_Deposit_Handler,
+		},
+		{
+			MethodName: "Transfer",
+			Handler:    _WalletOperations_Transfer_Handler,
+		},
+		{
+			MethodName: "Withdraw",
+			Handler:    _WalletOperations_Withdraw_Handler,
+		},
+		{
+			MethodName: "AssignFloat",
+			Handler:    _WalletOperations_AssignFloat_Handler,
+		},
+		{
+			MethodName: "GetTariff",
+			Handler:    _WalletOperations_GetTariff_Handler,
+		},
+		{
+			MethodName: "UpdateCharge",
+			Handler:    _WalletOperations_UpdateCharge_Handler,
+		},
+		{
+			MethodName: "UpdateSuperAgentStatus",
+			Handler:    _WalletOperations_UpdateSuperAgentStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "wallet-operations.proto",
+}
@@ -22,8 +22,10 @@ package auth
 
 import (
 	"log"
+	"strings"
 	"time"
 
+	"github.com/bhojpur/wallet/pkg/errors"
 	"github.com/bhojpur/wallet/pkg/models"
 
 	"github.com/dgrijalva/jwt-go"
@@ -99,3 +101,29 @@ func ValidateToken(tok *jwt.Token) bool {
 	}
 	return true
 }
+
+// Authenticate checks the bearer token of an authorization value, as sent in
+// the Authorization header or the authorization metadata of gRPC calls, and
+// returns the user it was given to
+func Authenticate(authorization, secret string) (UserAuthDetails, error) {
+	// check that the token value is set
+	bearer := strings.Split(authorization, " ")
+	if len(bearer) < 2 || bearer[1] == "" {
+		return UserAuthDetails{}, errors.Unauthorized{Message: "authentication token not set"}
+	}
+
+	var claims TokenClaims
+	token, err := ParseToken(bearer[1], secret, &claims)
+	if err != nil {
+		if err == jwt.ErrSignatureInvalid {
+			return UserAuthDetails{}, errors.Unauthorized{Message: "invalid signature on token"}
+		}
+
+		return UserAuthDetails{}, errors.Unauthorized{Message: "token has expired or is invalid"}
+	}
+	if valid := ValidateToken(token); !valid {
+		return UserAuthDetails{}, errors.Unauthorized{Message: "invalid token"}
+	}
+
+	return claims.User, nil
+}
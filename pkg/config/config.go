@@ -22,6 +22,9 @@ package config
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Database struct {
@@ -43,13 +46,55 @@ func (d Database) String(sslmode string) string {
 		"", d.Host, d.Port, d.User, d.DBName, d.Password, sslmode)
 }
 
+// Server holds where the servers listen and how they secure connections
+type Server struct {
+	HTTPPort int
+	GRPCPort int
+	UIPort   int
+
+	TLS TLS
+
+	ShutdownTimeout time.Duration
+}
+
 type Config struct {
-	DB Database
+	DB     Database
+	Server Server
 
 	Secret string
+
+	EngineKeyFile string
+
+	ReadOnly bool
+}
+
+// defaultEngineKeyFile is where the engine key is kept unless configured
+const (
+	defaultEngineKeyFile = "./engine_key.pem"
+	defaultHTTPPort      = 6700
+	defaultGRPCPort      = 7777
+	defaultUIPort        = 8080
+	// under the 30 seconds Kubernetes gives a pod to stop
+	defaultShutdownTimeout = 25 * time.Second
+)
+
+func orDefault(port, def int) int {
+	if port == 0 {
+		return def
+	}
+	return port
 }
 
 func GetConfig(cfg YamlConfig) Config {
+	engineKeyFile := cfg.EngineKeyFile
+	if engineKeyFile == "" {
+		engineKeyFile = defaultEngineKeyFile
+	}
+	shutdownTimeout := cfg.Server.ShutdownTimeout
+	if shutdownTimeout == 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+
 	return Config{
 		DB: Database{
 			User:     cfg.Database.User,
@@ -58,7 +103,70 @@ func GetConfig(cfg YamlConfig) Config {
 			Port:     cfg.Database.Port,
 			DBName:   cfg.Database.DBName,
 		},
+		Server: Server{
+			HTTPPort: orDefault(cfg.Server.HTTPPort, defaultHTTPPort),
+			GRPCPort: orDefault(cfg.Server.GRPCPort, defaultGRPCPort),
+			UIPort:   orDefault(cfg.Server.UIPort, defaultUIPort),
+			TLS: TLS{
+				CertFile:     cfg.Server.TLS.CertFile,
+				KeyFile:      cfg.Server.TLS.KeyFile,
+				ClientCAFile: cfg.Server.TLS.ClientCAFile,
+			},
+			ShutdownTimeout: shutdownTimeout,
+		},
 
 		Secret: cfg.AppSecret,
+
+		EngineKeyFile: engineKeyFile,
+
+		ReadOnly: cfg.ReadOnly,
+	}
+}
+
+// Validate reports every setting the server can't start with
+func (c Config) Validate() error {
+	var problems []string
+	required := func(value, setting string) {
+		if value == "" {
+			problems = append(problems, setting+" is required")
+		}
+	}
+
+	required(c.DB.Host, "database host")
+	required(c.DB.User, "database user")
+	required(c.DB.DBName, "database name")
+	required(c.Secret, "app secret key")
+	if _, err := strconv.Atoi(c.DB.Port); c.DB.Port != "" && err != nil {
+		problems = append(problems, fmt.Sprintf("database port %q is not a number", c.DB.Port))
+	}
+
+	ports := map[int]string{}
+	for _, port := range []struct {
+		name string
+		port int
+	}{{"http", c.Server.HTTPPort}, {"grpc", c.Server.GRPCPort}, {"ui", c.Server.UIPort}} {
+		if port.port < 1 || port.port > 65535 {
+			problems = append(problems, fmt.Sprintf("%s port %d is out of range", port.name, port.port))
+		} else if other, ok := ports[port.port]; ok {
+			problems = append(problems, fmt.Sprintf("%s port %d is the %s port too", port.name, port.port, other))
+		}
+		ports[port.port] = port.name
+	}
+
+	if c.Server.ShutdownTimeout < 0 {
+		problems = append(problems, fmt.Sprintf("shutdown timeout %v is negative", c.Server.ShutdownTimeout))
+	}
+
+	tls := c.Server.TLS
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		problems = append(problems, "tls cert file and key file have to be set together")
+	}
+	if tls.ClientCAFile != "" && tls.CertFile == "" {
+		problems = append(problems, "tls client ca file needs a tls cert file")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config: %s", strings.Join(problems, ", "))
 	}
+	return nil
 }
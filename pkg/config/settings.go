@@ -0,0 +1,124 @@
+package config
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix starts the environment variables that override settings of the config file
+const EnvPrefix = "WALLET_"
+
+// Setting is a field of the config file that the environment or a flag can override
+type Setting struct {
+	// Name is the flag of the setting
+	Name  string
+	Usage string
+	Bool  bool
+
+	set func(cfg *YamlConfig, value string) error
+}
+
+// Env returns the environment variable of the setting, WALLET_ and its name
+// in upper case, e.g. WALLET_DATABASE_HOST for database-host
+func (s Setting) Env() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.Name, "-", "_"))
+}
+
+// Settings covers every field of the config file
+var Settings = []Setting{
+	stringSetting("database-host", "host of the postgres database", func(cfg *YamlConfig) *string { return &cfg.Database.Host }),
+	stringSetting("database-port", "port of the postgres database", func(cfg *YamlConfig) *string { return &cfg.Database.Port }),
+	stringSetting("database-user", "user to connect to the database as", func(cfg *YamlConfig) *string { return &cfg.Database.User }),
+	stringSetting("database-password", "password of the database user", func(cfg *YamlConfig) *string { return &cfg.Database.Password }),
+	stringSetting("database-name", "name of the database", func(cfg *YamlConfig) *string { return &cfg.Database.DBName }),
+	intSetting("http-port", fmt.Sprintf("port of the REST API, %d if not set", defaultHTTPPort), func(cfg *YamlConfig) *int { return &cfg.Server.HTTPPort }),
+	intSetting("grpc-port", fmt.Sprintf("port of the gRPC API, %d if not set", defaultGRPCPort), func(cfg *YamlConfig) *int { return &cfg.Server.GRPCPort }),
+	intSetting("ui-port", fmt.Sprintf("port of the web UI, %d if not set", defaultUIPort), func(cfg *YamlConfig) *int { return &cfg.Server.UIPort }),
+	durationSetting("shutdown-timeout", fmt.Sprintf("how long stopping waits for requests in flight and background work, %v if not set", defaultShutdownTimeout), func(cfg *YamlConfig) *time.Duration { return &cfg.Server.ShutdownTimeout }),
+	stringSetting("tls-cert-file", "PEM certificate the servers listen with, TLS is off without one", func(cfg *YamlConfig) *string { return &cfg.Server.TLS.CertFile }),
+	stringSetting("tls-key-file", "PEM key of the TLS certificate", func(cfg *YamlConfig) *string { return &cfg.Server.TLS.KeyFile }),
+	stringSetting("tls-client-ca-file", "PEM CA gRPC clients have to present a certificate of, for mutual TLS", func(cfg *YamlConfig) *string { return &cfg.Server.TLS.ClientCAFile }),
+	stringSetting("app-secret-key", "secret the bearer tokens are signed with", func(cfg *YamlConfig) *string { return &cfg.AppSecret }),
+	stringSetting("engine-key-file", fmt.Sprintf("PEM file of the engine key, created if missing, %s if not set", defaultEngineKeyFile), func(cfg *YamlConfig) *string { return &cfg.EngineKeyFile }),
+	boolSetting("read-only", "refuse changes through the APIs and hide them in the web UI", func(cfg *YamlConfig) *bool { return &cfg.ReadOnly }),
+}
+
+// Override sets the settings the environment or the flags have a value for, a
+// flag takes precedence over the environment, both over the config file
+func (cfg *YamlConfig) Override(lookupEnv, lookupFlag func(name string) (string, bool)) error {
+	for _, s := range Settings {
+		if value, ok := lookupEnv(s.Env()); ok {
+			if err := s.set(cfg, value); err != nil {
+				return fmt.Errorf("%s: %w", s.Env(), err)
+			}
+		}
+		if value, ok := lookupFlag(s.Name); ok {
+			if err := s.set(cfg, value); err != nil {
+				return fmt.Errorf("--%s: %w", s.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func stringSetting(name, usage string, field func(cfg *YamlConfig) *string) Setting {
+	return Setting{Name: name, Usage: usage, set: func(cfg *YamlConfig, value string) error {
+		*field(cfg) = value
+		return nil
+	}}
+}
+
+func intSetting(name, usage string, field func(cfg *YamlConfig) *int) Setting {
+	return Setting{Name: name, Usage: usage, set: func(cfg *YamlConfig, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		*field(cfg) = n
+		return nil
+	}}
+}
+
+func durationSetting(name, usage string, field func(cfg *YamlConfig) *time.Duration) Setting {
+	return Setting{Name: name, Usage: usage, set: func(cfg *YamlConfig, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 25s", value)
+		}
+		*field(cfg) = d
+		return nil
+	}}
+}
+
+func boolSetting(name, usage string, field func(cfg *YamlConfig) *bool) Setting {
+	return Setting{Name: name, Usage: usage, Bool: true, set: func(cfg *YamlConfig, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		*field(cfg) = b
+		return nil
+	}}
+}
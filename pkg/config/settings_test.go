@@ -0,0 +1,115 @@
+package config
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"strings"
+	"testing"
+)
+
+func lookup(values map[string]string) func(name string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	}
+}
+
+func TestOverride(t *testing.T) {
+	cfg := YamlConfig{
+		Database:  DatabaseConfig{Host: "file", User: "file", DBName: "file"},
+		Server:    ServerConfig{HTTPPort: 1000},
+		AppSecret: "file",
+	}
+	env := lookup(map[string]string{
+		"WALLET_DATABASE_HOST": "env",
+		"WALLET_DATABASE_USER": "env",
+		"WALLET_GRPC_PORT":     "2000",
+		"WALLET_READ_ONLY":     "true",
+	})
+	flags := lookup(map[string]string{
+		"database-host": "flag",
+		"ui-port":       "3000",
+	})
+	if err := cfg.Override(env, flags); err != nil {
+		t.Fatal(err)
+	}
+
+	got := GetConfig(cfg)
+	if got.DB.Host != "flag" || got.DB.User != "env" || got.DB.DBName != "file" || got.Secret != "file" {
+		t.Errorf("database %+v, secret %q, want the flag over the environment over the file", got.DB, got.Secret)
+	}
+	if got.Server.HTTPPort != 1000 || got.Server.GRPCPort != 2000 || got.Server.UIPort != 3000 {
+		t.Errorf("ports %+v, want 1000, 2000 and 3000", got.Server)
+	}
+	if !got.ReadOnly {
+		t.Error("not read-only, want WALLET_READ_ONLY to set it")
+	}
+	if got.EngineKeyFile != defaultEngineKeyFile {
+		t.Errorf("engine key file %q, want the default", got.EngineKeyFile)
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("validating: %v", err)
+	}
+}
+
+func TestOverrideInvalid(t *testing.T) {
+	var cfg YamlConfig
+	err := cfg.Override(lookup(map[string]string{"WALLET_HTTP_PORT": "http"}), lookup(nil))
+	if err == nil || !strings.HasPrefix(err.Error(), "WALLET_HTTP_PORT") {
+		t.Errorf("error %v, want one naming WALLET_HTTP_PORT", err)
+	}
+
+	err = cfg.Override(lookup(nil), lookup(map[string]string{"read-only": "maybe"}))
+	if err == nil || !strings.HasPrefix(err.Error(), "--read-only") {
+		t.Errorf("error %v, want one naming --read-only", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := GetConfig(YamlConfig{
+		Database: DatabaseConfig{Port: "postgres"},
+		Server: ServerConfig{
+			HTTPPort: 7777,
+			UIPort:   70000,
+			TLS:      TLSConfig{KeyFile: "key.pem", ClientCAFile: "ca.pem"},
+		},
+	})
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("no error, want the config to be invalid")
+	}
+	for _, problem := range []string{
+		"database host is required",
+		"database user is required",
+		"database name is required",
+		"app secret key is required",
+		`database port "postgres" is not a number`,
+		"grpc port 7777 is the http port too",
+		"ui port 70000 is out of range",
+		"tls cert file and key file have to be set together",
+		"tls client ca file needs a tls cert file",
+	} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("error %q doesn't tell %q", err, problem)
+		}
+	}
+}
@@ -22,8 +22,9 @@ package config
 
 import (
 	"fmt"
-	"log"
+	"io"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
@@ -36,42 +37,58 @@ type DatabaseConfig struct {
 	DBName   string `yaml:"dbname"`
 }
 
-// This maps the configuration in the yaml file
-// into a struct
+type ServerConfig struct {
+	HTTPPort int `yaml:"http_port"`
+	GRPCPort int `yaml:"grpc_port"`
+	// port of the web UI and its gRPC-Web calls
+	UIPort int       `yaml:"ui_port"`
+	TLS    TLSConfig `yaml:"tls"`
+	// how long stopping waits for requests in flight and the background workers
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// CA gRPC clients have to present a certificate of, mutual TLS is off without one
+	ClientCAFile string `yaml:"client_ca_file"`
+}
+
 type YamlConfig struct {
 	Database DatabaseConfig `yaml:"database"`
+	Server   ServerConfig   `yaml:"server"`
 
 	AppSecret string `yaml:"app_secret_key"`
+	// PEM file holding the engine key the server signs with, created if missing
+	EngineKeyFile string `yaml:"engine_key_file"`
+	// refuse changes through the REST and gRPC APIs and hide them in the web UI
+	ReadOnly bool `yaml:"read_only"`
 }
 
-func ReadYaml(path string) *YamlConfig {
-	if path == "" {
-		path = defaultYamlConfigPath()
+const defaultYamlConfigPath = "./config.yml"
+
+// ReadYaml reads the config file at path. Without a path it reads ./config.yml
+// if there is one, the settings can as well all come from the environment.
+func ReadYaml(path string) (*YamlConfig, error) {
+	explicit := path != ""
+	if !explicit {
+		path = defaultYamlConfigPath
 	}
 
+	var cfg YamlConfig
 	f, err := os.Open(path)
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(2)
+		if !explicit && os.IsNotExist(err) {
+			return &cfg, nil
+		}
+		return nil, fmt.Errorf("error opening config file: %w", err)
 	}
 	defer func() { _ = f.Close() }()
 
-	var cfg YamlConfig
 	decoder := yaml.NewDecoder(f)
 	err = decoder.Decode(&cfg)
-	if err != nil {
-		fmt.Printf("error reading yaml file into config struct: %s\n", err)
-		os.Exit(2)
-	}
-	return &cfg
-}
-
-func defaultYamlConfigPath() string {
-	_, err := os.Executable()
-	if err != nil {
-		log.Printf("error encountered reading path: %s\n", err)
-		os.Exit(2)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("error reading yaml file %s into config struct: %w", path, err)
 	}
-
-	return "./config.yml"
+	return &cfg, nil
 }
@@ -21,89 +21,137 @@ package customer
 // THE SOFTWARE.
 
 import (
+	"github.com/bhojpur/wallet/pkg/account"
 	"github.com/bhojpur/wallet/pkg/agent"
 	"github.com/bhojpur/wallet/pkg/errors"
 	"github.com/bhojpur/wallet/pkg/merchant"
 	"github.com/bhojpur/wallet/pkg/models"
 	"github.com/bhojpur/wallet/pkg/subscriber"
+	"github.com/bhojpur/wallet/pkg/user"
 
 	"github.com/gofrs/uuid"
 )
 
+// Finder looks up customers by any of their identifiers. An identifier can be
+// an email, an E.164 phone number, an account number, a till number for a merchant
+// or an agent number for an agent.
+//
+// Identifiers are resolved through the users directory, so the type of the customer
+// does not need to be known beforehand.
 type Finder interface {
-	FindAgentByEmail(string) (models.Agent, error)
-	FindMerchantByEmail(string) (models.Merchant, error)
-	FindSubscriberByEmail(string) (models.Subscriber, error)
-	FindIDByEmail(string, models.UserType) (uuid.UUID, error)
+	FindUser(identifier string, userType models.UserType) (models.User, error)
+	FindAgent(identifier string) (models.Agent, error)
+	FindMerchant(identifier string) (models.Merchant, error)
+	FindSubscriber(identifier string) (models.Subscriber, error)
+	FindID(identifier string, userType models.UserType) (uuid.UUID, error)
 }
 
-func NewFinder(agentRepo agent.Repository, merchRepo merchant.Repository, subRepo subscriber.Repository) Finder {
+func NewFinder(userRepo user.Repository, agentRepo agent.Repository, merchRepo merchant.Repository, subRepo subscriber.Repository, accRepo account.Repository) Finder {
 	return &finder{
+		userRepo:  userRepo,
 		agentRepo: agentRepo,
 		merchRepo: merchRepo,
 		subRepo:   subRepo,
+		accRepo:   accRepo,
 	}
 }
 
 type finder struct {
+	userRepo  user.Repository
 	agentRepo agent.Repository
 	merchRepo merchant.Repository
 	subRepo   subscriber.Repository
+	accRepo   account.Repository
 }
 
-func (f finder) FindAgentByEmail(email string) (models.Agent, error) {
-	agt, err := f.agentRepo.FindByEmail(email)
-	if errors.ErrorCode(err) == errors.ENOTFOUND {
-		return models.Agent{}, errors.Error{Err: err, Message: errors.ErrUserNotFound}
-	} else if err != nil {
+// FindUser resolves an identifier to the customer that owns it. If userType is
+// empty, a customer of any type is returned, otherwise the customer must be of
+// the given type. Administrators are not customers and are never returned.
+func (f finder) FindUser(identifier string, userType models.UserType) (models.User, error) {
+	kind, value, err := parseIdentifier(identifier)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	var usr models.User
+	switch kind {
+	case identifierEmail:
+		usr, err = f.userRepo.FindByEmail(value)
+	case identifierPhoneNumber:
+		usr, err = f.userRepo.FindByPhoneNumber(value)
+	case identifierTillNumber, identifierAgentNumber:
+		usr, err = f.userRepo.FindByCustomerNumber(value)
+	case identifierAccountNumber:
+		var acc models.Account
+		if acc, err = f.accRepo.GetAccountByNumber(value); err == nil {
+			usr, err = f.userRepo.FindByID(acc.UserID)
+		}
+	default:
+		err = errors.Error{Code: errors.ENOTFOUND}
+	}
+	if err != nil {
+		return models.User{}, userNotFound(err)
+	}
+
+	if usr.UserType == models.UserTypAdmin || (userType != "" && usr.UserType != userType) {
+		return models.User{}, errors.Error{Code: errors.ENOTFOUND, Message: errors.ErrUserNotFound}
+	}
+	return usr, nil
+}
+
+func (f finder) FindAgent(identifier string) (models.Agent, error) {
+	usr, err := f.FindUser(identifier, models.UserTypAgent)
+	if err != nil {
 		return models.Agent{}, err
 	}
 
+	agt, err := f.agentRepo.FindByID(usr.UserID)
+	if err != nil {
+		return models.Agent{}, userNotFound(err)
+	}
 	return agt, nil
 }
 
-func (f finder) FindMerchantByEmail(email string) (models.Merchant, error) {
-	merch, err := f.merchRepo.FindByEmail(email)
-	if errors.ErrorCode(err) == errors.ENOTFOUND {
-		return models.Merchant{}, errors.Error{Err: err, Message: errors.ErrUserNotFound}
-	} else if err != nil {
+func (f finder) FindMerchant(identifier string) (models.Merchant, error) {
+	usr, err := f.FindUser(identifier, models.UserTypMerchant)
+	if err != nil {
 		return models.Merchant{}, err
 	}
 
+	merch, err := f.merchRepo.FindByID(usr.UserID)
+	if err != nil {
+		return models.Merchant{}, userNotFound(err)
+	}
 	return merch, nil
 }
 
-func (f finder) FindSubscriberByEmail(email string) (models.Subscriber, error) {
-	sub, err := f.subRepo.FindByEmail(email)
-	if errors.ErrorCode(err) == errors.ENOTFOUND {
-		return models.Subscriber{}, errors.Error{Err: err, Message: errors.ErrUserNotFound}
-	} else if err != nil {
+func (f finder) FindSubscriber(identifier string) (models.Subscriber, error) {
+	usr, err := f.FindUser(identifier, models.UserTypSubscriber)
+	if err != nil {
 		return models.Subscriber{}, err
 	}
 
+	sub, err := f.subRepo.FindByID(usr.UserID)
+	if err != nil {
+		return models.Subscriber{}, userNotFound(err)
+	}
 	return sub, nil
 }
 
-func (f finder) FindIDByEmail(email string, userType models.UserType) (uuid.UUID, error) {
-	switch userType {
-	case models.UserTypAgent:
-		agt, err := f.FindAgentByEmail(email)
-		if err != nil {
-			return uuid.Nil, err
-		}
-		return agt.ID, nil
-	case models.UserTypMerchant:
-		merch, err := f.FindMerchantByEmail(email)
-		if err != nil {
-			return uuid.Nil, err
-		}
-		return merch.ID, nil
-	case models.UserTypSubscriber:
-		sub, err := f.FindSubscriberByEmail(email)
-		if err != nil {
-			return uuid.Nil, err
-		}
-		return sub.ID, nil
+// FindID returns the user id of a customer, userType may be left empty
+// if the customer can be of any type
+func (f finder) FindID(identifier string, userType models.UserType) (uuid.UUID, error) {
+	usr, err := f.FindUser(identifier, userType)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return usr.UserID, nil
+}
+
+// userNotFound gives a not found error a message the customer can understand
+func userNotFound(err error) error {
+	if errors.ErrorCode(err) == errors.ENOTFOUND {
+		return errors.Error{Err: err, Message: errors.ErrUserNotFound}
 	}
-	return uuid.Nil, errors.Error{Code: errors.EINVALID, Message: errors.ErrUserNotFound}
+	return err
 }
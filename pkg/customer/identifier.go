@@ -0,0 +1,72 @@
+package customer
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"strings"
+
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/helpers"
+	"github.com/bhojpur/wallet/pkg/models"
+)
+
+// identifierKind tells what a customer identifier given during a transaction refers to
+type identifierKind int
+
+const (
+	identifierEmail identifierKind = iota
+	identifierPhoneNumber
+	identifierAccountNumber
+	identifierTillNumber
+	identifierAgentNumber
+)
+
+// parseIdentifier works out the kind of identifier a customer provided and returns it
+// in the format it is stored in.
+//
+// Emails are recognised by the '@', phone numbers need to be in E.164 format i.e. have
+// a leading '+', while account, till and agent numbers are told apart by their length
+// and must carry a valid check digit.
+func parseIdentifier(identifier string) (identifierKind, string, error) {
+	identifier = strings.TrimSpace(identifier)
+
+	switch {
+	case strings.Contains(identifier, "@"):
+		return identifierEmail, identifier, nil
+	case strings.HasPrefix(identifier, "+"):
+		phone, err := helpers.NormalizePhoneNumber(identifier)
+		if err != nil {
+			return 0, "", err
+		}
+		return identifierPhoneNumber, phone, nil
+	case models.IsValidNumber(identifier):
+		switch len(identifier) {
+		case models.AccountNumberLength:
+			return identifierAccountNumber, identifier, nil
+		case models.TillNumberLength:
+			return identifierTillNumber, identifier, nil
+		case models.AgentNumberLength:
+			return identifierAgentNumber, identifier, nil
+		}
+	}
+
+	return 0, "", errors.Error{Code: errors.EINVALID, Message: errors.ErrInvalidIdentifier}
+}
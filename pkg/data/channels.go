@@ -21,6 +21,7 @@ package data
 // THE SOFTWARE.
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/bhojpur/wallet/pkg/models"
@@ -28,16 +29,65 @@ import (
 	"github.com/gofrs/uuid"
 )
 
+// drainInterval is how long Drain waits for a pending write to reach the buffer
+const drainInterval = 10 * time.Millisecond
+
+// Chan is a buffered channel that can be written to without waiting for room in
+// its buffer, and drained of what is left in it on shutdown
+type Chan[T any] struct {
+	Channel chan T
+	Reader  <-chan T
+	Writer  chan<- T
+
+	// writes waiting for room in the buffer
+	pending *int64
+}
+
+func newChan[T any](size int) Chan[T] {
+	channel := make(chan T, size)
+	return Chan[T]{Channel: channel, Reader: channel, Writer: channel, pending: new(int64)}
+}
+
+// Send writes to the channel without waiting for room in its buffer
+func (c Chan[T]) Send(value T) {
+	atomic.AddInt64(c.pending, 1)
+	go func() {
+		defer atomic.AddInt64(c.pending, -1)
+		c.Writer <- value
+	}()
+}
+
+// Pending returns how many values were sent and not read yet
+func (c Chan[T]) Pending() int {
+	return len(c.Channel) + int(atomic.LoadInt64(c.pending))
+}
+
+// Drain hands what is left in the channel to handle, until the buffer is empty
+// and no write waits for room in it. Nothing should be sent any more.
+func (c Chan[T]) Drain(handle func(T)) {
+	for {
+		select {
+		case value := <-c.Reader:
+			handle(value)
+		default:
+			if atomic.LoadInt64(c.pending) == 0 && len(c.Channel) == 0 {
+				return
+			}
+			time.Sleep(drainInterval)
+		}
+	}
+}
+
 // CustomerContract describe the characteristics of data that should
 // be passed along in channels for when a user is created or something.
 type CustomerContract struct {
 	UserID uuid.UUID
 }
 
-type ChanNewCustomers struct {
-	Channel chan CustomerContract
-	Reader  <-chan CustomerContract
-	Writer  chan<- CustomerContract
+type ChanNewCustomers = Chan[CustomerContract]
+
+func NewChanNewCustomers(size int) ChanNewCustomers {
+	return newChan[CustomerContract](size)
 }
 
 // TransactionContract represents the type of data
@@ -50,10 +100,10 @@ type TransactionContract struct {
 	Timestamp    time.Time
 }
 
-type ChanNewTransactions struct {
-	Channel chan TransactionContract
-	Reader  <-chan TransactionContract
-	Writer  chan<- TransactionContract
+type ChanNewTransactions = Chan[TransactionContract]
+
+func NewChanNewTransactions(size int) ChanNewTransactions {
+	return newChan[TransactionContract](size)
 }
 
 // type ChanNewTxnEvents struct {
@@ -0,0 +1,56 @@
+package data
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestDrain(t *testing.T) {
+	channel := NewChanNewCustomers(2)
+
+	// more than the buffer holds, the rest waits for room
+	sent := map[uuid.UUID]bool{}
+	for i := 0; i < 10; i++ {
+		customer := CustomerContract{UserID: uuid.Must(uuid.NewV4())}
+		sent[customer.UserID] = true
+		channel.Send(customer)
+	}
+	if pending := channel.Pending(); pending != 10 {
+		t.Errorf("%d pending, want 10", pending)
+	}
+
+	channel.Drain(func(customer CustomerContract) {
+		if !sent[customer.UserID] {
+			t.Errorf("drained %v twice or never sent it", customer.UserID)
+		}
+		delete(sent, customer.UserID)
+	})
+
+	if len(sent) != 0 {
+		t.Errorf("%d customers not drained", len(sent))
+	}
+	if pending := channel.Pending(); pending != 0 {
+		t.Errorf("%d pending after draining, want 0", pending)
+	}
+}
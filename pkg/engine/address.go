@@ -0,0 +1,179 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	// RIPEMD-160 hash of the public key
+	pubKeyHashLen = 20
+	// version, hash of the public key and checksum
+	addressLen = 1 + pubKeyHashLen + addressChecksumLen
+	// no valid address is longer, anything longer is refused before it is decoded
+	maxEncodedAddressLen = 64
+)
+
+var b58Alphabet = []byte("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz")
+
+var (
+	ErrAddressEmpty    = errors.New("address is empty")
+	ErrAddressEncoding = errors.New("address is not base58 encoded")
+	ErrAddressLength   = errors.New("address has the wrong length")
+	ErrAddressVersion  = errors.New("address version is not supported")
+	ErrAddressChecksum = errors.New("address checksum does not match")
+)
+
+// AddressError is returned for an address that can't be parsed, the
+// error it wraps is one of the ErrAddress errors
+type AddressError struct {
+	Address string
+	Err     error
+}
+
+func (e *AddressError) Error() string {
+	return fmt.Sprintf("invalid address %q: %v", e.Address, e.Err)
+}
+
+func (e *AddressError) Unwrap() error {
+	return e.Err
+}
+
+// ParseAddress decodes an address, checks its version and checksum and
+// returns the hash of the public key it was made from. Addresses written by
+// earlier versions, which wrote a single leading 1 for all the leading zero
+// bytes, are parsed too, CanonicalAddress gives the address written now.
+func ParseAddress(address string) ([]byte, error) {
+	fail := func(err error) ([]byte, error) {
+		return nil, &AddressError{Address: address, Err: err}
+	}
+
+	if address == "" {
+		return fail(ErrAddressEmpty)
+	}
+	if len(address) > maxEncodedAddressLen {
+		return fail(ErrAddressLength)
+	}
+
+	payload, err := base58Decode([]byte(address))
+	if err != nil {
+		return fail(err)
+	}
+	if len(payload) < addressLen && isLegacyAddress(address) {
+		// the single 1 stood for all the leading zero bytes, the address was
+		// written by an earlier version if the checksum matches with them
+		padded := append(make([]byte, addressLen-len(payload)), payload...)
+		if bytes.Equal(padded[addressLen-addressChecksumLen:], checksum(padded[:addressLen-addressChecksumLen])) {
+			payload = padded
+		}
+	}
+	if len(payload) != addressLen {
+		return fail(ErrAddressLength)
+	}
+	if payload[0] != version {
+		return fail(ErrAddressVersion)
+	}
+
+	versioned, actual := payload[:addressLen-addressChecksumLen], payload[addressLen-addressChecksumLen:]
+	if !bytes.Equal(actual, checksum(versioned)) {
+		return fail(ErrAddressChecksum)
+	}
+
+	return versioned[1:], nil
+}
+
+// CanonicalAddress returns the address as it is written now, it is the
+// address itself unless an earlier version wrote it
+func CanonicalAddress(address string) (string, error) {
+	pubKeyHash, err := ParseAddress(address)
+	if err != nil {
+		return "", err
+	}
+	return string(encodeAddress(pubKeyHash)), nil
+}
+
+// encodeAddress returns the address of the hash of a public key
+func encodeAddress(pubKeyHash []byte) []byte {
+	versioned := append([]byte{version}, pubKeyHash...)
+	return base58Encode(append(versioned, checksum(versioned)...))
+}
+
+// isLegacyAddress tells whether an address may have been written by an earlier
+// version, which wrote one leading 1 however many zero bytes the payload began with
+func isLegacyAddress(address string) bool {
+	return strings.HasPrefix(address, "1") && !strings.HasPrefix(address, "11")
+}
+
+// base58Encode encodes the input with the Bitcoin alphabet, every leading
+// zero byte is written as a leading 1
+func base58Encode(input []byte) []byte {
+	zeros := 0
+	for zeros < len(input) && input[zeros] == 0 {
+		zeros++
+	}
+
+	x := new(big.Int).SetBytes(input)
+	base := big.NewInt(int64(len(b58Alphabet)))
+	mod := new(big.Int)
+
+	var result []byte
+	for x.Sign() > 0 {
+		x.DivMod(x, base, mod)
+		result = append(result, b58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		result = append(result, b58Alphabet[0])
+	}
+
+	// the digits were written least significant first
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+
+	return result
+}
+
+// base58Decode is the reverse of base58Encode, it fails on characters
+// that are not in the alphabet
+func base58Decode(input []byte) ([]byte, error) {
+	zeros := 0
+	for zeros < len(input) && input[zeros] == b58Alphabet[0] {
+		zeros++
+	}
+
+	x := new(big.Int)
+	base := big.NewInt(int64(len(b58Alphabet)))
+	for _, b := range input[zeros:] {
+		digit := bytes.IndexByte(b58Alphabet, b)
+		if digit < 0 {
+			return nil, ErrAddressEncoding
+		}
+		x.Mul(x, base)
+		x.Add(x, big.NewInt(int64(digit)))
+	}
+
+	return append(make([]byte, zeros), x.Bytes()...), nil
+}
@@ -0,0 +1,184 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestParseAddress(t *testing.T) {
+	wallet := NewWallet()
+	address := string(wallet.GetAddress())
+
+	pubKeyHash, err := ParseAddress(address)
+	if err != nil {
+		t.Fatalf("ParseAddress(%q) failed: %v", address, err)
+	}
+	if !bytes.Equal(pubKeyHash, HashPubKey(wallet.PublicKey)) {
+		t.Errorf("ParseAddress(%q) = %x, want %x", address, pubKeyHash, HashPubKey(wallet.PublicKey))
+	}
+
+	tampered := []byte(address)
+	if tampered[5] == 'z' {
+		tampered[5] = 'y'
+	} else {
+		tampered[5] = 'z'
+	}
+
+	cases := []struct {
+		address string
+		want    error
+	}{
+		{"", ErrAddressEmpty},
+		{"1", ErrAddressLength},
+		{"0OIl", ErrAddressEncoding},
+		{address + address, ErrAddressLength},
+		{string(tampered), ErrAddressChecksum},
+		{string(base58Encode(append([]byte{0x05}, make([]byte, addressLen-1)...))), ErrAddressVersion},
+	}
+	for _, c := range cases {
+		_, err := ParseAddress(c.address)
+		if !errors.Is(err, c.want) {
+			t.Errorf("ParseAddress(%q) = %v, want %v", c.address, err, c.want)
+		}
+		var addrErr *AddressError
+		if !errors.As(err, &addrErr) {
+			t.Errorf("ParseAddress(%q) error is %T, want *AddressError", c.address, err)
+		}
+	}
+}
+
+// a key whose public key hash begins with a zero byte, earlier versions wrote its
+// address with a single leading 1
+const (
+	zeroHashKey           = 215
+	zeroHash              = "00389a9e0a52ec0f96de4c6299aafa03095f230f"
+	zeroHashAddress       = "112AouxYRy1CvxkyCMhhdur6ARTc2Uj73m"
+	zeroHashLegacyAddress = "12AouxYRy1CvxkyCMhhdur6ARTc2Uj73m"
+)
+
+func zeroHashWallet() *Wallet {
+	curve := elliptic.P256()
+	private := ecdsa.PrivateKey{D: big.NewInt(zeroHashKey)}
+	private.PublicKey.Curve = curve
+	private.PublicKey.X, private.PublicKey.Y = curve.ScalarBaseMult(private.D.Bytes())
+	return &Wallet{PrivateKey: private, PublicKey: publicKeyBytes(&private.PublicKey)}
+}
+
+func TestLegacyAddress(t *testing.T) {
+	wallet := zeroHashWallet()
+	if got := string(wallet.GetAddress()); got != zeroHashAddress {
+		t.Fatalf("GetAddress() = %s, want %s", got, zeroHashAddress)
+	}
+
+	for _, address := range []string{zeroHashAddress, zeroHashLegacyAddress} {
+		pubKeyHash, err := ParseAddress(address)
+		if err != nil {
+			t.Fatalf("ParseAddress(%q) failed: %v", address, err)
+		}
+		if got := hex.EncodeToString(pubKeyHash); got != zeroHash {
+			t.Errorf("ParseAddress(%q) = %s, want %s", address, got, zeroHash)
+		}
+
+		canonical, err := CanonicalAddress(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if canonical != zeroHashAddress {
+			t.Errorf("CanonicalAddress(%q) = %s, want %s", address, canonical, zeroHashAddress)
+		}
+	}
+
+	wallets := Wallets{Wallets: map[string]*Wallet{}}
+	wallets.ImportWallet(wallet)
+	if _, err := wallets.GetWallet(zeroHashLegacyAddress); err != nil {
+		t.Errorf("GetWallet(%q) failed: %v", zeroHashLegacyAddress, err)
+	}
+
+	// a 1 too few is not a legacy address
+	if _, err := ParseAddress(zeroHashLegacyAddress[1:]); !errors.Is(err, ErrAddressLength) {
+		t.Errorf("ParseAddress(%q) = %v, want %v", zeroHashLegacyAddress[1:], err, ErrAddressLength)
+	}
+}
+
+// FuzzParseAddress checks that any input is either refused with an error or is an
+// address that encodes back to itself, or to the address an earlier version wrote
+func FuzzParseAddress(f *testing.F) {
+	f.Add(string(NewWallet().GetAddress()))
+	f.Add("")
+	f.Add("1")
+	f.Add("11111111111111111111111111")
+	f.Add("1BoatSLRHtKNngkdXEeobR76b53LETtpyT")
+	f.Add(zeroHashLegacyAddress)
+
+	f.Fuzz(func(t *testing.T, address string) {
+		pubKeyHash, err := ParseAddress(address)
+		if err != nil {
+			var addrErr *AddressError
+			if !errors.As(err, &addrErr) {
+				t.Fatalf("ParseAddress(%q) error is %T, want *AddressError", address, err)
+			}
+			return
+		}
+
+		encoded := string(encodeAddress(pubKeyHash))
+		legacy := "1" + strings.TrimLeft(encoded, "1")
+		if encoded != address && legacy != address {
+			t.Fatalf("ParseAddress(%q) = %x, which encodes to %q", address, pubKeyHash, encoded)
+		}
+	})
+}
+
+// FuzzAddressChecksum checks that every public key hash makes an address that parses
+// back to it, and that changing a byte of the checksum is caught
+func FuzzAddressChecksum(f *testing.F) {
+	f.Add(make([]byte, pubKeyHashLen), byte(0))
+	f.Add(bytes.Repeat([]byte{0xff}, pubKeyHashLen), byte(3))
+
+	f.Fuzz(func(t *testing.T, pubKeyHash []byte, at byte) {
+		if len(pubKeyHash) != pubKeyHashLen {
+			return
+		}
+
+		versioned := append([]byte{version}, pubKeyHash...)
+		payload := append(versioned, checksum(versioned)...)
+
+		got, err := ParseAddress(string(base58Encode(payload)))
+		if err != nil {
+			t.Fatalf("address of %x doesn't parse: %v", pubKeyHash, err)
+		}
+		if !bytes.Equal(got, pubKeyHash) {
+			t.Fatalf("address of %x parses to %x", pubKeyHash, got)
+		}
+
+		payload[addressLen-addressChecksumLen+int(at)%addressChecksumLen] ^= 0x01
+		if _, err := ParseAddress(string(base58Encode(payload))); !errors.Is(err, ErrAddressChecksum) {
+			t.Fatalf("address of %x with a wrong checksum gives %v", pubKeyHash, err)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// HardenedOffset is added to the index of a hardened child key
+	HardenedOffset = uint32(0x80000000)
+
+	// DefaultBasePath is the BIP44 style path under which the addresses of a node are
+	// derived, the index of the address is appended to it
+	DefaultBasePath = "m/44'/0'/0'/0"
+
+	// SLIP-10 derives P-256 master keys with this HMAC key
+	masterKeySalt = "Nist256p1 seed"
+)
+
+var ErrInvalidPath = errors.New("derivation path is not valid")
+
+// ExtendedKey is a P-256 private key together with the chain code its children are
+// derived with. Keys are derived as set out by SLIP-10, which carries BIP32
+// derivation over to the NIST P-256 curve.
+type ExtendedKey struct {
+	Key       []byte
+	ChainCode []byte
+}
+
+// NewMasterKey derives the master key of the tree from a seed, e.g. a BIP39 seed
+func NewMasterKey(seed []byte) ExtendedKey {
+	mac := hmac.New(sha512.New, []byte(masterKeySalt))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	// a key out of range is very unlikely, SLIP-10 then hashes again
+	for !validScalar(sum[:32]) {
+		mac = hmac.New(sha512.New, []byte(masterKeySalt))
+		mac.Write(sum)
+		sum = mac.Sum(nil)
+	}
+
+	return ExtendedKey{Key: sum[:32], ChainCode: sum[32:]}
+}
+
+// Child derives the child key at the index, which is hardened from HardenedOffset on
+func (k ExtendedKey) Child(index uint32) ExtendedKey {
+	curve := elliptic.P256()
+	n := curve.Params().N
+
+	var data []byte
+	if index >= HardenedOffset {
+		data = append([]byte{0x00}, k.Key...)
+	} else {
+		x, y := curve.ScalarBaseMult(k.Key)
+		data = elliptic.MarshalCompressed(curve, x, y)
+	}
+
+	for {
+		mac := hmac.New(sha512.New, k.ChainCode)
+		mac.Write(data)
+		mac.Write(ser32(index))
+		sum := mac.Sum(nil)
+
+		child := new(big.Int).SetBytes(sum[:32])
+		if child.Cmp(n) < 0 {
+			child.Add(child, new(big.Int).SetBytes(k.Key))
+			child.Mod(child, n)
+			if child.Sign() != 0 {
+				key := make([]byte, 32)
+				child.FillBytes(key)
+				return ExtendedKey{Key: key, ChainCode: sum[32:]}
+			}
+		}
+
+		// the key is out of range, SLIP-10 derives again from the right half
+		data = append([]byte{0x01}, sum[32:]...)
+	}
+}
+
+// Derive derives the key at a path such as m/44'/0'/0'/0/1, hardened
+// indexes are marked with an apostrophe, an h or an H
+func (k ExtendedKey) Derive(path string) (ExtendedKey, error) {
+	indexes, err := ParsePath(path)
+	if err != nil {
+		return ExtendedKey{}, err
+	}
+
+	for _, index := range indexes {
+		k = k.Child(index)
+	}
+
+	return k, nil
+}
+
+// PrivateKey returns the ECDSA key
+func (k ExtendedKey) PrivateKey() *ecdsa.PrivateKey {
+	curve := elliptic.P256()
+	private := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(k.Key)}
+	private.PublicKey.Curve = curve
+	private.PublicKey.X, private.PublicKey.Y = curve.ScalarBaseMult(k.Key)
+
+	return private
+}
+
+// ParsePath parses a derivation path into the indexes of the keys along it
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, ErrInvalidPath
+	}
+
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		hardened := strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h") || strings.HasSuffix(part, "H")
+		if hardened {
+			part = part[:len(part)-1]
+		}
+
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || uint32(index) >= HardenedOffset {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPath, path)
+		}
+		if hardened {
+			index += uint64(HardenedOffset)
+		}
+		indexes = append(indexes, uint32(index))
+	}
+
+	return indexes, nil
+}
+
+// AddressPath returns the path of the address at the index under the base path
+func AddressPath(basePath string, index uint32) string {
+	return fmt.Sprintf("%s/%d", basePath, index)
+}
+
+func validScalar(key []byte) bool {
+	k := new(big.Int).SetBytes(key)
+	return k.Sign() != 0 && k.Cmp(elliptic.P256().Params().N) < 0
+}
+
+func ser32(i uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, i)
+	return b
+}
@@ -0,0 +1,175 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"crypto/elliptic"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// TestDeriveSLIP10 checks the keys of test vector 1 for nist256p1 of SLIP-10
+func TestDeriveSLIP10(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+
+	vectors := []struct {
+		path      string
+		chainCode string
+		private   string
+		public    string
+	}{
+		{
+			"m",
+			"beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea",
+			"612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2",
+			"0266874dc6ade47b3ecd096745ca09bcd29638dd52c2c12117b11ed3e458cfa9e8",
+		},
+		{
+			"m/0H",
+			"3460cea53e6a6bb5fb391eeef3237ffd8724bf0a40e94943c98b83825342ee11",
+			"6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c",
+			"0384610f5ecffe8fda089363a41f56a5c7ffc1d81b59a612d0d649b2d22355590c",
+		},
+		{
+			"m/0H/1",
+			"4187afff1aafa8445010097fb99d23aee9f599450c7bd140b6826ac22ba21d0c",
+			"284e9d38d07d21e4e281b645089a94f4cf5a5a81369acf151a1c3a57f18b2129",
+			"03526c63f8d0b4bbbf9c80df553fe66742df4676b241dabefdef67733e070f6844",
+		},
+		{
+			"m/0H/1/2H",
+			"98c7514f562e64e74170cc3cf304ee1ce54d6b6da4f880f313e8204c2a185318",
+			"694596e8a54f252c960eb771a3c41e7e32496d03b954aeb90f61635b8e092aa7",
+			"0359cf160040778a4b14c5f4d7b76e327ccc8c4a6086dd9451b7482b5a4972dda0",
+		},
+		{
+			"m/0H/1/2H/2",
+			"ba96f776a5c3907d7fd48bde5620ee374d4acfd540378476019eab70790c63a0",
+			"5996c37fd3dd2679039b23ed6f70b506c6b56b3cb5e424681fb0fa64caf82aaa",
+			"029f871f4cb9e1c97f9f4de9ccd0d4a2f2a171110c61178f84430062230833ff20",
+		},
+		{
+			"m/0H/1/2H/2/1000000000",
+			"b9b7b82d326bb9cb5b5b121066feea4eb93d5241103c9e7a18aad40f1dde8059",
+			"21c4f269ef0a5fd1badf47eeacebeeaa3de22eb8e5b0adcd0f27dd99d34d0119",
+			"02216cd26d31147f72427a453c443ed2cde8a1e53c9cc44e5ddf739725413fe3f4",
+		},
+	}
+
+	master := NewMasterKey(seed)
+	for _, v := range vectors {
+		key, err := master.Derive(v.path)
+		if err != nil {
+			t.Fatalf("Derive(%q) failed: %v", v.path, err)
+		}
+
+		private := key.PrivateKey()
+		public := elliptic.MarshalCompressed(private.Curve, private.X, private.Y)
+		if got := hex.EncodeToString(key.ChainCode); got != v.chainCode {
+			t.Errorf("%s chain code = %s, want %s", v.path, got, v.chainCode)
+		}
+		if got := hex.EncodeToString(key.Key); got != v.private {
+			t.Errorf("%s private key = %s, want %s", v.path, got, v.private)
+		}
+		if got := hex.EncodeToString(public); got != v.public {
+			t.Errorf("%s public key = %s, want %s", v.path, got, v.public)
+		}
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	for _, path := range []string{"", "44'/0'", "m/x", "m/2147483648", "m/0'/-1"} {
+		if _, err := ParsePath(path); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("ParsePath(%q) = %v, want %v", path, err, ErrInvalidPath)
+		}
+	}
+
+	indexes, err := ParsePath("m/44'/1h/2H/3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint32{44 + HardenedOffset, 1 + HardenedOffset, 2 + HardenedOffset, 3}
+	for i := range want {
+		if indexes[i] != want[i] {
+			t.Errorf("ParsePath index %d = %d, want %d", i, indexes[i], want[i])
+		}
+	}
+}
+
+// TestRestore checks that the addresses of a mnemonic are regenerated with their keys
+func TestRestore(t *testing.T) {
+	mnemonic, err := NewMnemonic()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := Wallets{Wallets: map[string]*Wallet{}}
+	if err := created.SetMnemonic(mnemonic, "password"); err != nil {
+		t.Fatal(err)
+	}
+	var addresses []string
+	for i := 0; i < 3; i++ {
+		address, err := created.CreateWallet()
+		if err != nil {
+			t.Fatal(err)
+		}
+		addresses = append(addresses, address)
+	}
+
+	restored := Wallets{Wallets: map[string]*Wallet{}}
+	if err := restored.Restore(mnemonic, "password", uint32(len(addresses))); err != nil {
+		t.Fatal(err)
+	}
+	for i, address := range addresses {
+		wallet, err := restored.GetWallet(address)
+		if err != nil {
+			t.Fatalf("address %d was not restored: %v", i, err)
+		}
+		if wallet.PrivateKey.D.Cmp(created.Wallets[address].PrivateKey.D) != 0 {
+			t.Errorf("address %d was restored with another key", i)
+		}
+		if wallet.Path != AddressPath(DefaultBasePath, uint32(i)) {
+			t.Errorf("address %d was restored at %s", i, wallet.Path)
+		}
+	}
+
+	// the next address follows the restored ones
+	next, err := restored.CreateWallet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := created.deriveWallet(uint32(len(addresses))); next != want {
+		t.Errorf("CreateWallet() after Restore = %s, want %s", next, want)
+	}
+
+	// another password is another wallet
+	other := Wallets{Wallets: map[string]*Wallet{}}
+	if err := other.Restore(mnemonic, "other", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.GetWallet(addresses[0]); !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("GetWallet() with another password = %v, want %v", err, ErrWalletNotFound)
+	}
+	if err := restored.Restore(mnemonic, "other", 1); !errors.Is(err, ErrSeedExists) {
+		t.Errorf("Restore() of another seed = %v, want %v", err, ErrSeedExists)
+	}
+}
@@ -0,0 +1,135 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"math/big"
+)
+
+const (
+	pemPublicKey  = "PUBLIC KEY"
+	pemPrivateKey = "EC PRIVATE KEY"
+)
+
+var (
+	ErrInvalidPublicKey  = errors.New("public key is not a P-256 key in a known encoding")
+	ErrInvalidPrivateKey = errors.New("private key is not a PEM encoded P-256 EC private key")
+)
+
+// NewWalletFromKey returns the Wallet of an existing private key, e.g. an imported one
+func NewWalletFromKey(private *ecdsa.PrivateKey) (*Wallet, error) {
+	if private.Curve != elliptic.P256() {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	return &Wallet{PrivateKey: *private, PublicKey: publicKeyBytes(&private.PublicKey)}, nil
+}
+
+// PublicKeySEC1 returns the public key as a SEC1 point, compressed or uncompressed
+func (w Wallet) PublicKeySEC1(compressed bool) []byte {
+	pub := w.PrivateKey.PublicKey
+	if compressed {
+		return elliptic.MarshalCompressed(pub.Curve, pub.X, pub.Y)
+	}
+	return elliptic.Marshal(pub.Curve, pub.X, pub.Y)
+}
+
+// PublicKeyPEM returns the public key as a PEM encoded PKIX public key
+func (w Wallet) PublicKeyPEM() ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(&w.PrivateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemPublicKey, Bytes: der}), nil
+}
+
+// PrivateKeyPEM returns the private key as a PEM encoded SEC1 EC private key,
+// the form the engine key is kept in
+func (w Wallet) PrivateKeyPEM() ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(&w.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemPrivateKey, Bytes: der}), nil
+}
+
+// ParsePrivateKeyPEM parses a PEM encoded SEC1 EC private key on the P-256 curve
+func ParsePrivateKeyPEM(content []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil || block.Type != pemPrivateKey {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	private, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil || private.Curve != elliptic.P256() {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	return private, nil
+}
+
+// ParsePublicKey parses a P-256 public key given as a PEM encoded PKIX public key, a
+// compressed or uncompressed SEC1 point, or the X and Y coordinates one after the other
+func ParsePublicKey(data []byte) (*ecdsa.PublicKey, error) {
+	curve := elliptic.P256()
+
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != pemPublicKey {
+			return nil, ErrInvalidPublicKey
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, ErrInvalidPublicKey
+		}
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != curve {
+			return nil, ErrInvalidPublicKey
+		}
+		return pub, nil
+	}
+
+	var x, y *big.Int
+	switch len(data) {
+	case 1 + 2*coordinateLen:
+		x, y = elliptic.Unmarshal(curve, data)
+	case 1 + coordinateLen:
+		x, y = elliptic.UnmarshalCompressed(curve, data)
+	case 2 * coordinateLen:
+		x = new(big.Int).SetBytes(data[:coordinateLen])
+		y = new(big.Int).SetBytes(data[coordinateLen:])
+		if !curve.IsOnCurve(x, y) {
+			x = nil
+		}
+	}
+	if x == nil {
+		return nil, ErrInvalidPublicKey
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
@@ -0,0 +1,241 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keystoreVersion = 1
+	keystoreKDF     = "scrypt"
+	keystoreCipher  = "aes-256-gcm"
+
+	// scrypt cost parameters of new keystores, the ones a keystore was
+	// written with are kept in it so they can be raised later on
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 32
+)
+
+var (
+	ErrWrongPassphrase  = errors.New("keystore passphrase is not correct")
+	ErrEmptyPassphrase  = errors.New("keystore passphrase should not be empty")
+	ErrUnknownKeystore  = errors.New("keystore format is not supported")
+	ErrCorruptedWallets = errors.New("keystore holds a key that can't be read")
+)
+
+// keystore is the file the private keys of the wallets are kept in,
+// encrypted with a key derived from a passphrase
+type keystore struct {
+	Version    int          `json:"version"`
+	KDF        string       `json:"kdf"`
+	KDFParams  scryptParams `json:"kdfparams"`
+	Cipher     string       `json:"cipher"`
+	Nonce      []byte       `json:"nonce"`
+	Ciphertext []byte       `json:"ciphertext"`
+}
+
+type scryptParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+// keyring is the part of the keystore that is encrypted
+type keyring struct {
+	// BIP39 seed addresses are derived from and the index of the next one
+	Seed      []byte              `json:"seed,omitempty"`
+	NextIndex uint32              `json:"nextIndex"`
+	Wallets   map[string]keyEntry `json:"wallets"`
+}
+
+// keyEntry is a wallet as it is kept in the keyring. The public key is
+// kept as it was, the address of the wallet is derived from it.
+type keyEntry struct {
+	PrivateKey []byte `json:"privateKey"`
+	PublicKey  []byte `json:"publicKey"`
+	Path       string `json:"path,omitempty"`
+}
+
+func newKeyring(ws Wallets) (keyring, error) {
+	ring := keyring{
+		Seed:      ws.seed,
+		NextIndex: ws.nextIndex,
+		Wallets:   make(map[string]keyEntry, len(ws.Wallets)),
+	}
+
+	for address, wallet := range ws.Wallets {
+		der, err := x509.MarshalECPrivateKey(&wallet.PrivateKey)
+		if err != nil {
+			return keyring{}, err
+		}
+		ring.Wallets[address] = keyEntry{PrivateKey: der, PublicKey: wallet.PublicKey, Path: wallet.Path}
+	}
+
+	return ring, nil
+}
+
+func (ring keyring) wallets() (map[string]*Wallet, error) {
+	wallets := make(map[string]*Wallet, len(ring.Wallets))
+	for address, entry := range ring.Wallets {
+		private, err := x509.ParseECPrivateKey(entry.PrivateKey)
+		if err != nil {
+			return nil, ErrCorruptedWallets
+		}
+		wallets[address] = &Wallet{PrivateKey: *private, PublicKey: entry.PublicKey, Path: entry.Path}
+	}
+
+	return wallets, nil
+}
+
+// sealKeyring encrypts the keyring with the passphrase
+func sealKeyring(ring keyring, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyPassphrase
+	}
+
+	plaintext, err := json.Marshal(ring)
+	if err != nil {
+		return nil, err
+	}
+
+	ks := keystore{
+		Version:   keystoreVersion,
+		KDF:       keystoreKDF,
+		KDFParams: scryptParams{N: scryptN, R: scryptR, P: scryptP, Salt: make([]byte, saltLen)},
+		Cipher:    keystoreCipher,
+	}
+	if _, err := io.ReadFull(rand.Reader, ks.KDFParams.Salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := ks.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	ks.Nonce = make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, ks.Nonce); err != nil {
+		return nil, err
+	}
+	ks.Ciphertext = aead.Seal(nil, ks.Nonce, plaintext, ks.additionalData())
+
+	return json.MarshalIndent(ks, "", "  ")
+}
+
+// openKeyring decrypts a keystore written by sealKeyring
+func openKeyring(content, passphrase []byte) (keyring, error) {
+	var ks keystore
+	if err := json.Unmarshal(content, &ks); err != nil {
+		return keyring{}, ErrUnknownKeystore
+	}
+	if ks.Version != keystoreVersion || ks.KDF != keystoreKDF || ks.Cipher != keystoreCipher {
+		return keyring{}, ErrUnknownKeystore
+	}
+
+	aead, err := ks.aead(passphrase)
+	if err != nil {
+		return keyring{}, err
+	}
+	if len(ks.Nonce) != aead.NonceSize() {
+		return keyring{}, ErrUnknownKeystore
+	}
+
+	plaintext, err := aead.Open(nil, ks.Nonce, ks.Ciphertext, ks.additionalData())
+	if err != nil {
+		return keyring{}, ErrWrongPassphrase
+	}
+
+	var ring keyring
+	if err := json.Unmarshal(plaintext, &ring); err != nil {
+		return keyring{}, ErrCorruptedWallets
+	}
+
+	return ring, nil
+}
+
+// aead derives the encryption key from the passphrase
+func (ks keystore) aead(passphrase []byte) (cipher.AEAD, error) {
+	params := ks.KDFParams
+	key, err := scrypt.Key(passphrase, params.Salt, params.N, params.R, params.P, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("cannot derive keystore key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// additionalData binds the ciphertext to the parameters it was encrypted with,
+// so that they can't be changed without decryption failing
+func (ks keystore) additionalData() []byte {
+	params := ks.KDFParams
+	return []byte(fmt.Sprintf("%d|%s|%s|%d|%d|%d|%x", ks.Version, ks.KDF, ks.Cipher, params.N, params.R, params.P, params.Salt))
+}
+
+// writeFileAtomic writes the content to a temporary file, readable by the owner only,
+// next to path and renames it over path. A reader sees either the old or the new file.
+func writeFileAtomic(path string, content []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// does nothing once the file has been renamed
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
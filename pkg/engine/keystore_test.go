@@ -0,0 +1,200 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+var passphrase = []byte("correct horse battery staple")
+
+func TestSealOpenKeyring(t *testing.T) {
+	wallet := NewWallet()
+	address := string(wallet.GetAddress())
+	ring, err := newKeyring(Wallets{
+		Wallets:   map[string]*Wallet{address: wallet},
+		seed:      []byte("seed"),
+		nextIndex: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := sealKeyring(ring, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, ring.Wallets[address].PrivateKey) {
+		t.Fatal("the keystore holds the private key in the clear")
+	}
+
+	opened, err := openKeyring(sealed, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(opened.Seed) != "seed" || opened.NextIndex != 3 {
+		t.Errorf("opened seed %q and next index %d, want %q and 3", opened.Seed, opened.NextIndex, "seed")
+	}
+	wallets, err := opened.wallets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := wallets[address]; !ok || got.PrivateKey.D.Cmp(wallet.PrivateKey.D) != 0 {
+		t.Errorf("wallet %s was not opened with its key", address)
+	}
+
+	if _, err := sealKeyring(ring, nil); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("sealKeyring() with no passphrase = %v, want %v", err, ErrEmptyPassphrase)
+	}
+	if _, err := openKeyring(sealed, []byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("openKeyring() with a wrong passphrase = %v, want %v", err, ErrWrongPassphrase)
+	}
+
+	cases := []struct {
+		name   string
+		tamper func(ks *keystore)
+		want   error
+	}{
+		{"cost", func(ks *keystore) { ks.KDFParams.N /= 2 }, ErrWrongPassphrase},
+		{"salt", func(ks *keystore) { ks.KDFParams.Salt[0] ^= 0x01 }, ErrWrongPassphrase},
+		{"nonce", func(ks *keystore) { ks.Nonce[0] ^= 0x01 }, ErrWrongPassphrase},
+		{"ciphertext", func(ks *keystore) { ks.Ciphertext[0] ^= 0x01 }, ErrWrongPassphrase},
+		{"version", func(ks *keystore) { ks.Version++ }, ErrUnknownKeystore},
+		{"cipher", func(ks *keystore) { ks.Cipher = "aes-128-cbc" }, ErrUnknownKeystore},
+	}
+	for _, c := range cases {
+		var ks keystore
+		if err := json.Unmarshal(sealed, &ks); err != nil {
+			t.Fatal(err)
+		}
+		c.tamper(&ks)
+		tampered, err := json.Marshal(ks)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := openKeyring(tampered, passphrase); !errors.Is(err, c.want) {
+			t.Errorf("openKeyring() with tampered %s = %v, want %v", c.name, err, c.want)
+		}
+	}
+}
+
+// baselineWallets is how earlier versions wrote the wallets to the gob file
+type baselineWallets struct {
+	Wallets map[string]*baselineWallet
+}
+
+type baselineWallet struct {
+	PrivateKey ecdsa.PrivateKey
+	PublicKey  []byte
+}
+
+// p256Curve stands for the curve of the Go releases the gob files were written
+// with, it was encoded as the parameters of the curve
+type p256Curve struct {
+	*elliptic.CurveParams
+}
+
+func writeBaselineFile(t *testing.T, path string, wallets ...*Wallet) {
+	gob.RegisterName("crypto/elliptic.p256Curve", p256Curve{})
+
+	baseline := baselineWallets{Wallets: map[string]*baselineWallet{}}
+	for _, wallet := range wallets {
+		private := wallet.PrivateKey
+		private.Curve = p256Curve{elliptic.P256().Params()}
+		// the single leading 1 of the base58 encoding of earlier versions
+		address := "1" + string(bytes.TrimLeft(wallet.GetAddress(), "1"))
+		baseline.Wallets[address] = &baselineWallet{PrivateKey: private, PublicKey: wallet.PublicKey}
+	}
+
+	var content bytes.Buffer
+	if err := gob.NewEncoder(&content).Encode(baseline); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, content.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateLegacyFile(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	const nodeID = "3000"
+	wallet, zeroHash := NewWallet(), zeroHashWallet()
+	writeBaselineFile(t, fmt.Sprintf(legacyWalletFile, nodeID), wallet, zeroHash)
+
+	migrated, err := NewWallets(nodeID, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range []*Wallet{wallet, zeroHash} {
+		address := string(w.GetAddress())
+		got, err := migrated.GetWallet(address)
+		if err != nil {
+			t.Fatalf("wallet %s was not migrated: %v", address, err)
+		}
+		if got.PrivateKey.D.Cmp(w.PrivateKey.D) != 0 || !bytes.Equal(got.PublicKey, w.PublicKey) {
+			t.Errorf("wallet %s was migrated with another key", address)
+		}
+	}
+	if _, ok := migrated.Wallets[zeroHashAddress]; !ok {
+		t.Errorf("the wallet of a legacy address is not kept at %s", zeroHashAddress)
+	}
+
+	if _, err := os.Stat(fmt.Sprintf(legacyWalletFile, nodeID)); !os.IsNotExist(err) {
+		t.Errorf("the gob file is still there: %v", err)
+	}
+	info, err := os.Stat(fmt.Sprintf(keystoreFile, nodeID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("keystore mode = %v, want 0600", mode)
+	}
+
+	// the keystore is read on the next start
+	reopened, err := NewWallets(nodeID, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reopened.Wallets) != 2 {
+		t.Errorf("reopened %d wallets, want 2", len(reopened.Wallets))
+	}
+	if _, err := NewWallets(nodeID, []byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("NewWallets() with a wrong passphrase = %v, want %v", err, ErrWrongPassphrase)
+	}
+}
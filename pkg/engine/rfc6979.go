@@ -0,0 +1,110 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/asn1"
+	"math/big"
+)
+
+// signRFC6979 signs the digest with a nonce derived from the private key and the digest
+// as set out by RFC 6979, with HMAC-SHA256. The same digest always gives the same
+// signature, so signing doesn't depend on a good source of randomness.
+func signRFC6979(private *ecdsa.PrivateKey, digest []byte) ([]byte, error) {
+	curve := private.Curve
+	n := curve.Params().N
+	qlen := n.BitLen()
+	rolen := (qlen + 7) / 8
+
+	e := bits2int(digest, qlen)
+	x := int2octets(private.D, rolen)
+	h1 := int2octets(new(big.Int).Mod(e, n), rolen)
+
+	v := bytesOf(0x01, sha256.Size)
+	k := bytesOf(0x00, sha256.Size)
+
+	k = hmacSHA256(k, v, []byte{0x00}, x, h1)
+	v = hmacSHA256(k, v)
+	k = hmacSHA256(k, v, []byte{0x01}, x, h1)
+	v = hmacSHA256(k, v)
+
+	for {
+		var t []byte
+		for len(t) < rolen {
+			v = hmacSHA256(k, v)
+			t = append(t, v...)
+		}
+
+		nonce := bits2int(t, qlen)
+		if nonce.Sign() > 0 && nonce.Cmp(n) < 0 {
+			rx, _ := curve.ScalarBaseMult(int2octets(nonce, rolen))
+			r := new(big.Int).Mod(rx, n)
+
+			if r.Sign() != 0 {
+				// s = nonce^-1 (e + r d) mod n
+				s := new(big.Int).Mul(r, private.D)
+				s.Add(s, e)
+				s.Mul(s, new(big.Int).ModInverse(nonce, n))
+				s.Mod(s, n)
+
+				if s.Sign() != 0 {
+					return asn1.Marshal(struct{ R, S *big.Int }{r, s})
+				}
+			}
+		}
+
+		k = hmacSHA256(k, v, []byte{0x00})
+		v = hmacSHA256(k, v)
+	}
+}
+
+// bits2int takes the leftmost qlen bits of the input as an integer
+func bits2int(input []byte, qlen int) *big.Int {
+	x := new(big.Int).SetBytes(input)
+	if excess := len(input)*8 - qlen; excess > 0 {
+		x.Rsh(x, uint(excess))
+	}
+	return x
+}
+
+// int2octets writes the integer big endian in rolen bytes
+func int2octets(x *big.Int, rolen int) []byte {
+	return x.FillBytes(make([]byte, rolen))
+}
+
+func hmacSHA256(key []byte, parts ...[]byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	for _, part := range parts {
+		mac.Write(part)
+	}
+	return mac.Sum(nil)
+}
+
+func bytesOf(b byte, n int) []byte {
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = b
+	}
+	return out
+}
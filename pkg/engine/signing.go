@@ -0,0 +1,114 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// coordinateLen is the length of a P-256 coordinate in bytes
+const coordinateLen = 32
+
+// Sha256 returns the SHA-256 hash of the parts written one after the other
+func Sha256(parts ...[]byte) []byte {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+// LoadSigningKey reads the wallet the server signs with from a PEM file holding an EC
+// private key. The key is generated and saved, readable by the owner only, the first time.
+func LoadSigningKey(path string) (*Wallet, error) {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return createSigningKey(path)
+	} else if err != nil {
+		return nil, err
+	}
+
+	private, err := ParsePrivateKeyPEM(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return NewWalletFromKey(private)
+}
+
+func createSigningKey(path string) (*Wallet, error) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := NewWalletFromKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := wallet.PrivateKeyPEM()
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// publicKeyBytes returns the X and Y coordinates of the key one after
+// the other, each padded to the length of a coordinate
+func publicKeyBytes(pub *ecdsa.PublicKey) []byte {
+	key := make([]byte, 2*coordinateLen)
+	pub.X.FillBytes(key[:coordinateLen])
+	pub.Y.FillBytes(key[coordinateLen:])
+	return key
+}
+
+// Sign signs a digest with the private key of the wallet, the signature is ASN.1 DER
+// encoded. Signing is deterministic (RFC 6979), a digest always gets the same signature.
+func (w Wallet) Sign(digest []byte) ([]byte, error) {
+	return signRFC6979(&w.PrivateKey, digest)
+}
+
+// Verify reports whether sig is a signature of digest by the wallet
+func (w Wallet) Verify(digest, sig []byte) bool {
+	return ecdsa.VerifyASN1(&w.PrivateKey.PublicKey, digest, sig)
+}
+
+// Verify reports whether sig is a signature of digest by the public key, given as the
+// X and Y coordinates like the public key of a Wallet or in any encoding ParsePublicKey reads
+func Verify(pubKey, digest, sig []byte) (bool, error) {
+	pub, err := ParsePublicKey(pubKey)
+	if err != nil {
+		return false, err
+	}
+
+	return ecdsa.VerifyASN1(pub, digest, sig), nil
+}
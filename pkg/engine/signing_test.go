@@ -0,0 +1,98 @@
+package engine
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+// the P-256, SHA-256 sample of RFC 6979, appendix A.2.5
+func TestSignRFC6979(t *testing.T) {
+	curve := elliptic.P256()
+	d, _ := new(big.Int).SetString("C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721", 16)
+	private := &ecdsa.PrivateKey{D: d, PublicKey: ecdsa.PublicKey{Curve: curve}}
+	private.X, private.Y = curve.ScalarBaseMult(d.Bytes())
+
+	wallet, err := NewWalletFromKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := wallet.Sign(Sha256([]byte("sample")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rs struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(sig, &rs); err != nil {
+		t.Fatal(err)
+	}
+	wantR := "efd48b2aacb6a8fd1140dd9cd45e81d69d2c877b56aaf991c34d0ea84eaf3716"
+	wantS := "f7cb1c942d657c41d436c7a1b6e29f65f3e900dbb9aff4064dc4ab2f843acda8"
+	if r := hex.EncodeToString(rs.R.Bytes()); r != wantR {
+		t.Errorf("r = %s, want %s", r, wantR)
+	}
+	if s := hex.EncodeToString(rs.S.Bytes()); s != wantS {
+		t.Errorf("s = %s, want %s", s, wantS)
+	}
+
+	again, _ := wallet.Sign(Sha256([]byte("sample")))
+	if !bytes.Equal(sig, again) {
+		t.Error("signing the same digest twice gave different signatures")
+	}
+	if !wallet.Verify(Sha256([]byte("sample")), sig) {
+		t.Error("wallet doesn't verify its own signature")
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	wallet := NewWallet()
+	digest := Sha256([]byte("message"))
+	sig, _ := wallet.Sign(digest)
+
+	pemKey, err := wallet.PublicKeyPEM()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encodings := map[string][]byte{
+		"raw":          wallet.PublicKey,
+		"uncompressed": wallet.PublicKeySEC1(false),
+		"compressed":   wallet.PublicKeySEC1(true),
+		"pem":          pemKey,
+	}
+	for name, key := range encodings {
+		ok, err := Verify(key, digest, sig)
+		if err != nil || !ok {
+			t.Errorf("Verify with %s public key = %v, %v", name, ok, err)
+		}
+	}
+
+	if _, err := ParsePublicKey([]byte("not a key")); err != ErrInvalidPublicKey {
+		t.Errorf("ParsePublicKey of garbage = %v, want %v", err, ErrInvalidPublicKey)
+	}
+}
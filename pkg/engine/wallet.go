@@ -21,14 +21,12 @@ package engine
 // THE SOFTWARE.
 
 import (
-	"bytes"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/sha256"
 	"log"
 
-	utils "github.com/bhojpur/crypto/pkg/utils"
 	"golang.org/x/crypto/ripemd160"
 )
 
@@ -39,27 +37,21 @@ const addressChecksumLen = 4
 type Wallet struct {
 	PrivateKey ecdsa.PrivateKey
 	PublicKey  []byte
+	// derivation path of the key, empty for keys that were not derived
+	Path string
 }
 
 // NewWallet creates and returns a Wallet
 func NewWallet() *Wallet {
 	private, public := newKeyPair()
-	wallet := Wallet{private, public}
+	wallet := Wallet{PrivateKey: private, PublicKey: public}
 
 	return &wallet
 }
 
 // GetAddress returns wallet address
 func (w Wallet) GetAddress() []byte {
-	pubKeyHash := HashPubKey(w.PublicKey)
-
-	versionedPayload := append([]byte{version}, pubKeyHash...)
-	checksum := checksum(versionedPayload)
-
-	fullPayload := append(versionedPayload, checksum...)
-	address := utils.Base58Encode(fullPayload)
-
-	return address
+	return encodeAddress(HashPubKey(w.PublicKey))
 }
 
 // HashPubKey hashes public key
@@ -78,13 +70,8 @@ func HashPubKey(pubKey []byte) []byte {
 
 // ValidateAddress check if address if valid
 func ValidateAddress(address string) bool {
-	pubKeyHash := utils.Base58Decode([]byte(address))
-	actualChecksum := pubKeyHash[len(pubKeyHash)-addressChecksumLen:]
-	version := pubKeyHash[0]
-	pubKeyHash = pubKeyHash[1 : len(pubKeyHash)-addressChecksumLen]
-	targetChecksum := checksum(append([]byte{version}, pubKeyHash...))
-
-	return bytes.Compare(actualChecksum, targetChecksum) == 0
+	_, err := ParseAddress(address)
+	return err == nil
 }
 
 // Checksum generates a checksum for a public key
@@ -101,7 +88,5 @@ func newKeyPair() (ecdsa.PrivateKey, []byte) {
 	if err != nil {
 		log.Panic(err)
 	}
-	pubKey := append(private.PublicKey.X.Bytes(), private.PublicKey.Y.Bytes()...)
-
-	return *private, pubKey
+	return *private, publicKeyBytes(&private.PublicKey)
 }
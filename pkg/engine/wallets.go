@@ -22,34 +22,127 @@ package engine
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 	"crypto/elliptic"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math/big"
 	"os"
 )
 
-const walletFile = "wallet_%s.dat"
+const (
+	keystoreFile = "wallet_%s.keystore"
+	// wallets used to be kept unencrypted in a gob file
+	legacyWalletFile = "wallet_%s.dat"
+)
+
+var (
+	ErrNoSeed     = errors.New("wallets have no mnemonic to derive addresses from")
+	ErrSeedExists = errors.New("wallets are already derived from another mnemonic")
+
+	ErrWalletNotFound = errors.New("no wallet with this address in the keystore")
+)
 
 // Wallets stores a collection of Bhojpur Chain wallets
 type Wallets struct {
 	Wallets map[string]*Wallet
+
+	// BIP39 seed the addresses are derived from, under DefaultBasePath,
+	// and the index of the next address
+	seed      []byte
+	nextIndex uint32
+
+	// the keystore of the wallets is encrypted with a key derived from it
+	passphrase []byte
 }
 
-// NewWallets creates new Wallets and fills it from a file if it exists
-func NewWallets(nodeID string) (*Wallets, error) {
-	wallets := Wallets{}
+// NewWallets creates new Wallets and fills it from the keystore of the node if it
+// exists. Wallets kept in the unencrypted file of earlier versions are moved into
+// the keystore, which is encrypted with the passphrase.
+func NewWallets(nodeID string, passphrase []byte) (*Wallets, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyPassphrase
+	}
+
+	wallets := Wallets{passphrase: passphrase}
 	wallets.Wallets = make(map[string]*Wallet)
 
 	err := wallets.LoadFromFile(nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wallets, nil
+}
+
+// SetMnemonic sets the mnemonic addresses are derived from. The wallets of a node
+// are only ever derived from one mnemonic, which should be written down as their backup.
+func (ws *Wallets) SetMnemonic(mnemonic, password string) error {
+	seed, err := MnemonicSeed(mnemonic, password)
+	if err != nil {
+		return err
+	}
+
+	if ws.seed != nil {
+		if !bytes.Equal(ws.seed, seed) {
+			return ErrSeedExists
+		}
+		return nil
+	}
+
+	ws.seed = seed
+	return nil
+}
+
+// HasMnemonic reports whether addresses can be derived
+func (ws Wallets) HasMnemonic() bool {
+	return ws.seed != nil
+}
+
+// CreateWallet derives the next address from the mnemonic and adds its Wallet to Wallets
+func (ws *Wallets) CreateWallet() (string, error) {
+	if ws.seed == nil {
+		return "", ErrNoSeed
+	}
+
+	address := ws.deriveWallet(ws.nextIndex)
+	ws.nextIndex++
 
-	return &wallets, err
+	return address, nil
 }
 
-// CreateWallet adds a new Wallet to Wallets
-func (ws *Wallets) CreateWallet() string {
-	wallet := NewWallet()
+// Restore regenerates the first count addresses of a mnemonic, e.g. on a new node
+// after the keystore was lost. Restoring the same mnemonic again does no harm.
+func (ws *Wallets) Restore(mnemonic, password string, count uint32) error {
+	if err := ws.SetMnemonic(mnemonic, password); err != nil {
+		return err
+	}
+
+	for index := uint32(0); index < count; index++ {
+		ws.deriveWallet(index)
+	}
+	if count > ws.nextIndex {
+		ws.nextIndex = count
+	}
+
+	return nil
+}
+
+// deriveWallet derives the wallet at the index under the base path and returns its address
+func (ws *Wallets) deriveWallet(index uint32) string {
+	path := AddressPath(DefaultBasePath, index)
+	// the base path is a constant, deriving from it can't fail
+	key, _ := NewMasterKey(ws.seed).Derive(path)
+
+	private := key.PrivateKey()
+	wallet := &Wallet{
+		PrivateKey: *private,
+		PublicKey:  publicKeyBytes(&private.PublicKey),
+		Path:       path,
+	}
 	address := fmt.Sprintf("%s", wallet.GetAddress())
 
 	ws.Wallets[address] = wallet
@@ -69,50 +162,129 @@ func (ws *Wallets) GetAddresses() []string {
 }
 
 // GetWallet returns a Wallet by its address
-func (ws Wallets) GetWallet(address string) Wallet {
-	return *ws.Wallets[address]
+func (ws Wallets) GetWallet(address string) (Wallet, error) {
+	// wallets are kept by the address as it is written now
+	address, err := CanonicalAddress(address)
+	if err != nil {
+		return Wallet{}, err
+	}
+
+	wallet, ok := ws.Wallets[address]
+	if !ok {
+		return Wallet{}, ErrWalletNotFound
+	}
+
+	return *wallet, nil
 }
 
-// LoadFromFile loads wallets from the file
+// ImportWallet adds a Wallet that was not derived from the mnemonic, e.g. an
+// existing key, to Wallets and returns its address
+func (ws *Wallets) ImportWallet(wallet *Wallet) string {
+	address := fmt.Sprintf("%s", wallet.GetAddress())
+
+	ws.Wallets[address] = wallet
+
+	return address
+}
+
+// LoadFromFile loads wallets from the keystore of the node, there is nothing
+// to load when neither the keystore nor an unencrypted wallet file exist
 func (ws *Wallets) LoadFromFile(nodeID string) error {
-	walletFile := fmt.Sprintf(walletFile, nodeID)
-	if _, err := os.Stat(walletFile); os.IsNotExist(err) {
+	content, err := ioutil.ReadFile(fmt.Sprintf(keystoreFile, nodeID))
+	if os.IsNotExist(err) {
+		return ws.migrateLegacyFile(nodeID)
+	} else if err != nil {
 		return err
 	}
 
-	fileContent, err := ioutil.ReadFile(walletFile)
+	ring, err := openKeyring(content, ws.passphrase)
 	if err != nil {
-		log.Panic(err)
+		return err
 	}
 
-	var wallets Wallets
-	gob.Register(elliptic.P256())
-	decoder := gob.NewDecoder(bytes.NewReader(fileContent))
-	err = decoder.Decode(&wallets)
+	wallets, err := ring.wallets()
 	if err != nil {
-		log.Panic(err)
+		return err
 	}
 
-	ws.Wallets = wallets.Wallets
+	ws.Wallets = wallets
+	ws.seed = ring.Seed
+	ws.nextIndex = ring.NextIndex
 
 	return nil
 }
 
-// SaveToFile saves wallets to a file
-func (ws Wallets) SaveToFile(nodeID string) {
-	var content bytes.Buffer
-	walletFile := fmt.Sprintf(walletFile, nodeID)
-
-	gob.Register(elliptic.P256())
-
-	encoder := gob.NewEncoder(&content)
-	err := encoder.Encode(ws)
+// SaveToFile encrypts the wallets and replaces the keystore of the node with them
+func (ws Wallets) SaveToFile(nodeID string) error {
+	ring, err := newKeyring(ws)
 	if err != nil {
-		log.Panic(err)
+		return err
 	}
 
-	err = ioutil.WriteFile(walletFile, content.Bytes(), 0644)
+	content, err := sealKeyring(ring, ws.passphrase)
 	if err != nil {
-		log.Panic(err)
+		return err
 	}
+
+	return writeFileAtomic(fmt.Sprintf(keystoreFile, nodeID), content)
+}
+
+// legacyWallet is a wallet as it was written to the gob file. The curve of the key is
+// left out, it was always P-256 and the encoding of curves differs between Go releases.
+type legacyWallet struct {
+	PrivateKey struct {
+		PublicKey struct {
+			X, Y *big.Int
+		}
+		D *big.Int
+	}
+	PublicKey []byte
+}
+
+// migrateLegacyFile moves the wallets of an unencrypted gob file into the keystore,
+// under the address written now. The gob file is only removed once the keystore has
+// been written.
+func (ws *Wallets) migrateLegacyFile(nodeID string) error {
+	legacyFile := fmt.Sprintf(legacyWalletFile, nodeID)
+
+	content, err := ioutil.ReadFile(legacyFile)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var wallets struct {
+		Wallets map[string]*legacyWallet
+	}
+	decoder := gob.NewDecoder(bytes.NewReader(content))
+	if err := decoder.Decode(&wallets); err != nil {
+		return fmt.Errorf("cannot read wallets from %s: %w", legacyFile, err)
+	}
+
+	for address, legacy := range wallets.Wallets {
+		key := legacy.PrivateKey
+		if key.D == nil || key.PublicKey.X == nil || key.PublicKey.Y == nil {
+			return fmt.Errorf("cannot read wallet %s from %s: %w", address, legacyFile, ErrCorruptedWallets)
+		}
+
+		private := ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{Curve: elliptic.P256(), X: key.PublicKey.X, Y: key.PublicKey.Y},
+			D:         key.D,
+		}
+		wallet := &Wallet{PrivateKey: private, PublicKey: legacy.PublicKey}
+
+		// the address is written again, earlier versions wrote some addresses differently
+		canonical := string(wallet.GetAddress())
+		if canonical != address {
+			log.Printf("wallet %s from %s is now at address %s", address, legacyFile, canonical)
+		}
+		ws.Wallets[canonical] = wallet
+	}
+
+	if err := ws.SaveToFile(nodeID); err != nil {
+		return err
+	}
+
+	return os.Remove(legacyFile)
 }
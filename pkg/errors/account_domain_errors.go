@@ -37,6 +37,10 @@ const (
 	DebitAmountAboveBalance    = ERMessage("cannot debit amount, account balance not enough")
 
 	UserCantHaveAccount = ERMessage("user is not allowed to hold an account")
+
+	OverdraftOnlyForSubscribers = ERMessage("overdraft facility is only offered to subscribers")
+	OverdraftNoCreditTier       = ERMessage("account has not been assigned a credit tier, overdraft is not available")
+	OverdraftOutstanding        = ERMessage("cannot opt out of overdraft facility with an outstanding overdraft")
 )
 
 // ErrUserHasAccount
@@ -56,3 +56,12 @@ func BadRequestResponse(message string) ApiErrorResponse {
 		Status:  http.StatusBadRequest,
 	}
 }
+
+// ForbiddenResponse
+func ForbiddenResponse(message string) ApiErrorResponse {
+	return ApiErrorResponse{
+		Error:   "forbidden",
+		Message: message,
+		Status:  http.StatusForbidden,
+	}
+}
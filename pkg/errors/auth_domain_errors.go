@@ -32,3 +32,19 @@ type Unauthorized struct {
 func (e Unauthorized) Error() string {
 	return e.Message
 }
+
+// Forbidden is returned for requests the server refuses to carry out, e.g.
+// changes while it runs read-only, or admin requests of other users
+type Forbidden struct {
+	Message string
+}
+
+func (e Forbidden) Error() string {
+	return e.Message
+}
+
+// ErrReadOnly is returned for changes refused by a read-only server
+var ErrReadOnly = Forbidden{Message: "the wallet server is read-only"}
+
+// ErrAdminOnly is returned for admin requests of users that aren't administrators
+var ErrAdminOnly = Forbidden{Message: "only administrators can make this request"}
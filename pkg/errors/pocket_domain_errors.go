@@ -0,0 +1,41 @@
+package errors
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+
+	"github.com/bhojpur/wallet/pkg/models"
+)
+
+const (
+	PocketsOnlyForSubscribers = ERMessage("pockets are only offered to subscribers")
+	ErrPocketExists           = ERMessage("a pocket with the same name already exists")
+	ErrPocketNotFound         = ERMessage("pocket not found")
+	ErrPocketBalanceTooLow    = ERMessage("cannot withdraw amount, pocket balance not enough")
+	ErrPocketLocked           = ERMessage("pocket is locked, money can't be withdrawn before the lock ends")
+	ErrPocketNotEmpty         = ERMessage("pocket still holds money, withdraw it before closing the pocket")
+)
+
+// ErrPocketPenaltyNotAccepted asks the customer to accept the penalty of withdrawing from a locked pocket
+func ErrPocketPenaltyNotAccepted(penalty models.Paisas) ERMessage {
+	return ERMessage(fmt.Sprintf("pocket is locked, withdrawing now is charged a penalty of %v, set acceptPenalty to continue", penalty.ToFloat()))
+}
@@ -26,6 +26,12 @@ const (
 	ErrUserExists         = ERMessage("user already exists")
 	ErrUserNotFound       = ERMessage("user not found")
 	ErrAgentNotSuperAgent = ERMessage("given agent is not a super agent")
+
+	// ErrNumberTaken returned when a generated account, till or agent
+	// number has already been assigned to another customer.
+	ErrNumberTaken        = ERMessage("generated number is already assigned")
+	ErrInvalidPhoneNumber = ERMessage("phone number is not a valid E.164 number")
+	ErrInvalidIdentifier  = ERMessage("customer identifier is not a valid email, phone number, account, till or agent number")
 )
 
 // PasswordHashError
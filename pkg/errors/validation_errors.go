@@ -69,6 +69,20 @@ const (
 	ErrorAgentIDRequired           = ValidationError("agentID is a required field")
 	ErrorAccountNumberRequired     = ValidationError("accountNo is a required field")
 	ErrorChargeIDRequired          = ValidationError("chargeId is a required field")
+	ErrorCreditTierRequired        = ValidationError("tier is a required field")
+	ErrorInvalidCreditTier         = ValidationError("tier should be one of none, bronze, silver or gold")
+	ErrorAccountTypeRequired       = ValidationError("accountType is a required field")
+	ErrorInvalidAccountType        = ValidationError("accountType should be one of savings, current or utility")
+	ErrorInvalidInterestRate       = ValidationError("rate should be in basis points, at most 10000")
+	ErrorInvalidDate               = ValidationError("dates should be in the format YYYY-MM-DD")
+	ErrorPocketNameRequired        = ValidationError("name is a required field")
+	ErrorPocketNameTooLong         = ValidationError("name should be at most 50 characters")
+	ErrorInvalidPeriod             = ValidationError("from should not be after to")
+	ErrorInvalidCursor             = ValidationError("cursor is not valid, use the nextCursor of a previous page")
+	ErrorInvalidPageLimit          = ValidationError("limit should be at most 100")
+	ErrorInvalidOperation          = ValidationError("operation should be a comma separated list of transaction operations")
+	ErrorExportFormatRequired      = ValidationError("format is a required field")
+	ErrorInvalidExportFormat       = ValidationError("format should be one of csv, pdf, ofx, mt940 or camt053")
 )
 
 // ParseValidationErrorMap takes in the error map that go-ozzo validation
@@ -0,0 +1,60 @@
+package estatement
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/export"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Document is the statement of an account for a month. It is rendered once when the
+// month is over and kept, the hash lets customers check a copy they were sent.
+type Document struct {
+	ID        uuid.UUID
+	AccountID uuid.UUID `gorm:"column:account_id;not null;uniqueIndex:idx_estatement_account_month"`
+	UserID    uuid.UUID `gorm:"column:user_id;not null;index"`
+	// first day of the month the statement covers
+	Month time.Time `gorm:"column:month;type:date;not null;uniqueIndex:idx_estatement_account_month"`
+
+	Format   export.Format `gorm:"column:format"`
+	FileName string        `gorm:"column:file_name"`
+	Content  []byte        `gorm:"column:content"`
+	// hex encoded SHA-256 of the content
+	ContentHash string `gorm:"column:content_hash;not null"`
+
+	// set once the customer has been sent the statement
+	NotifiedAt *time.Time `gorm:"column:notified_at;index"`
+
+	CreatedAt time.Time
+}
+
+func (d *Document) BeforeCreate(tx *gorm.DB) error {
+	d.ID, _ = uuid.NewV4()
+	return nil
+}
+
+func (Document) TableName() string {
+	return "e_statements"
+}
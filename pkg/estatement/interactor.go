@@ -0,0 +1,194 @@
+package estatement
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/export"
+	"github.com/bhojpur/wallet/pkg/helpers"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/worker"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// how often the job looks for statements of last month that have not been generated or sent
+	jobCheckInterval = time.Hour
+	// statements are sent in batches so that their content isn't all loaded at once
+	notifyBatchSize = 50
+
+	monthLayout = "2006-01"
+)
+
+type Interactor interface {
+	GenerateMonth(month time.Time) (int, error)
+	List(userID uuid.UUID) ([]Document, error)
+	Get(userID uuid.UUID, month time.Time) (Document, error)
+}
+
+func NewInteractor(repository Repository, exporter export.Interactor, notifier Notifier, workers *worker.Group) Interactor {
+	intr := &interactor{
+		repository: repository,
+		exporter:   exporter,
+		notifier:   notifier,
+	}
+
+	workers.Go("e-statement job", intr.runStatementJob)
+
+	return intr
+}
+
+type interactor struct {
+	repository Repository
+	exporter   export.Interactor
+	notifier   Notifier
+}
+
+// GenerateMonth renders the statement of a month that is over for every active account
+// and sends them out. Each statement is stored as soon as it is rendered, so a run that
+// is interrupted carries on from where it stopped and accounts that already have a
+// statement for the month are left alone. It returns the number of statements generated.
+func (i interactor) GenerateMonth(month time.Time) (int, error) {
+	month = startOfMonth(month)
+	if month.AddDate(0, 1, 0).After(time.Now()) {
+		return 0, errors.Error{Code: errors.EINVALID, Message: errors.ErrStatementMonthNotOver}
+	}
+
+	accounts, err := i.repository.GetPendingAccounts(month)
+	if err != nil {
+		return 0, err
+	}
+
+	var generated int
+	for _, acc := range accounts {
+		// system accounts don't belong to a customer
+		if acc.IsDebitNormal() {
+			continue
+		}
+
+		added, err := i.generate(acc, month)
+		if err != nil {
+			log.Printf("error happened while generating statement of account %v for %v: %v", acc.ID, month.Format(monthLayout), err)
+			continue
+		}
+		if added {
+			generated++
+		}
+	}
+
+	return generated, i.notifyPending()
+}
+
+func (i interactor) generate(acc models.Account, month time.Time) (bool, error) {
+	params := export.Params{
+		Format: export.FormatPDF,
+		From:   month.Format(helpers.DateLayout),
+		To:     month.AddDate(0, 1, -1).Format(helpers.DateLayout),
+	}
+
+	exp, err := i.exporter.Export(acc.UserID, params)
+	if err != nil {
+		return false, err
+	}
+
+	var content bytes.Buffer
+	if err := exp.Render(&content); err != nil {
+		return false, err
+	}
+
+	hash := sha256.Sum256(content.Bytes())
+	doc := Document{
+		AccountID:   acc.ID,
+		UserID:      acc.UserID,
+		Month:       month,
+		Format:      exp.Format,
+		FileName:    exp.FileName(),
+		Content:     content.Bytes(),
+		ContentHash: hex.EncodeToString(hash[:]),
+	}
+
+	return i.repository.Add(doc)
+}
+
+// notifyPending sends every statement that hasn't been sent, including
+// those left over by an earlier run
+func (i interactor) notifyPending() error {
+	for {
+		docs, err := i.repository.GetUnnotified(notifyBatchSize)
+		if err != nil {
+			return err
+		}
+
+		var sent int
+		for _, doc := range docs {
+			if err := i.notifier.Notify(doc); err != nil {
+				log.Printf("error happened while sending statement %v: %v", doc.ID, err)
+				continue
+			}
+			if err := i.repository.MarkNotified(doc.ID, time.Now()); err != nil {
+				return err
+			}
+			sent++
+		}
+
+		// stop once a batch is short or nothing in it could be sent, failures are retried on the next run
+		if len(docs) < notifyBatchSize || sent == 0 {
+			return nil
+		}
+	}
+}
+
+// List returns the statements of a customer without their content
+func (i interactor) List(userID uuid.UUID) ([]Document, error) {
+	return i.repository.FetchByUser(userID)
+}
+
+// Get returns the statement of a customer for a month
+func (i interactor) Get(userID uuid.UUID, month time.Time) (Document, error) {
+	return i.repository.FindByMonth(userID, startOfMonth(month))
+}
+
+func (i interactor) runStatementJob(done <-chan struct{}) {
+	ticker := time.NewTicker(jobCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := i.GenerateMonth(helpers.LastMonth(time.Now())); err != nil {
+			log.Printf("error happened while generating e-statements %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+	}
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,130 @@
+package estatement
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/storage"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// columns listed without the content, which is only loaded when needed
+var summaryColumns = []string{"id", "account_id", "user_id", "month", "format", "file_name", "content_hash", "notified_at", "created_at"}
+
+type Repository interface {
+	Add(Document) (bool, error)
+	GetPendingAccounts(month time.Time) ([]models.Account, error)
+	GetUnnotified(limit int) ([]Document, error)
+	MarkNotified(id uuid.UUID, at time.Time) error
+	FetchByUser(userID uuid.UUID) ([]Document, error)
+	FindByMonth(userID uuid.UUID, month time.Time) (Document, error)
+}
+
+func NewRepository(database *storage.Database) Repository {
+	return &repository{db: database}
+}
+
+type repository struct {
+	db *storage.Database
+}
+
+// Add stores a document, it returns false if the account already
+// has a statement for the month
+func (r repository) Add(doc Document) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&doc)
+	if err := result.Error; err != nil {
+		return false, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// GetPendingAccounts fetches the active accounts, opened before the end of the
+// month, that don't have a statement for it yet
+func (r repository) GetPendingAccounts(month time.Time) ([]models.Account, error) {
+	var accounts []models.Account
+
+	result := r.db.Where(
+		models.Account{Status: models.StatusActive},
+	).Where(
+		"created_at < ?", month.AddDate(0, 1, 0),
+	).Where(
+		"NOT EXISTS (SELECT 1 FROM e_statements e WHERE e.account_id = accounts.id AND e.month = ?)", month,
+	).Find(&accounts)
+
+	if err := result.Error; err != nil {
+		return nil, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return accounts, nil
+}
+
+// GetUnnotified fetches the oldest documents that haven't been sent yet
+func (r repository) GetUnnotified(limit int) ([]Document, error) {
+	var docs []Document
+
+	result := r.db.Where("notified_at IS NULL").Order("created_at").Limit(limit).Find(&docs)
+	if err := result.Error; err != nil {
+		return nil, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return docs, nil
+}
+
+func (r repository) MarkNotified(id uuid.UUID, at time.Time) error {
+	result := r.db.Model(&Document{ID: id}).Update("notified_at", at)
+	if err := result.Error; err != nil {
+		return errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return nil
+}
+
+// FetchByUser lists the statements of a user without their content, newest first
+func (r repository) FetchByUser(userID uuid.UUID) ([]Document, error) {
+	var docs []Document
+
+	result := r.db.Select(summaryColumns).Where(Document{UserID: userID}).Order("month desc").Find(&docs)
+	if err := result.Error; err != nil {
+		return nil, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return docs, nil
+}
+
+func (r repository) FindByMonth(userID uuid.UUID, month time.Time) (Document, error) {
+	var doc Document
+
+	result := r.db.Where(Document{UserID: userID}).Where("month = ?", month).Limit(1).Find(&doc)
+	if err := result.Error; err != nil {
+		return Document{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+	if result.RowsAffected == 0 {
+		return Document{}, errors.Error{Code: errors.ENOTFOUND, Message: errors.EStatementNotFound}
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,91 @@
+package export
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/helpers"
+)
+
+// camt053Renderer writes an ISO 20022 bank to customer statement, camt.053.001.02
+type camt053Renderer struct {
+	w io.Writer
+}
+
+func camtIndicator(amount Amount) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+func camtBalance(code string, amount Amount, day time.Time) string {
+	return fmt.Sprintf(`<Bal><Tp><CdOrPrtry><Cd>%s</Cd></CdOrPrtry></Tp><Amt Ccy="%s">%s</Amt><CdtDbtInd>%s</CdtDbtInd><Dt><Dt>%s</Dt></Dt></Bal>
+`, code, currency, amount.format("."), camtIndicator(amount), day.Format(helpers.DateLayout))
+}
+
+func (r *camt053Renderer) Begin(h Header) error {
+	_, err := fmt.Fprintf(r.w, `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+<BkToCstmrStmt>
+<GrpHdr><MsgId>%s</MsgId><CreDtTm>%s</CreDtTm></GrpHdr>
+<Stmt>
+<Id>%s</Id>
+<CreDtTm>%s</CreDtTm>
+<FrToDt><FrDtTm>%s</FrDtTm><ToDtTm>%s</ToDtTm></FrToDt>
+<Acct><Id><Othr><Id>%s</Id></Othr></Id><Ccy>%s</Ccy></Acct>
+%s%s`,
+		escape(h.Reference()), h.CreatedAt.Format(time.RFC3339),
+		escape(h.Reference()), h.CreatedAt.Format(time.RFC3339),
+		h.From.Format(time.RFC3339), h.LastDay().Format(time.RFC3339),
+		escape(h.Account.AccountNumber), currency,
+		camtBalance("OPBD", h.OpeningBalance, h.From),
+		camtBalance("CLBD", h.ClosingBalance, h.LastDay()),
+	)
+
+	return err
+}
+
+func (r *camt053Renderer) Entry(e Entry) error {
+	_, err := fmt.Fprintf(r.w, `<Ntry>
+<Amt Ccy="%s">%s</Amt>
+<CdtDbtInd>%s</CdtDbtInd>
+<Sts>BOOK</Sts>
+<BookgDt><DtTm>%s</DtTm></BookgDt>
+<ValDt><Dt>%s</Dt></ValDt>
+<AcctSvcrRef>%s</AcctSvcrRef>
+<BkTxCd><Prtry><Cd>%s</Cd></Prtry></BkTxCd>
+<AddtlNtryInf>%s</AddtlNtryInf>
+</Ntry>
+`, currency, e.Amount.format("."), camtIndicator(e.Amount),
+		e.CreatedAt.Format(time.RFC3339), e.CreatedAt.Format(helpers.DateLayout),
+		e.ID, escape(string(e.Operation)), escape(e.Counterparty))
+
+	return err
+}
+
+func (r *camt053Renderer) End() error {
+	_, err := io.WriteString(r.w, "</Stmt>\n</BkToCstmrStmt>\n</Document>\n")
+	return err
+}
@@ -0,0 +1,70 @@
+package export
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+)
+
+type csvRenderer struct {
+	w *csv.Writer
+}
+
+func newCSVRenderer(w io.Writer) *csvRenderer {
+	return &csvRenderer{w: csv.NewWriter(w)}
+}
+
+func (r *csvRenderer) Begin(Header) error {
+	return r.w.Write([]string{"date", "transaction_id", "type", "counterparty", "debit", "credit", "balance"})
+}
+
+func (r *csvRenderer) Entry(e Entry) error {
+	var debit, credit string
+	if e.Amount < 0 {
+		debit = (-e.Amount).String()
+	} else {
+		credit = e.Amount.String()
+	}
+
+	err := r.w.Write([]string{
+		e.CreatedAt.Format(time.RFC3339),
+		e.ID.String(),
+		string(e.Operation),
+		e.Counterparty,
+		debit,
+		credit,
+		e.Balance.String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// write rows out as they come rather than buffering the whole statement
+	r.w.Flush()
+	return r.w.Error()
+}
+
+func (r *csvRenderer) End() error {
+	r.w.Flush()
+	return r.w.Error()
+}
@@ -0,0 +1,184 @@
+package export
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/statement"
+)
+
+type Format string
+
+const (
+	FormatCSV     = Format("csv")
+	FormatPDF     = Format("pdf")
+	FormatOFX     = Format("ofx")
+	FormatMT940   = Format("mt940")
+	FormatCamt053 = Format("camt053")
+)
+
+// currency every amount on the wallet is in
+const currency = "INR"
+
+// ContentType returns the media type of documents in the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatCamt053:
+		return "application/xml"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Extension returns the file name extension of documents in the format
+func (f Format) Extension() string {
+	switch f {
+	case FormatMT940:
+		return "sta"
+	case FormatCamt053:
+		return "xml"
+	default:
+		return string(f)
+	}
+}
+
+// Amount is a signed amount in paisas. Exported statements show the net balance of an
+// account, which is negative while an overdraft is outstanding.
+type Amount int64
+
+func toAmount(rupees float64) Amount {
+	return Amount(math.Round(rupees * 100))
+}
+
+// format returns the amount without a sign, with two decimals after sep
+func (a Amount) format(sep string) string {
+	if a < 0 {
+		a = -a
+	}
+	return fmt.Sprintf("%d%s%02d", a/100, sep, a%100)
+}
+
+// String returns the amount with a leading minus when negative, e.g. -30.50
+func (a Amount) String() string {
+	if a < 0 {
+		return "-" + a.format(".")
+	}
+	return a.format(".")
+}
+
+// Header describes the statement being exported
+type Header struct {
+	Account        models.Account
+	From           time.Time // inclusive
+	To             time.Time // exclusive
+	OpeningBalance Amount
+	ClosingBalance Amount
+	CreatedAt      time.Time
+}
+
+// LastDay returns the last day covered by the statement
+func (h Header) LastDay() time.Time {
+	return h.To.Add(-time.Nanosecond)
+}
+
+// Reference returns an identifier of the statement, unique per account and period
+func (h Header) Reference() string {
+	return fmt.Sprintf("%s-%s", h.Account.AccountNumber, h.From.Format("20060102"))
+}
+
+// Entry is a row on an exported statement
+type Entry struct {
+	statement.Statement
+
+	// account number of the counterparty or, when it has none, its type
+	Counterparty string
+	// positive for credits, negative for debits
+	Amount  Amount
+	Balance Amount
+}
+
+// renderer writes a statement in one format. Entries are given oldest first
+// between a call to Begin and a call to End.
+type renderer interface {
+	Begin(Header) error
+	Entry(Entry) error
+	End() error
+}
+
+func newRenderer(format Format, w io.Writer, branding Branding) renderer {
+	switch format {
+	case FormatPDF:
+		return newPDFRenderer(w, branding)
+	case FormatOFX:
+		return &ofxRenderer{w: w}
+	case FormatMT940:
+		return &mt940Renderer{w: w}
+	case FormatCamt053:
+		return &camt053Renderer{w: w}
+	default:
+		return newCSVRenderer(w)
+	}
+}
+
+// Export is a statement ready to be written. Looking up the account and the balances
+// happens before, so that a failure is reported before anything is streamed.
+type Export struct {
+	Format Format
+	Header Header
+
+	branding Branding
+	stream   func(fn func(Entry) error) error
+}
+
+// FileName returns the name the document is downloaded as
+func (e Export) FileName() string {
+	return fmt.Sprintf("statement-%s.%s", e.Header.Reference(), e.Format.Extension())
+}
+
+// Render writes the statement to w, one entry at a time
+func (e Export) Render(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	r := newRenderer(e.Format, buf, e.branding)
+
+	if err := r.Begin(e.Header); err != nil {
+		return err
+	}
+	if err := e.stream(r.Entry); err != nil {
+		return err
+	}
+	if err := r.End(); err != nil {
+		return err
+	}
+
+	return buf.Flush()
+}
@@ -0,0 +1,160 @@
+package export
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/account"
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/statement"
+
+	"github.com/gofrs/uuid"
+)
+
+type Interactor interface {
+	// Export prepares the statement of a customer's own account
+	Export(userID uuid.UUID, params Params) (Export, error)
+	// ExportAccount prepares the statement of any account, e.g. for an admin
+	// reconciling the settlement account
+	ExportAccount(accountNumber string, params Params) (Export, error)
+}
+
+func NewInteractor(statementRepo statement.Repository, accRepo account.Repository, branding Branding) Interactor {
+	return &interactor{
+		statementRepo: statementRepo,
+		accRepo:       accRepo,
+		branding:      branding,
+	}
+}
+
+type interactor struct {
+	statementRepo statement.Repository
+	accRepo       account.Repository
+	branding      Branding
+}
+
+func (i interactor) Export(userID uuid.UUID, params Params) (Export, error) {
+	acc, err := i.accRepo.GetAccountByUserID(userID)
+	if errors.ErrorCode(err) == errors.ENOTFOUND {
+		return Export{}, errors.Error{Message: errors.AccountNotCreated, Err: err}
+	} else if err != nil {
+		return Export{}, err
+	}
+
+	return i.prepare(acc, params)
+}
+
+func (i interactor) ExportAccount(accountNumber string, params Params) (Export, error) {
+	if accountNumber == "" {
+		return Export{}, errors.ValidationErrors{errors.ErrorAccountNumberRequired}
+	}
+
+	acc, err := i.accRepo.GetAccountByNumber(accountNumber)
+	if err != nil {
+		return Export{}, err
+	}
+
+	return i.prepare(acc, params)
+}
+
+func (i interactor) prepare(acc models.Account, params Params) (Export, error) {
+	from, to := params.Period()
+
+	opening, err := i.netBalanceAt(acc.UserID, from)
+	if err != nil {
+		return Export{}, err
+	}
+	closing, err := i.netBalanceAt(acc.UserID, to)
+	if err != nil {
+		return Export{}, err
+	}
+
+	export := Export{
+		Format: params.Format,
+		Header: Header{
+			Account:        acc,
+			From:           from,
+			To:             to,
+			OpeningBalance: opening,
+			ClosingBalance: closing,
+			CreatedAt:      time.Now(),
+		},
+		branding: i.branding,
+	}
+
+	export.stream = func(fn func(Entry) error) error {
+		counterparties := newCounterpartyCache(i.accRepo)
+
+		return i.statementRepo.Stream(acc.UserID, from, to, func(stmt statement.Statement) error {
+			return fn(Entry{
+				Statement:    stmt,
+				Counterparty: counterparties.describe(stmt),
+				Amount:       toAmount(stmt.CreditAmount) - toAmount(stmt.DebitAmount),
+				Balance:      toAmount(stmt.Balance) - toAmount(stmt.OverdraftBalance),
+			})
+		})
+	}
+
+	return export, nil
+}
+
+// netBalanceAt returns the balance of an account at a point in time less any overdraft outstanding
+func (i interactor) netBalanceAt(userID uuid.UUID, at time.Time) (Amount, error) {
+	stmt, err := i.statementRepo.GetLastBefore(userID, at)
+	if err != nil {
+		return 0, err
+	}
+
+	return toAmount(stmt.Balance) - toAmount(stmt.OverdraftBalance), nil
+}
+
+// counterpartyCache looks up the account numbers of counterparties, a customer
+// usually deals with the same few counterparties over and over.
+type counterpartyCache struct {
+	accRepo account.Repository
+	numbers map[uuid.UUID]string
+}
+
+func newCounterpartyCache(accRepo account.Repository) *counterpartyCache {
+	return &counterpartyCache{accRepo: accRepo, numbers: map[uuid.UUID]string{}}
+}
+
+func (c *counterpartyCache) describe(stmt statement.Statement) string {
+	if stmt.CounterpartyID == uuid.Nil {
+		return string(stmt.CounterpartyType)
+	}
+
+	number, ok := c.numbers[stmt.CounterpartyID]
+	if !ok {
+		acc, err := c.accRepo.GetAccountByUserID(stmt.CounterpartyID)
+		if err == nil {
+			number = acc.AccountNumber
+		}
+		if number == "" {
+			number = string(stmt.CounterpartyType)
+		}
+		c.numbers[stmt.CounterpartyID] = number
+	}
+
+	return number
+}
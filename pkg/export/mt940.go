@@ -0,0 +1,98 @@
+package export
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// mt940DateLayout is the YYMMDD date of SWIFT messages
+const mt940DateLayout = "060102"
+
+// mt940Renderer writes a SWIFT MT940 customer statement message
+type mt940Renderer struct {
+	w      io.Writer
+	header Header
+}
+
+// mtBalance formats a balance field, e.g. C201130INR690,00
+func mtBalance(amount Amount, day time.Time) string {
+	mark := "C"
+	if amount < 0 {
+		mark = "D"
+	}
+	return fmt.Sprintf("%s%s%s%s", mark, day.Format(mt940DateLayout), currency, amount.format(","))
+}
+
+func (r *mt940Renderer) Begin(h Header) error {
+	r.header = h
+
+	_, err := fmt.Fprintf(r.w, ":20:%s\r\n:25:%s\r\n:28C:%s/1\r\n:60F:%s\r\n",
+		"STMT"+h.From.Format("20060102"),
+		truncate(h.Account.AccountNumber, 35),
+		h.From.Format("0601"),
+		mtBalance(h.OpeningBalance, h.From),
+	)
+
+	return err
+}
+
+func (r *mt940Renderer) Entry(e Entry) error {
+	mark := "C"
+	if e.Amount < 0 {
+		mark = "D"
+	}
+
+	// value date, entry date, mark, amount, transaction type and reference
+	ref := strings.ReplaceAll(e.ID.String(), "-", "")
+	_, err := fmt.Fprintf(r.w, ":61:%s%s%s%sNTRF%s\r\n:86:%s\r\n",
+		e.CreatedAt.Format(mt940DateLayout),
+		e.CreatedAt.Format("0102"),
+		mark,
+		e.Amount.format(","),
+		truncate(ref, 16),
+		truncate(swiftText(strings.TrimSpace(string(e.Operation)+" "+e.Counterparty)), 65),
+	)
+
+	return err
+}
+
+func (r *mt940Renderer) End() error {
+	_, err := fmt.Fprintf(r.w, ":62F:%s\r\n-\r\n", mtBalance(r.header.ClosingBalance, r.header.LastDay()))
+	return err
+}
+
+// swiftText replaces characters outside the SWIFT X character set
+func swiftText(s string) string {
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			return c
+		case strings.ContainsRune("/-?:().,'+ ", c):
+			return c
+		default:
+			return ' '
+		}
+	}, s)
+}
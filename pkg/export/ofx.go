@@ -0,0 +1,108 @@
+package export
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// ofxDateLayout is the OFX datetime in UTC
+const ofxDateLayout = "20060102150405"
+
+// ofxRenderer writes an OFX 2.2 bank statement download
+type ofxRenderer struct {
+	w      io.Writer
+	header Header
+}
+
+func ofxDate(t time.Time) string {
+	return t.UTC().Format(ofxDateLayout) + "[0:GMT]"
+}
+
+func (r *ofxRenderer) Begin(h Header) error {
+	r.header = h
+
+	_, err := fmt.Fprintf(r.w, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS>
+<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<DTSERVER>%s</DTSERVER>
+<LANGUAGE>ENG</LANGUAGE>
+</SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS>
+<TRNUID>%s</TRNUID>
+<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS>
+<CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>WALLET</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>%s</DTSTART>
+<DTEND>%s</DTEND>
+`, ofxDate(h.CreatedAt), escape(h.Reference()), currency, escape(h.Account.AccountNumber), ofxDate(h.From), ofxDate(h.To))
+
+	return err
+}
+
+func (r *ofxRenderer) Entry(e Entry) error {
+	trnType := "CREDIT"
+	if e.Amount < 0 {
+		trnType = "DEBIT"
+	}
+
+	name := string(e.Operation)
+	if e.Counterparty != "" {
+		name = e.Counterparty
+	}
+
+	_, err := fmt.Fprintf(r.w, `<STMTTRN>
+<TRNTYPE>%s</TRNTYPE>
+<DTPOSTED>%s</DTPOSTED>
+<TRNAMT>%s</TRNAMT>
+<FITID>%s</FITID>
+<NAME>%s</NAME>
+<MEMO>%s</MEMO>
+</STMTTRN>
+`, trnType, ofxDate(e.CreatedAt), e.Amount, e.ID, escape(truncate(name, 32)), escape(string(e.Operation)))
+
+	return err
+}
+
+func (r *ofxRenderer) End() error {
+	_, err := fmt.Fprintf(r.w, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+</STMTRS>
+</STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`, r.header.ClosingBalance, ofxDate(r.header.To))
+
+	return err
+}
+
+// truncate cuts s down to at most n bytes, formats limit the length of most fields
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,78 @@
+package export
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/helpers"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Params are the query parameters of a statement export. The days are inclusive
+// and default to the current month.
+type Params struct {
+	Format Format `query:"format"`
+	From   string `query:"from"`
+	To     string `query:"to"`
+	// only used by admins, who can export the statement of any account
+	AccountNumber string `query:"accountNo"`
+}
+
+func (req Params) Validate() error {
+	err := validation.ValidateStruct(&req,
+		validation.Field(&req.Format,
+			validation.Required.Error(string(errors.ErrorExportFormatRequired)),
+			validation.In(FormatCSV, FormatPDF, FormatOFX, FormatMT940, FormatCamt053).Error(string(errors.ErrorInvalidExportFormat)),
+		),
+		validation.Field(&req.From, validation.Date(helpers.DateLayout).Error(string(errors.ErrorInvalidDate))),
+		validation.Field(&req.To,
+			validation.Date(helpers.DateLayout).Error(string(errors.ErrorInvalidDate)),
+			validation.By(func(interface{}) error {
+				if req.From != "" && req.To != "" && req.From > req.To {
+					return errors.ErrorInvalidPeriod
+				}
+				return nil
+			}),
+		),
+	)
+
+	return errors.ParseValidationErrorMap(err)
+}
+
+// Period returns the start of the first day and the end of the last day exported
+func (req Params) Period() (from, to time.Time) {
+	now := time.Now()
+	from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	to = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+
+	if req.From != "" {
+		from, _ = time.ParseInLocation(helpers.DateLayout, req.From, now.Location())
+	}
+	if req.To != "" {
+		// the last day is included in full
+		to, _ = time.ParseInLocation(helpers.DateLayout, req.To, now.Location())
+		to = to.AddDate(0, 0, 1)
+	}
+	return from, to
+}
@@ -0,0 +1,273 @@
+package export
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bhojpur/wallet/pkg/helpers"
+)
+
+// A4 page in points with the layout of the statement table
+const (
+	pageWidth   = 595.0
+	pageHeight  = 842.0
+	pageMargin  = 40.0
+	rowHeight   = 14.0
+	tableTop    = 700.0
+	tableBottom = 70.0
+	fontSize    = 9.0
+)
+
+// numbers of the objects written up front, pages are numbered after them
+const (
+	catalogObject = iota + 1
+	pagesObject
+	regularFontObject
+	boldFontObject
+	monoFontObject
+)
+
+// columns of the statement table, amounts are right aligned on their x
+var pdfColumns = []struct {
+	title string
+	x     float64
+	right bool
+}{
+	{"Date", pageMargin, false},
+	{"Type", pageMargin + 95, false},
+	{"Counterparty", pageMargin + 200, false},
+	{"Debit", pageMargin + 380, true},
+	{"Credit", pageMargin + 450, true},
+	{"Balance", pageWidth - pageMargin, true},
+}
+
+// pdfRenderer writes a PDF document page by page. Only the page being laid out
+// is kept in memory, the page tree and cross reference table are written last.
+type pdfRenderer struct {
+	w        *countingWriter
+	branding Branding
+	header   Header
+
+	offsets []int64 // of every object written, by object number - 1
+	pages   []int   // object numbers of the pages
+	page    *bytes.Buffer
+	y       float64
+}
+
+func newPDFRenderer(w io.Writer, branding Branding) *pdfRenderer {
+	return &pdfRenderer{w: &countingWriter{w: w}, branding: branding}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (r *pdfRenderer) object(body string) error {
+	r.offsets = append(r.offsets, r.w.n)
+	_, err := fmt.Fprintf(r.w, "%d 0 obj\n%s\nendobj\n", len(r.offsets), body)
+	return err
+}
+
+func (r *pdfRenderer) Begin(h Header) error {
+	r.header = h
+
+	if _, err := io.WriteString(r.w, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"); err != nil {
+		return err
+	}
+
+	objects := []string{
+		fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObject),
+		"", // the page tree is written once every page is known
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+	}
+	for i, body := range objects {
+		if i+1 == pagesObject {
+			r.offsets = append(r.offsets, 0)
+			continue
+		}
+		if err := r.object(body); err != nil {
+			return err
+		}
+	}
+
+	r.newPage()
+	return nil
+}
+
+func (r *pdfRenderer) newPage() {
+	r.page = &bytes.Buffer{}
+	h := r.header
+	c := r.branding.Colour
+
+	// header band with the brand name
+	fmt.Fprintf(r.page, "%.3f %.3f %.3f rg 0 %.1f %.1f 60 re f\n", c[0], c[1], c[2], pageHeight-60, pageWidth)
+	r.text("F2", 18, pageMargin, pageHeight-38, r.branding.Name, "1 1 1")
+
+	y := pageHeight - 85
+	r.text("F2", 12, pageMargin, y, "Statement of account "+h.Account.AccountNumber, "0 0 0")
+	r.text("F1", fontSize, pageMargin, y-16, fmt.Sprintf("Period: %s to %s", h.From.Format(helpers.DateLayout), h.LastDay().Format(helpers.DateLayout)), "0 0 0")
+	if len(r.pages) == 0 {
+		r.text("F1", fontSize, pageMargin, y-30, fmt.Sprintf("Opening balance: %s %s", currency, h.OpeningBalance), "0 0 0")
+	}
+
+	for _, col := range pdfColumns {
+		r.cell("F2", tableTop, col.x, col.right, col.title)
+	}
+	fmt.Fprintf(r.page, "0.5 w %.1f %.1f m %.1f %.1f l S\n", pageMargin, tableTop-4, pageWidth-pageMargin, tableTop-4)
+	r.y = tableTop - rowHeight - 4
+}
+
+func (r *pdfRenderer) text(font string, size, x, y float64, s, colour string) {
+	fmt.Fprintf(r.page, "BT %s rg /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", colour, font, size, x, y, pdfString(s))
+}
+
+// cell writes a table cell, right aligned cells are set in the fixed width font so
+// their width is known without font metrics
+func (r *pdfRenderer) cell(font string, y, x float64, right bool, s string) {
+	if right {
+		if font == "F1" {
+			font = "F3"
+		}
+		x -= float64(len(s)) * fontSize * 0.6
+	}
+	r.text(font, fontSize, x, y, s, "0 0 0")
+}
+
+func (r *pdfRenderer) Entry(e Entry) error {
+	if r.y < tableBottom {
+		if err := r.endPage(); err != nil {
+			return err
+		}
+		r.newPage()
+	}
+
+	var debit, credit string
+	if e.Amount < 0 {
+		debit = (-e.Amount).String()
+	} else {
+		credit = e.Amount.String()
+	}
+
+	values := []string{
+		e.CreatedAt.Format("2006-01-02 15:04"),
+		string(e.Operation),
+		truncate(e.Counterparty, 34),
+		debit,
+		credit,
+		e.Balance.String(),
+	}
+	for i, col := range pdfColumns {
+		r.cell("F1", r.y, col.x, col.right, values[i])
+	}
+	r.y -= rowHeight
+
+	return nil
+}
+
+// endPage writes out the page being laid out with its footer
+func (r *pdfRenderer) endPage() error {
+	r.text("F1", 8, pageMargin, 40, r.branding.Footer, "0.4 0.4 0.4")
+	r.text("F1", 8, pageWidth-pageMargin-40, 40, fmt.Sprintf("Page %d", len(r.pages)+1), "0.4 0.4 0.4")
+
+	content := r.page.Bytes()
+	if err := r.object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content)); err != nil {
+		return err
+	}
+
+	err := r.object(fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.0f %.0f] /Contents %d 0 R /Resources << /Font << /F1 %d 0 R /F2 %d 0 R /F3 %d 0 R >> >> >>",
+		pagesObject, pageWidth, pageHeight, len(r.offsets), regularFontObject, boldFontObject, monoFontObject,
+	))
+	if err != nil {
+		return err
+	}
+
+	r.pages = append(r.pages, len(r.offsets))
+	return nil
+}
+
+func (r *pdfRenderer) End() error {
+	if r.y < tableBottom+rowHeight {
+		if err := r.endPage(); err != nil {
+			return err
+		}
+		r.newPage()
+	}
+	r.y -= rowHeight / 2
+	r.text("F2", fontSize, pageMargin, r.y, fmt.Sprintf("Closing balance: %s %s", currency, r.header.ClosingBalance), "0 0 0")
+
+	if err := r.endPage(); err != nil {
+		return err
+	}
+
+	// the page tree goes at the end, its number was reserved up front
+	kids := make([]string, len(r.pages))
+	for i, page := range r.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	r.offsets[pagesObject-1] = r.w.n
+	_, err := fmt.Fprintf(r.w, "%d 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n",
+		pagesObject, strings.Join(kids, " "), len(r.pages))
+	if err != nil {
+		return err
+	}
+
+	xref := r.w.n
+	fmt.Fprintf(r.w, "xref\n0 %d\n0000000000 65535 f \n", len(r.offsets)+1)
+	for _, offset := range r.offsets {
+		fmt.Fprintf(r.w, "%010d 00000 n \n", offset)
+	}
+	_, err = fmt.Fprintf(r.w, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(r.offsets)+1, catalogObject, xref)
+
+	return err
+}
+
+// pdfString escapes s for a literal string, characters the standard fonts
+// can't show are replaced
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case c < 32 || c > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
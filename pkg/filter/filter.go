@@ -0,0 +1,270 @@
+package filter
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// timeLayout has a fixed width, so that times order the same as their text
+const timeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// aliases are short names of fields that are filtered on often
+var aliases = map[string]string{
+	"spec":          "metadata.engine_spec_name",
+	"owner":         "metadata.owner",
+	"trigger":       "metadata.trigger",
+	"created":       "metadata.created",
+	"finished":      "metadata.finished",
+	"success":       "conditions.success",
+	"failure_count": "conditions.failure_count",
+	"can_replay":    "conditions.can_replay",
+	"did_execute":   "conditions.did_execute",
+	"wait_until":    "conditions.wait_until",
+}
+
+// Value returns the value of a field of an engine status as text, and whether the status
+// has the field set. Fields are named by their path in the EngineStatus message, such as
+// phase or metadata.owner, annotations by their key as annotation.<key>. Enums are the
+// lower case name of the value without its prefix, such as done for PHASE_DONE.
+func Value(status *v1.EngineStatus, field string) (value string, present bool, err error) {
+	if alias, ok := aliases[field]; ok {
+		field = alias
+	}
+
+	path := strings.Split(field, ".")
+	if len(path) == 2 && path[0] == "annotation" {
+		value, present = annotation(status.GetMetadata(), path[1])
+		return value, present, nil
+	}
+	if len(path) == 3 && path[0] == "metadata" && path[1] == "annotations" {
+		value, present = annotation(status.GetMetadata(), path[2])
+		return value, present, nil
+	}
+
+	// messages that are not set read as empty ones, so that the path is checked all the same
+	msg, present := status.ProtoReflect(), true
+	for i, name := range path {
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if fd == nil || fd.IsList() || fd.IsMap() {
+			return "", false, fmt.Errorf("unknown field %q", field)
+		}
+		present = present && msg.Has(fd)
+
+		if i < len(path)-1 {
+			if fd.Kind() != protoreflect.MessageKind || isTimestamp(fd) {
+				return "", false, fmt.Errorf("unknown field %q", field)
+			}
+			msg = msg.Get(fd).Message()
+			continue
+		}
+
+		value, err := format(fd, msg.Get(fd))
+		if err != nil {
+			return "", false, fmt.Errorf("field %q: %w", field, err)
+		}
+		return value, present, nil
+	}
+
+	return "", false, fmt.Errorf("unknown field %q", field)
+}
+
+func annotation(metadata *v1.EngineMetadata, key string) (string, bool) {
+	for _, annotation := range metadata.GetAnnotations() {
+		if annotation.Key == key {
+			return annotation.Value, true
+		}
+	}
+	return "", false
+}
+
+func isTimestamp(fd protoreflect.FieldDescriptor) bool {
+	return fd.Kind() == protoreflect.MessageKind && fd.Message().FullName() == "google.protobuf.Timestamp"
+}
+
+func format(fd protoreflect.FieldDescriptor, value protoreflect.Value) (string, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return value.String(), nil
+	case protoreflect.BoolKind:
+		return strconv.FormatBool(value.Bool()), nil
+	case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Sint32Kind, protoreflect.Sint64Kind:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case protoreflect.Uint32Kind, protoreflect.Uint64Kind:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case protoreflect.EnumKind:
+		ev := fd.Enum().Values().ByNumber(value.Enum())
+		if ev == nil {
+			return strconv.Itoa(int(value.Enum())), nil
+		}
+		name := string(ev.Name())
+		if i := strings.Index(name, "_"); i >= 0 {
+			name = name[i+1:]
+		}
+		return strings.ToLower(name), nil
+	case protoreflect.MessageKind:
+		if isTimestamp(fd) {
+			ts := value.Message()
+			seconds := ts.Get(fd.Message().Fields().ByName("seconds")).Int()
+			nanos := ts.Get(fd.Message().Fields().ByName("nanos")).Int()
+			if seconds == 0 && nanos == 0 {
+				return "", nil
+			}
+			return time.Unix(seconds, nanos).UTC().Format(timeLayout), nil
+		}
+	}
+	return "", fmt.Errorf("%s is not a value that can be filtered on", fd.Kind())
+}
+
+// Validate checks that the fields and operations of filter and order expressions are known,
+// so that matching and sorting with them can't fail
+func Validate(filters []*v1.FilterExpression, order []*v1.OrderExpression) error {
+	empty := &v1.EngineStatus{}
+	for _, expr := range filters {
+		for _, term := range expr.GetTerms() {
+			if _, err := matchTerm(empty, term); err != nil {
+				return err
+			}
+		}
+	}
+	for _, o := range order {
+		if _, _, err := Value(empty, o.Field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Match tells whether an engine status matches every expression. An expression
+// matches when any of its terms does, an expression without terms always matches.
+func Match(status *v1.EngineStatus, filters []*v1.FilterExpression) (bool, error) {
+	for _, expr := range filters {
+		if len(expr.GetTerms()) == 0 {
+			continue
+		}
+
+		var matched bool
+		for _, term := range expr.Terms {
+			ok, err := matchTerm(status, term)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matchTerm(status *v1.EngineStatus, term *v1.FilterTerm) (bool, error) {
+	value, present, err := Value(status, term.Field)
+	if err != nil {
+		return false, err
+	}
+
+	var ok bool
+	switch term.Operation {
+	case v1.FilterOp_OP_EQUALS:
+		ok = value == term.Value
+	case v1.FilterOp_OP_STARTS_WITH:
+		ok = strings.HasPrefix(value, term.Value)
+	case v1.FilterOp_OP_ENDS_WITH:
+		ok = strings.HasSuffix(value, term.Value)
+	case v1.FilterOp_OP_CONTAINS:
+		ok = strings.Contains(value, term.Value)
+	case v1.FilterOp_OP_EXISTS:
+		ok = present
+	default:
+		return false, fmt.Errorf("unknown filter operation %v", term.Operation)
+	}
+
+	if term.Negate {
+		ok = !ok
+	}
+	return ok, nil
+}
+
+// Sort orders engine statuses by the fields of the order expressions, the first one
+// first. Statuses that order the same keep the order they were in.
+func Sort(statuses []*v1.EngineStatus, order []*v1.OrderExpression) error {
+	if len(order) == 0 {
+		return nil
+	}
+
+	// the values are read up front, so that an unknown field is an error rather than a panic in the sort
+	keys := make(map[*v1.EngineStatus][]string, len(statuses))
+	for _, status := range statuses {
+		values := make([]string, len(order))
+		for i, o := range order {
+			value, _, err := Value(status, o.Field)
+			if err != nil {
+				return err
+			}
+			values[i] = value
+		}
+		keys[status] = values
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		ki, kj := keys[statuses[i]], keys[statuses[j]]
+		for n, o := range order {
+			c := compare(ki[n], kj[n])
+			if c == 0 {
+				continue
+			}
+			if o.Ascending {
+				return c < 0
+			}
+			return c > 0
+		}
+		return false
+	})
+	return nil
+}
+
+// compare compares numbers by their value and everything else as text
+func compare(a, b string) int {
+	if na, err := strconv.ParseInt(a, 10, 64); err == nil {
+		if nb, err := strconv.ParseInt(b, 10, 64); err == nil {
+			switch {
+			case na < nb:
+				return -1
+			case na > nb:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
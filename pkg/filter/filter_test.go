@@ -0,0 +1,154 @@
+package filter
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func engineStatus(name, spec string, phase v1.EnginePhase, success bool, created time.Time, annotations ...*v1.Annotation) *v1.EngineStatus {
+	return &v1.EngineStatus{
+		Name:  name,
+		Phase: phase,
+		Metadata: &v1.EngineMetadata{
+			EngineSpecName: spec,
+			Created:        timestamppb.New(created),
+			Annotations:    annotations,
+		},
+		Conditions: &v1.EngineConditions{Success: success},
+	}
+}
+
+func TestMatch(t *testing.T) {
+	created := time.Date(2020, 11, 30, 10, 0, 0, 0, time.UTC)
+	status := engineStatus("interest-post.3", "interest-post", v1.EnginePhase_PHASE_DONE, true, created,
+		&v1.Annotation{Key: "month", Value: "2020-11"})
+
+	cases := []struct {
+		filters []string
+		want    bool
+	}{
+		{nil, true},
+		{[]string{"name==interest-post.3"}, true},
+		{[]string{"name==interest-post"}, false},
+		{[]string{"name!=interest-post"}, true},
+		{[]string{"spec^=interest-"}, true},
+		{[]string{"spec!^=interest-"}, false},
+		{[]string{"name$=.3"}, true},
+		{[]string{"name*=post"}, true},
+		{[]string{"phase==done"}, true},
+		{[]string{"phase==running|phase==done"}, true},
+		{[]string{"phase==done", "success==false"}, false},
+		{[]string{"conditions.success==true"}, true},
+		{[]string{"failure_count==0"}, true},
+		{[]string{"created^=2020-11-30"}, true},
+		{[]string{"annotation.month==2020-11"}, true},
+		{[]string{"metadata.annotations.month==2020-10"}, false},
+		{[]string{"annotation.day"}, false},
+		{[]string{"!annotation.day"}, true},
+		{[]string{"finished"}, false},
+		{[]string{"metadata.repository.repo==wallet"}, false},
+	}
+	for _, c := range cases {
+		var filters []*v1.FilterExpression
+		for _, text := range c.filters {
+			expr, err := Parse(text)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", text, err)
+			}
+			filters = append(filters, expr)
+		}
+
+		if err := Validate(filters, nil); err != nil {
+			t.Fatalf("Validate(%q) failed: %v", c.filters, err)
+		}
+		got, err := Match(status, filters)
+		if err != nil {
+			t.Fatalf("Match(%q) failed: %v", c.filters, err)
+		}
+		if got != c.want {
+			t.Errorf("Match(%q) = %v, want %v", c.filters, got, c.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, text := range []string{"", "==done", "phase = done", "phase==done|"} {
+		if expr, err := Parse(text); err == nil {
+			t.Errorf("Parse(%q) = %v, want an error", text, expr)
+		}
+	}
+
+	for _, text := range []string{"nope==1", "metadata==1", "metadata.created.seconds==1", "results==1"} {
+		expr, err := Parse(text)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", text, err)
+		}
+		if err := Validate([]*v1.FilterExpression{expr}, nil); err == nil {
+			t.Errorf("Validate(%q) succeeded, want an unknown field", text)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	day := time.Date(2020, 11, 1, 0, 0, 0, 0, time.UTC)
+	statuses := []*v1.EngineStatus{
+		engineStatus("reconcile.10", "reconcile", v1.EnginePhase_PHASE_DONE, true, day.AddDate(0, 0, 2)),
+		engineStatus("reconcile.9", "reconcile", v1.EnginePhase_PHASE_DONE, false, day),
+		engineStatus("interest-post.1", "interest-post", v1.EnginePhase_PHASE_DONE, true, day.AddDate(0, 0, 1)),
+	}
+	statuses[0].Conditions.FailureCount = 10
+	statuses[1].Conditions.FailureCount = 9
+
+	cases := []struct {
+		order []string
+		want  []string
+	}{
+		{[]string{"created:asc"}, []string{"reconcile.9", "interest-post.1", "reconcile.10"}},
+		{[]string{"failure_count"}, []string{"reconcile.10", "reconcile.9", "interest-post.1"}},
+		{[]string{"spec:asc", "created"}, []string{"interest-post.1", "reconcile.10", "reconcile.9"}},
+	}
+	for _, c := range cases {
+		var order []*v1.OrderExpression
+		for _, text := range c.order {
+			o, err := ParseOrder(text)
+			if err != nil {
+				t.Fatalf("ParseOrder(%q) failed: %v", text, err)
+			}
+			order = append(order, o)
+		}
+
+		sorted := append([]*v1.EngineStatus(nil), statuses...)
+		if err := Sort(sorted, order); err != nil {
+			t.Fatalf("Sort(%q) failed: %v", c.order, err)
+		}
+		for i, status := range sorted {
+			if status.Name != c.want[i] {
+				t.Errorf("Sort(%q)[%d] = %s, want %s", c.order, i, status.Name, c.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package filter
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+)
+
+// operators of the filter syntax, the longest first so that they are matched greedily
+var operators = []struct {
+	token     string
+	operation v1.FilterOp
+	negate    bool
+}{
+	{"!^=", v1.FilterOp_OP_STARTS_WITH, true},
+	{"!$=", v1.FilterOp_OP_ENDS_WITH, true},
+	{"!*=", v1.FilterOp_OP_CONTAINS, true},
+	{"==", v1.FilterOp_OP_EQUALS, false},
+	{"!=", v1.FilterOp_OP_EQUALS, true},
+	{"^=", v1.FilterOp_OP_STARTS_WITH, false},
+	{"$=", v1.FilterOp_OP_ENDS_WITH, false},
+	{"*=", v1.FilterOp_OP_CONTAINS, false},
+}
+
+// Parse parses a filter expression, terms separated by | of which any should match.
+// A term is a field, an operator and a value, such as phase==done or spec^=interest-.
+// The operators are == (equals), ^= (starts with), $= (ends with) and *= (contains),
+// negated by a leading !, as in != or !^=. A field on its own has to be set, !field not.
+func Parse(expr string) (*v1.FilterExpression, error) {
+	var res v1.FilterExpression
+	for _, text := range strings.Split(expr, "|") {
+		term, err := parseTerm(strings.TrimSpace(text))
+		if err != nil {
+			return nil, err
+		}
+		res.Terms = append(res.Terms, term)
+	}
+	return &res, nil
+}
+
+func parseTerm(text string) (*v1.FilterTerm, error) {
+	for i := 0; i < len(text); i++ {
+		for _, op := range operators {
+			if !strings.HasPrefix(text[i:], op.token) {
+				continue
+			}
+
+			field := strings.TrimSpace(text[:i])
+			if field == "" {
+				return nil, fmt.Errorf("filter term %q has no field", text)
+			}
+			return &v1.FilterTerm{
+				Field:     field,
+				Value:     strings.TrimSpace(text[i+len(op.token):]),
+				Operation: op.operation,
+				Negate:    op.negate,
+			}, nil
+		}
+	}
+
+	// a field on its own
+	field, negate := strings.TrimPrefix(text, "!"), strings.HasPrefix(text, "!")
+	if field == "" || strings.ContainsAny(field, " =") {
+		return nil, fmt.Errorf("filter term %q should be a field, an operator and a value, such as phase==done", text)
+	}
+	return &v1.FilterTerm{Field: field, Operation: v1.FilterOp_OP_EXISTS, Negate: negate}, nil
+}
+
+// ParseOrder parses an order, a field optionally followed by :asc or :desc (the default)
+func ParseOrder(text string) (*v1.OrderExpression, error) {
+	field, direction := text, "desc"
+	if i := strings.LastIndex(text, ":"); i >= 0 {
+		field, direction = text[:i], text[i+1:]
+	}
+
+	if field == "" {
+		return nil, fmt.Errorf("order %q has no field", text)
+	}
+	switch direction {
+	case "asc":
+		return &v1.OrderExpression{Field: field, Ascending: true}, nil
+	case "desc":
+		return &v1.OrderExpression{Field: field}, nil
+	}
+	return nil, fmt.Errorf("order %q should end in :asc or :desc", text)
+}
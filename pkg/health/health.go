@@ -0,0 +1,158 @@
+package health
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status is the status of the server, or of one of its components
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// checkTimeout bounds a check, a component that doesn't answer in time is down
+const checkTimeout = 5 * time.Second
+
+// Check tells whether a component of the server works, by returning nil
+type Check func(ctx context.Context) error
+
+// Report is the status of the server and of each of its components
+type Report struct {
+	Status     Status               `json:"status"`
+	Components map[string]Component `json:"components"`
+}
+
+// Up tells whether all the components are up
+func (r Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+// Component is the status of a component, with the reason it is down
+type Component struct {
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type component struct {
+	name  string
+	check Check
+	live  bool
+}
+
+// Checker checks the components of the server. The server is live when the
+// components it can't recover without a restart work, and ready when all its
+// components work.
+type Checker struct {
+	components   []component
+	shuttingDown int32
+}
+
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+// Add adds a component the server needs to be ready
+func (c *Checker) Add(name string, check Check) {
+	c.components = append(c.components, component{name: name, check: check})
+}
+
+// AddLive adds a component the server needs to be live, and ready
+func (c *Checker) AddLive(name string, check Check) {
+	c.components = append(c.components, component{name: name, check: check, live: true})
+}
+
+// Shutdown makes the server not ready, so that it gets no more requests
+// while it shuts down
+func (c *Checker) Shutdown() {
+	atomic.StoreInt32(&c.shuttingDown, 1)
+}
+
+// Live checks the components the server needs to be live
+func (c *Checker) Live(ctx context.Context) Report {
+	return c.run(ctx, true)
+}
+
+// Ready checks all the components
+func (c *Checker) Ready(ctx context.Context) Report {
+	report := c.run(ctx, false)
+	if atomic.LoadInt32(&c.shuttingDown) == 1 {
+		report.Status = StatusDown
+		report.Components["server"] = Component{Status: StatusDown, Error: "shutting down"}
+	}
+	return report
+}
+
+// run runs the checks concurrently, so that a slow component doesn't delay
+// the report past the timeout of the probe
+func (c *Checker) run(ctx context.Context, liveOnly bool) Report {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	report := Report{Status: StatusUp, Components: map[string]Component{}}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, comp := range c.components {
+		if liveOnly && !comp.live {
+			continue
+		}
+
+		wg.Add(1)
+		go func(comp component) {
+			defer wg.Done()
+			result := Component{Status: StatusUp}
+			if err := check(ctx, comp.check); err != nil {
+				result = Component{Status: StatusDown, Error: err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[comp.name] = result
+			if result.Status == StatusDown {
+				report.Status = StatusDown
+			}
+		}(comp)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// check runs a check until ctx ends, a check that ignores ctx is left behind
+func check(ctx context.Context, check Check) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- check(ctx)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,78 @@
+package health
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestChecker(t *testing.T) {
+	checker := NewChecker()
+	checker.AddLive("workers", func(ctx context.Context) error { return nil })
+	checker.Add("database", func(ctx context.Context) error { return fmt.Errorf("connection refused") })
+
+	live := checker.Live(context.Background())
+	if !live.Up() || len(live.Components) != 1 {
+		t.Errorf("Live() = %+v, want only the workers, up", live)
+	}
+
+	ready := checker.Ready(context.Background())
+	if ready.Up() {
+		t.Error("Ready() is up, want down with the database down")
+	}
+	if c := ready.Components["database"]; c.Status != StatusDown || c.Error != "connection refused" {
+		t.Errorf("database = %+v, want down with its error", c)
+	}
+	if c := ready.Components["workers"]; c.Status != StatusUp {
+		t.Errorf("workers = %+v, want up", c)
+	}
+}
+
+func TestCheckerShutdown(t *testing.T) {
+	checker := NewChecker()
+	checker.AddLive("workers", func(ctx context.Context) error { return nil })
+	checker.Shutdown()
+
+	if !checker.Live(context.Background()).Up() {
+		t.Error("Live() is down, want the server live while it shuts down")
+	}
+	if checker.Ready(context.Background()).Up() {
+		t.Error("Ready() is up, want the server not ready while it shuts down")
+	}
+}
+
+func TestCheckerTimeout(t *testing.T) {
+	checker := NewChecker()
+	block := make(chan struct{})
+	defer close(block)
+	checker.Add("stuck", func(ctx context.Context) error {
+		<-block
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if c := checker.Ready(ctx).Components["stuck"]; c.Status != StatusDown {
+		t.Errorf("stuck = %+v, want down once the context ends", c)
+	}
+}
@@ -0,0 +1,37 @@
+package helpers
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import "time"
+
+// DateLayout is how days are written, YYYY-MM-DD, in query parameters, flags and
+// the documents the wallet produces
+const DateLayout = "2006-01-02"
+
+// MonthLayout is how months are written, YYYY-MM
+const MonthLayout = "2006-01"
+
+// LastMonth returns midnight of the first day of the month before the one of now. Going
+// back a month from now itself would land in the same month on the 29th to the 31st,
+// March 31 less a month being March 3.
+func LastMonth(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location())
+}
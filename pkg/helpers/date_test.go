@@ -0,0 +1,44 @@
+package helpers
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLastMonth(t *testing.T) {
+	ist := time.FixedZone("IST", 5*3600+1800)
+	cases := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{time.Date(2022, 3, 31, 23, 59, 0, 0, ist), time.Date(2022, 2, 1, 0, 0, 0, 0, ist)},
+		{time.Date(2022, 3, 1, 0, 0, 0, 0, ist), time.Date(2022, 2, 1, 0, 0, 0, 0, ist)},
+		{time.Date(2022, 5, 31, 12, 0, 0, 0, ist), time.Date(2022, 4, 1, 0, 0, 0, 0, ist)},
+		{time.Date(2022, 1, 31, 12, 0, 0, 0, ist), time.Date(2021, 12, 1, 0, 0, 0, 0, ist)},
+	}
+	for _, c := range cases {
+		if got := LastMonth(c.now); !got.Equal(c.want) || got.Location() != ist {
+			t.Errorf("LastMonth(%v) = %v, want %v", c.now, got, c.want)
+		}
+	}
+}
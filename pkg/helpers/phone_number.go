@@ -0,0 +1,72 @@
+package helpers
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"strings"
+
+	"github.com/bhojpur/wallet/pkg/errors"
+)
+
+const (
+	minPhoneNumberDigits = 8
+	maxPhoneNumberDigits = 15 // maximum length of an E.164 number
+)
+
+// NormalizePhoneNumber formats a phone number into its E.164 representation i.e.
+// a leading '+' followed by the country code and subscriber number. Spaces, dashes,
+// dots and brackets are stripped and an international "00" prefix is replaced by '+'.
+// Numbers without a prefix are assumed to already start with the country code.
+func NormalizePhoneNumber(phone string) (string, error) {
+	number := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, phone)
+
+	if strings.HasPrefix(number, "00") {
+		number = number[2:]
+	} else {
+		number = strings.TrimPrefix(number, "+")
+	}
+
+	if len(number) < minPhoneNumberDigits || len(number) > maxPhoneNumberDigits || number[0] == '0' {
+		return "", errors.Error{Code: errors.EINVALID, Message: errors.ErrInvalidPhoneNumber}
+	}
+	for _, c := range number {
+		if c < '0' || c > '9' {
+			return "", errors.Error{Code: errors.EINVALID, Message: errors.ErrInvalidPhoneNumber}
+		}
+	}
+
+	return "+" + number, nil
+}
+
+// NormalizeOptionalPhoneNumber normalizes a phone number that may be left out, an
+// empty phone number stays empty
+func NormalizeOptionalPhoneNumber(phone string) (string, error) {
+	if phone == "" {
+		return "", nil
+	}
+	return NormalizePhoneNumber(phone)
+}
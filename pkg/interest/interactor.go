@@ -0,0 +1,265 @@
+package interest
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/account"
+	"github.com/bhojpur/wallet/pkg/customer"
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/helpers"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/statement"
+	"github.com/bhojpur/wallet/pkg/worker"
+
+	"github.com/gofrs/uuid"
+)
+
+// how often the interest job looks for days that have not accrued and months that have not been posted
+const jobCheckInterval = time.Hour
+
+type Interactor interface {
+	GetRates() ([]Rate, error)
+	SetRate(params RateParams) (Rate, error)
+	GetAccruals(accountNumber string, from, to time.Time) ([]Accrual, error)
+
+	AccrueDay(day time.Time) (int, error)
+	PostMonth(month time.Time) (int, error)
+}
+
+func NewInteractor(repository Repository, accRepo account.Repository, statementRepo statement.Repository, accountant account.Accountant, finder customer.Finder, workers *worker.Group) Interactor {
+	intr := &interactor{
+		repository:     repository,
+		accRepo:        accRepo,
+		statementRepo:  statementRepo,
+		accountant:     accountant,
+		customerFinder: finder,
+	}
+
+	workers.Go("interest job", intr.runInterestJob)
+
+	return intr
+}
+
+type interactor struct {
+	repository     Repository
+	accRepo        account.Repository
+	statementRepo  statement.Repository
+	accountant     account.Accountant
+	customerFinder customer.Finder
+}
+
+func (i interactor) GetRates() ([]Rate, error) {
+	return i.repository.GetRates()
+}
+
+// SetRate configures the annual interest rate paid on a product
+func (i interactor) SetRate(params RateParams) (Rate, error) {
+	return i.repository.SetRate(params.AccountType, params.AnnualRateBps)
+}
+
+// GetAccruals lists the daily accruals of a customer's account, for auditing
+func (i interactor) GetAccruals(accountNumber string, from, to time.Time) ([]Accrual, error) {
+	usr, err := i.customerFinder.FindUser(accountNumber, "")
+	if err != nil {
+		return nil, err
+	}
+
+	acc, err := i.accRepo.GetAccountByUserID(usr.UserID)
+	if errors.ErrorCode(err) == errors.ENOTFOUND {
+		return nil, errors.Error{Message: errors.AccountNotCreated, Err: err}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return i.repository.GetAccruals(acc.ID, from, to)
+}
+
+// AccrueDay records the interest earned on the end-of-day balance of the given day by every
+// account whose product pays interest. It can be re-run for a day, accounts that have already
+// accrued interest for the day are left alone. It returns the number of accruals recorded.
+func (i interactor) AccrueDay(day time.Time) (int, error) {
+	day = startOfDay(day)
+	endOfDay := day.AddDate(0, 0, 1)
+	if endOfDay.After(time.Now()) {
+		return 0, errors.Error{Code: errors.EINVALID, Message: errors.ErrDayNotOver}
+	}
+
+	rates, err := i.repository.GetRates()
+	if err != nil {
+		return 0, err
+	}
+
+	var recorded int
+	for _, rate := range rates {
+		if rate.AnnualRateBps == 0 {
+			continue
+		}
+
+		accounts, err := i.accRepo.GetAccountsByType(rate.AccountType)
+		if err != nil {
+			return recorded, err
+		}
+
+		for _, acc := range accounts {
+			if !acc.CreatedAt.Before(endOfDay) {
+				continue
+			}
+
+			balance, err := i.statementRepo.GetBalanceAt(acc.ID, endOfDay)
+			if err != nil {
+				return recorded, err
+			}
+			if balance == 0 {
+				continue
+			}
+
+			added, err := i.repository.AddAccrual(Accrual{
+				AccountID:       acc.ID,
+				UserID:          acc.UserID,
+				Day:             day,
+				EndOfDayBalance: balance,
+				AnnualRateBps:   rate.AnnualRateBps,
+				Amount:          DailyInterest(balance, rate.AnnualRateBps),
+			})
+			if err != nil {
+				return recorded, err
+			}
+			if added {
+				recorded++
+			}
+		}
+	}
+
+	return recorded, nil
+}
+
+// PostMonth credits the interest accrued up to the end of the given month from the interest
+// expense system account. Accruals are marked posted so a re-run credits nothing twice, and
+// accruals recorded late for an earlier month are picked up. Only whole Paisas are paid, the
+// fraction left over is carried into the next posting. It returns the number of accounts credited.
+func (i interactor) PostMonth(month time.Time) (int, error) {
+	before := startOfMonth(month).AddDate(0, 1, 0)
+	if before.After(time.Now()) {
+		return 0, errors.Error{Code: errors.EINVALID, Message: errors.ErrMonthNotOver}
+	}
+
+	expense, err := i.accRepo.CreateSystemAccount(models.SystemInterestExpenseUserID, models.AccTypeExpense)
+	if err != nil {
+		return 0, err
+	}
+
+	accountIDs, err := i.repository.GetUnpostedAccounts(before)
+	if err != nil {
+		return 0, err
+	}
+
+	var credited int
+	for _, accountID := range accountIDs {
+		// postgres keeps timestamps to the microsecond, the claim is looked up by it
+		postedAt := time.Now().Truncate(time.Microsecond)
+
+		total, err := i.repository.ClaimAccruals(accountID, before, postedAt)
+		if err != nil {
+			return credited, err
+		}
+
+		// less than a Paisa is left unposted, it is paid once it adds up
+		amount := models.Paisas(math.Floor(total))
+		if amount == 0 {
+			i.releaseAccruals(accountID, postedAt)
+			continue
+		}
+
+		acc, err := i.accRepo.GetAccountByID(accountID)
+		if err == nil {
+			err = i.creditInterest(expense, acc, amount)
+		}
+		if err != nil {
+			log.Printf("error happened while crediting interest to account %v: %v", accountID, err)
+			i.releaseAccruals(accountID, postedAt)
+			continue
+		}
+		credited++
+
+		if remainder := total - float64(amount); remainder > 0 {
+			carried := Accrual{AccountID: acc.ID, UserID: acc.UserID, Day: before, Amount: remainder, Carried: true}
+			if _, err := i.repository.AddAccrual(carried); err != nil {
+				log.Printf("error happened while carrying %v Paisas of interest of account %v: %v", remainder, accountID, err)
+			}
+		}
+	}
+
+	return credited, nil
+}
+
+// releaseAccruals undoes a claim so that the accruals are posted later
+func (i interactor) releaseAccruals(accountID uuid.UUID, postedAt time.Time) {
+	if err := i.repository.ReleaseAccruals(accountID, postedAt); err != nil {
+		log.Printf("error happened while releasing interest accruals of account %v: %v", accountID, err)
+	}
+}
+
+// creditInterest moves interest from the expense system account to the customer's account
+func (i interactor) creditInterest(expense models.Account, acc models.Account, amount models.Paisas) error {
+	// each side of the movement names the other as its counterparty
+	customer := models.TxnCustomer{UserID: acc.UserID}
+	system := models.TxnCustomer{UserID: expense.UserID, UserType: models.UserTypSystem}
+
+	_, err := i.accountant.DebitAccount(expense.UserID, amount, models.TxnOpInterest, customer)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.accountant.CreditAccount(acc.UserID, amount, models.TxnOpInterest, system)
+	if err != nil {
+		// put the interest back so that the expense account matches what was paid
+		if _, e := i.accountant.CreditAccount(expense.UserID, amount, models.TxnOpInterest, customer); e != nil {
+			log.Printf("error happened while reversing interest expense of %v: %v", amount, e)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (i interactor) runInterestJob(done <-chan struct{}) {
+	ticker := time.NewTicker(jobCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now()
+		if _, err := i.AccrueDay(now.AddDate(0, 0, -1)); err != nil {
+			log.Printf("error happened while accruing interest %v", err)
+		}
+		if _, err := i.PostMonth(helpers.LastMonth(now)); err != nil {
+			log.Printf("error happened while posting interest %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package interest
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/account"
+	"github.com/bhojpur/wallet/pkg/models"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestPostMonthCarriesFractions(t *testing.T) {
+	acc := models.Account{ID: uuid.Must(uuid.NewV4()), UserID: uuid.Must(uuid.NewV4())}
+	repository := &memoryRepository{}
+	accountant := &memoryAccountant{credited: map[uuid.UUID]models.Paisas{}}
+	i := interactor{repository: repository, accRepo: memoryAccounts{accounts: []models.Account{acc}}, accountant: accountant}
+
+	// fractions chosen to add up exactly, so that the test doesn't depend on rounding
+	earned := map[time.Month][]float64{
+		time.January:  {0.75, 0.75},
+		time.February: {0.25},
+		time.March:    {0.5},
+	}
+	months := []struct {
+		month time.Month
+		// what the account is credited with, in Paisas
+		credited models.Paisas
+	}{
+		{time.January, 1},  // 1.5 earned, 0.5 carried
+		{time.February, 0}, // 0.75 is less than a Paisa, it waits
+		{time.March, 1},    // 1.25, 0.25 carried
+	}
+
+	var total float64
+	for _, m := range months {
+		for day, amount := range earned[m.month] {
+			repository.add(Accrual{
+				AccountID: acc.ID,
+				UserID:    acc.UserID,
+				Day:       time.Date(2022, m.month, day+1, 0, 0, 0, 0, time.Local),
+				Amount:    amount,
+			})
+			total += amount
+		}
+
+		before := accountant.credited[acc.UserID]
+		if _, err := i.PostMonth(time.Date(2022, m.month, 1, 0, 0, 0, 0, time.Local)); err != nil {
+			t.Fatalf("PostMonth(%v) failed: %v", m.month, err)
+		}
+		if got := accountant.credited[acc.UserID] - before; got != m.credited {
+			t.Errorf("PostMonth(%v) credited %v Paisas, want %v", m.month, got, m.credited)
+		}
+	}
+
+	// what was credited and what is still to be posted add up to what was earned
+	var unposted float64
+	for _, accrual := range repository.accruals {
+		if accrual.PostedAt == nil {
+			unposted += accrual.Amount
+		}
+	}
+	credited := float64(accountant.credited[acc.UserID])
+	if math.Abs(credited+unposted-total) > 1e-9 {
+		t.Errorf("credited %v and %v unposted Paisas, want %v in all", credited, unposted, total)
+	}
+	if unposted != 0.25 {
+		t.Errorf("%v Paisas are unposted, want 0.25 carried", unposted)
+	}
+}
+
+type memoryRepository struct {
+	Repository
+	accruals []Accrual
+}
+
+func (r *memoryRepository) add(accrual Accrual) {
+	accrual.ID = uuid.Must(uuid.NewV4())
+	r.accruals = append(r.accruals, accrual)
+}
+
+func (r *memoryRepository) AddAccrual(accrual Accrual) (bool, error) {
+	for _, a := range r.accruals {
+		if !accrual.Carried && !a.Carried && a.AccountID == accrual.AccountID && a.Day.Equal(accrual.Day) {
+			return false, nil
+		}
+	}
+	r.add(accrual)
+	return true, nil
+}
+
+func (r *memoryRepository) GetUnpostedAccounts(before time.Time) ([]uuid.UUID, error) {
+	seen := map[uuid.UUID]bool{}
+	var accountIDs []uuid.UUID
+	for _, a := range r.accruals {
+		if a.PostedAt == nil && a.Day.Before(before) && !seen[a.AccountID] {
+			seen[a.AccountID] = true
+			accountIDs = append(accountIDs, a.AccountID)
+		}
+	}
+	return accountIDs, nil
+}
+
+func (r *memoryRepository) ClaimAccruals(accountID uuid.UUID, before, postedAt time.Time) (float64, error) {
+	var total float64
+	for i, a := range r.accruals {
+		if a.AccountID == accountID && a.PostedAt == nil && a.Day.Before(before) {
+			r.accruals[i].PostedAt = &postedAt
+			total += a.Amount
+		}
+	}
+	return total, nil
+}
+
+func (r *memoryRepository) ReleaseAccruals(accountID uuid.UUID, postedAt time.Time) error {
+	for i, a := range r.accruals {
+		if a.AccountID == accountID && a.PostedAt != nil && a.PostedAt.Equal(postedAt) {
+			r.accruals[i].PostedAt = nil
+		}
+	}
+	return nil
+}
+
+// memoryAccounts only answers what posting interest asks of the account repository
+type memoryAccounts struct {
+	account.Repository
+	accounts []models.Account
+}
+
+func (r memoryAccounts) GetAccountByID(id uuid.UUID) (models.Account, error) {
+	for _, acc := range r.accounts {
+		if acc.ID == id {
+			return acc, nil
+		}
+	}
+	return models.Account{}, nil
+}
+
+func (memoryAccounts) CreateSystemAccount(userID uuid.UUID, accType models.AccountType) (models.Account, error) {
+	return models.Account{ID: userID, UserID: userID, AccountType: accType}, nil
+}
+
+type memoryAccountant struct {
+	account.Accountant
+	credited map[uuid.UUID]models.Paisas
+}
+
+func (a *memoryAccountant) DebitAccount(userID uuid.UUID, amount models.Paisas, _ models.TxnOperation, _ models.TxnCustomer) (float64, error) {
+	a.credited[userID] -= amount
+	return 0, nil
+}
+
+func (a *memoryAccountant) CreditAccount(userID uuid.UUID, amount models.Paisas, _ models.TxnOperation, _ models.TxnCustomer) (float64, error) {
+	a.credited[userID] += amount
+	return 0, nil
+}
@@ -0,0 +1,103 @@
+package interest
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// daysInYear is the day count used to turn an annual rate into a daily one
+const daysInYear = 365
+
+// Rate is the annual interest rate paid on a product. Products are
+// told apart by their account type.
+type Rate struct {
+	ID          uuid.UUID
+	AccountType models.AccountType `gorm:"column:account_type;not null;unique"`
+	// annual interest rate in basis points, 250 is 2.5%
+	AnnualRateBps uint `gorm:"column:annual_rate_bps"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (r *Rate) BeforeCreate(tx *gorm.DB) error {
+	r.ID, _ = uuid.NewV4()
+	return nil
+}
+
+func (Rate) TableName() string {
+	return "interest_rates"
+}
+
+// Accrual records the interest earned by an account on a single day. An account
+// accrues interest once a day, it is credited to the account in a monthly posting.
+type Accrual struct {
+	ID        uuid.UUID
+	AccountID uuid.UUID `gorm:"column:account_id;not null;uniqueIndex:idx_interest_accrual_account_day,where:carried = false"`
+	UserID    uuid.UUID `gorm:"column:user_id;not null"`
+	Day       time.Time `gorm:"column:day;type:date;not null;uniqueIndex:idx_interest_accrual_account_day"`
+
+	// what the interest was worked out from
+	EndOfDayBalance models.Paisas `gorm:"column:end_of_day_balance"`
+	AnnualRateBps   uint          `gorm:"column:annual_rate_bps"`
+	// interest earned in Paisas, the fraction is kept so that the
+	// monthly posting adds up to what was earned
+	Amount float64 `gorm:"column:amount"`
+
+	// a carried accrual holds the fraction of a Paisa left over by a posting, it is
+	// dated the first day of the next month and paid with the next posting
+	Carried bool `gorm:"column:carried;not null;default:false"`
+
+	// set when the interest has been credited to the account
+	PostedAt *time.Time `gorm:"column:posted_at;index"`
+
+	CreatedAt time.Time
+}
+
+func (a *Accrual) BeforeCreate(tx *gorm.DB) error {
+	a.ID, _ = uuid.NewV4()
+	return nil
+}
+
+func (Accrual) TableName() string {
+	return "interest_accruals"
+}
+
+// DailyInterest is the interest in Paisas earned in a day on a balance
+func DailyInterest(balance models.Paisas, annualRateBps uint) float64 {
+	return float64(balance) * float64(annualRateBps) / 10000 / daysInYear
+}
+
+// startOfDay truncates t to midnight in its location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// startOfMonth truncates t to midnight of the first day of its month
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,82 @@
+package interest
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/helpers"
+	"github.com/bhojpur/wallet/pkg/models"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// RateParams are properties required when an admin sets the interest rate of a product
+type RateParams struct {
+	AccountType models.AccountType `json:"accountType" schema:"accountType" form:"accountType"`
+	// annual interest rate in basis points
+	AnnualRateBps uint `json:"rate" schema:"rate" form:"rate"`
+}
+
+func (req RateParams) Validate() error {
+	err := validation.ValidateStruct(&req,
+		validation.Field(&req.AccountType,
+			validation.Required.Error(string(errors.ErrorAccountTypeRequired)),
+			validation.In(models.AccTypeSavings, models.AccTypeCurrent, models.AccTypeUtility).Error(string(errors.ErrorInvalidAccountType)),
+		),
+		validation.Field(&req.AnnualRateBps, validation.Max(uint(10000)).Error(string(errors.ErrorInvalidInterestRate))),
+	)
+
+	return errors.ParseValidationErrorMap(err)
+}
+
+// AccrualsParams are the query parameters used to audit the accruals of an account. The
+// days default to the current month.
+type AccrualsParams struct {
+	AccountNumber string `query:"accountNo"`
+	From          string `query:"from"`
+	To            string `query:"to"`
+}
+
+func (req AccrualsParams) Validate() error {
+	err := validation.ValidateStruct(&req,
+		validation.Field(&req.AccountNumber, validation.Required.Error(string(errors.ErrorAccountNumberRequired))),
+		validation.Field(&req.From, validation.Date(helpers.DateLayout).Error(string(errors.ErrorInvalidDate))),
+		validation.Field(&req.To, validation.Date(helpers.DateLayout).Error(string(errors.ErrorInvalidDate))),
+	)
+
+	return errors.ParseValidationErrorMap(err)
+}
+
+// Days returns the first and last day accruals are listed for
+func (req AccrualsParams) Days() (from, to time.Time) {
+	now := time.Now()
+	from, to = startOfMonth(now), startOfDay(now)
+
+	if req.From != "" {
+		from, _ = time.ParseInLocation(helpers.DateLayout, req.From, now.Location())
+	}
+	if req.To != "" {
+		to, _ = time.ParseInLocation(helpers.DateLayout, req.To, now.Location())
+	}
+	return from, to
+}
@@ -0,0 +1,158 @@
+package interest
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/storage"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	GetRates() ([]Rate, error)
+	SetRate(accType models.AccountType, annualRateBps uint) (Rate, error)
+
+	AddAccrual(Accrual) (bool, error)
+	GetAccruals(accountID uuid.UUID, from, to time.Time) ([]Accrual, error)
+	GetUnpostedAccounts(before time.Time) ([]uuid.UUID, error)
+	ClaimAccruals(accountID uuid.UUID, before, postedAt time.Time) (float64, error)
+	ReleaseAccruals(accountID uuid.UUID, postedAt time.Time) error
+}
+
+func NewRepository(database *storage.Database) Repository {
+	return &repository{db: database}
+}
+
+type repository struct {
+	db *storage.Database
+}
+
+// GetRates fetches the interest rates of all products
+func (r repository) GetRates() ([]Rate, error) {
+	var rates []Rate
+	result := r.db.Order("account_type").Find(&rates)
+	if err := result.Error; err != nil {
+		return nil, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return rates, nil
+}
+
+// SetRate adds or updates the interest rate of a product
+func (r repository) SetRate(accType models.AccountType, annualRateBps uint) (Rate, error) {
+	rate := Rate{AccountType: accType, AnnualRateBps: annualRateBps}
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"annual_rate_bps", "updated_at"}),
+	}).Create(&rate)
+	if err := result.Error; err != nil {
+		return Rate{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	result = r.db.Where(Rate{AccountType: accType}).First(&rate)
+	if err := result.Error; err != nil {
+		return Rate{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return rate, nil
+}
+
+// AddAccrual records an accrual, it returns false if the account
+// has already accrued interest for the day
+func (r repository) AddAccrual(accrual Accrual) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&accrual)
+	if err := result.Error; err != nil {
+		return false, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// GetAccruals fetches the accruals of an account for the days in [from, to]
+func (r repository) GetAccruals(accountID uuid.UUID, from, to time.Time) ([]Accrual, error) {
+	var accruals []Accrual
+	result := r.db.Where(
+		Accrual{AccountID: accountID},
+	).Where(
+		"day >= ? AND day <= ?", from, to,
+	).Order("day").Find(&accruals)
+	if err := result.Error; err != nil {
+		return nil, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return accruals, nil
+}
+
+// GetUnpostedAccounts fetches the accounts with interest accrued before
+// the given day that has not yet been credited
+func (r repository) GetUnpostedAccounts(before time.Time) ([]uuid.UUID, error) {
+	var accountIDs []uuid.UUID
+	result := r.db.Model(Accrual{}).
+		Where("posted_at IS NULL AND day < ?", before).
+		Distinct().Pluck("account_id", &accountIDs)
+	if err := result.Error; err != nil {
+		return nil, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return accountIDs, nil
+}
+
+// ClaimAccruals marks the account's unposted accruals before the given day as posted at
+// postedAt and returns their total. Claiming makes sure interest is only credited once
+// even if postings run concurrently.
+func (r repository) ClaimAccruals(accountID uuid.UUID, before, postedAt time.Time) (float64, error) {
+	var total float64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(Accrual{}).
+			Where("account_id = ? AND posted_at IS NULL AND day < ?", accountID, before).
+			Update("posted_at", postedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		return tx.Model(Accrual{}).
+			Where("account_id = ? AND posted_at = ?", accountID, postedAt).
+			Select("COALESCE(SUM(amount), 0)").Scan(&total).Error
+	})
+	if err != nil {
+		return 0, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return total, nil
+}
+
+// ReleaseAccruals undoes a claim whose interest could not be credited
+func (r repository) ReleaseAccruals(accountID uuid.UUID, postedAt time.Time) error {
+	result := r.db.Model(Accrual{}).
+		Where("account_id = ? AND posted_at = ?", accountID, postedAt).
+		Update("posted_at", nil)
+	if err := result.Error; err != nil {
+		return errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return nil
+}
@@ -68,11 +68,18 @@ func (ui interactor) AuthenticateByEmail(email, password string) (models.Merchan
 // Register takes in a merchant registration parameters and creates a new merchant
 // then adds the merchant to db.
 func (ui interactor) Register(params RegistrationParams) (models.Merchant, error) {
+	// phone numbers are kept in E.164 format so that customers
+	// can be found by them regardless of how they were typed
+	phoneNumber, err := helpers.NormalizeOptionalPhoneNumber(params.PhoneNumber)
+	if err != nil {
+		return models.Merchant{}, err
+	}
+
 	merchant := models.Merchant{
 		FirstName:   params.FirstName,
 		LastName:    params.LastName,
 		Email:       params.Email,
-		PhoneNumber: params.PhoneNumber,
+		PhoneNumber: phoneNumber,
 		Password:    params.Password,
 		PassportNo:  params.PassportNo,
 	}
@@ -85,7 +92,17 @@ func (ui interactor) Register(params RegistrationParams) (models.Merchant, error
 
 	// change password to hashed string
 	merchant.Password = passwordHash
-	merch, err := ui.repository.Add(merchant)
+
+	// generate a till number, and try again with another one
+	// in the unlikely case it has already been assigned
+	var merch models.Merchant
+	for attempt := 0; attempt < models.NumberGenerationAttempts; attempt++ {
+		merchant.TillNumber = models.NewTillNumber()
+		merch, err = ui.repository.Add(merchant)
+		if errors.ErrorMessage(err) != string(errors.ErrNumberTaken) {
+			break
+		}
+	}
 	if err != nil {
 		return models.Merchant{}, err
 	}
@@ -100,5 +117,5 @@ func (ui interactor) Register(params RegistrationParams) (models.Merchant, error
 // like creating an account for them automatically.
 func (ui interactor) postNewMerchantToChannel(merchant *models.Merchant) {
 	newMerchant := parseToNewMerchant(*merchant)
-	go func() { ui.customersChannel.Writer <- newMerchant }()
+	ui.customersChannel.Send(newMerchant)
 }
@@ -24,9 +24,9 @@ import (
 	"github.com/bhojpur/wallet/pkg/errors"
 	"github.com/bhojpur/wallet/pkg/models"
 	"github.com/bhojpur/wallet/pkg/storage"
+	"github.com/bhojpur/wallet/pkg/user"
 
 	"github.com/gofrs/uuid"
-	"github.com/jackc/pgconn"
 	"gorm.io/gorm"
 )
 
@@ -36,6 +36,8 @@ type Repository interface {
 	FetchAll() ([]models.Merchant, error)
 	FindByID(uuid.UUID) (models.Merchant, error)
 	FindByEmail(string) (models.Merchant, error)
+	FindByPhoneNumber(string) (models.Merchant, error)
+	FindByTillNumber(string) (models.Merchant, error)
 	Update(models.Merchant) error
 }
 
@@ -61,27 +63,31 @@ func (r repository) searchBy(row models.Merchant) (models.Merchant, error) {
 	return merchant, nil
 }
 
-// Add a merchant if already not in db.
+// Add a merchant if not already in db.
 func (r repository) Add(merchant models.Merchant) (models.Merchant, error) {
-	// add new merchant to merchants table, if query return violation of unique key column,
-	// we know that the merchant with given record exists and return that merchant instead
-	result := r.db.Model(models.Merchant{}).Create(&merchant)
-	if err := result.Error; err != nil {
-		// we check if the error is a postgres unique constraint violation
-		if pgerr, ok := err.(*pgconn.PgError); ok && pgerr.Code == "23505" {
-			return merchant, errors.Error{Code: errors.ECONFLICT, Message: errors.ErrUserExists}
+	err := user.Register(r.db, &merchant, func() models.User {
+		return models.NewUser(merchant.ID, models.UserTypMerchant, merchant.Email, merchant.PhoneNumber, merchant.TillNumber)
+	}, "till_number")
+	if err != nil {
+		if errors.ErrorCode(err) == errors.ECONFLICT {
+			return merchant, err
 		}
-		return models.Merchant{}, errors.Error{Err: result.Error, Code: errors.EINTERNAL}
+		return models.Merchant{}, err
 	}
 
 	return merchant, nil
 }
 
-// Delete a merchant
+// Delete a merchant and their entry in the users directory
 func (r repository) Delete(merchant models.Merchant) error {
-	result := r.db.Delete(&merchant)
-	if result.Error != nil {
-		return errors.Error{Err: result.Error, Code: errors.EINTERNAL}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&merchant).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", merchant.ID).Delete(&models.User{}).Error
+	})
+	if err != nil {
+		return errors.Error{Err: err, Code: errors.EINTERNAL}
 	}
 	return nil
 }
@@ -114,6 +120,18 @@ func (r repository) FindByEmail(email string) (models.Merchant, error) {
 	return merchant, err
 }
 
+// FindByPhoneNumber searches merchant by phone number
+func (r repository) FindByPhoneNumber(phone string) (models.Merchant, error) {
+	merchant, err := r.searchBy(models.Merchant{PhoneNumber: phone})
+	return merchant, err
+}
+
+// FindByTillNumber searches merchant by till number
+func (r repository) FindByTillNumber(tillNumber string) (models.Merchant, error) {
+	merchant, err := r.searchBy(models.Merchant{TillNumber: tillNumber})
+	return merchant, err
+}
+
 // Update
 func (r repository) Update(merchant models.Merchant) error {
 	var merch models.Merchant
@@ -0,0 +1,96 @@
+package metrics
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"log"
+
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/storage"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// floatQuery totals the float admins assigned to super agents, and the money
+// the accounts of the customers hold, leaving out the system accounts. Statement entries are rounded to paisas
+// one by one as that is how they were recorded.
+const floatQuery = `
+SELECT
+	(SELECT COALESCE(SUM(ROUND(credit_amount * 100)), 0)::bigint FROM statements
+		WHERE operation = ?) AS issued,
+	(SELECT COALESCE(SUM(available_balance), 0)::bigint FROM accounts
+		WHERE deleted_at IS NULL AND account_type NOT IN ?) AS in_circulation`
+
+// systemAccountTypes are the types of the accounts the wallet keeps for itself
+var systemAccountTypes = []models.AccountType{models.AccTypeExpense, models.AccTypeIncome}
+
+// WatchDatabase reports the stats of the connection pool of the database, and
+// the float issued against the float in circulation
+func (m *Metrics) WatchDatabase(database *storage.Database) error {
+	db, err := database.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	if err := m.registry.Register(collectors.NewDBStatsCollector(db, namespace)); err != nil {
+		return err
+	}
+	return m.registry.Register(newFloatCollector(database))
+}
+
+// floatCollector queries the float on every scrape, so that it is never stale
+type floatCollector struct {
+	database *storage.Database
+	desc     *prometheus.Desc
+}
+
+func newFloatCollector(database *storage.Database) *floatCollector {
+	return &floatCollector{
+		database: database,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "float_rupees"),
+			"Float assigned to super agents (issued), and money the accounts of the customers hold (in_circulation), in rupees.",
+			[]string{"state"}, nil,
+		),
+	}
+}
+
+func (c *floatCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *floatCollector) Collect(ch chan<- prometheus.Metric) {
+	var float struct {
+		Issued        models.Paisas
+		InCirculation models.Paisas
+	}
+	err := c.database.Raw(floatQuery, models.TxnFloatAssignment, systemAccountTypes).Scan(&float).Error
+	if err != nil {
+		// a scrape without the float shows in the gaps of the gauge
+		log.Printf("error collecting the float metrics %v", err)
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float.Issued.ToFloat(), "issued")
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float.InCirculation.ToFloat(), "in_circulation")
+}
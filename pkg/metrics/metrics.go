@@ -0,0 +1,136 @@
+package metrics
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the names of the metrics of the wallet
+const namespace = "wallet"
+
+// Metrics collects the metrics of the server, for Prometheus to scrape
+type Metrics struct {
+	registry *prometheus.Registry
+
+	transactions        *prometheus.CounterVec
+	transactionAmounts  *prometheus.CounterVec
+	transactionFees     *prometheus.CounterVec
+	transactionDuration *prometheus.HistogramVec
+	transactionFailures *prometheus.CounterVec
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+}
+
+// New returns the metrics of the server, with those of the Go runtime and of
+// the process
+func New() *Metrics {
+	transactionLabels := []string{"operation", "source_type", "destination_type"}
+
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		transactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transactions_total",
+			Help:      "Transactions completed.",
+		}, transactionLabels),
+		transactionAmounts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transaction_amount_rupees_total",
+			Help:      "Amount moved by the transactions completed, in rupees.",
+		}, transactionLabels),
+		transactionFees: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transaction_fees_rupees_total",
+			Help:      "Fees charged on the transactions completed, in rupees.",
+		}, transactionLabels),
+		transactionDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "transaction_duration_seconds",
+			Help:      "Time taken by transactions, completed or failed.",
+			Buckets:   prometheus.DefBuckets,
+		}, transactionLabels),
+		transactionFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transaction_failures_total",
+			Help:      "Transactions failed, by error code.",
+		}, append(transactionLabels, "code")),
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Requests to the REST API, by route and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to answer requests to the REST API, by route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.transactions,
+		m.transactionAmounts,
+		m.transactionFees,
+		m.transactionDuration,
+		m.transactionFailures,
+		m.httpRequests,
+		m.httpDuration,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a request to the REST API. The route is the path the
+// request matched, with its parameters, such as /api/account/pockets/:pocket_id.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// WatchChannel reports how many items a background channel holds, the
+// listener of the channel is behind when they pile up
+func (m *Metrics) WatchChannel(name string, pending func() int) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "channel_pending",
+		Help:        "Items a background channel holds, waiting for its listener.",
+		ConstLabels: prometheus.Labels{"channel": name},
+	}, func() float64 {
+		return float64(pending())
+	}))
+}
@@ -0,0 +1,62 @@
+package metrics
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/transaction"
+)
+
+// NewTransactor returns a transactor that records the metrics of the
+// transactions the given transactor makes
+func NewTransactor(next transaction.Transactor, metrics *Metrics) transaction.Transactor {
+	return &transactor{next: next, metrics: metrics}
+}
+
+type transactor struct {
+	next    transaction.Transactor
+	metrics *Metrics
+}
+
+func (tr transactor) Transact(txn transaction.Transaction) (transaction.Completed, error) {
+	start := time.Now()
+	completed, err := tr.next.Transact(txn)
+
+	labels := []string{
+		string(txn.TxnOperation),
+		string(txn.Source.UserType),
+		string(txn.Destination.UserType),
+	}
+	tr.metrics.transactionDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	if err != nil {
+		code := string(errors.ErrorCode(err))
+		tr.metrics.transactionFailures.WithLabelValues(append(labels, code)...).Inc()
+		return completed, err
+	}
+
+	tr.metrics.transactions.WithLabelValues(labels...).Inc()
+	tr.metrics.transactionAmounts.WithLabelValues(labels...).Add(txn.Amount.ToPaisas().ToFloat())
+	tr.metrics.transactionFees.WithLabelValues(labels...).Add(completed.Charge.ToFloat())
+
+	return completed, nil
+}
@@ -0,0 +1,84 @@
+package metrics
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/transaction"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeTransactor struct {
+	charge models.Paisas
+	err    error
+}
+
+func (f fakeTransactor) Transact(txn transaction.Transaction) (transaction.Completed, error) {
+	if f.err != nil {
+		return transaction.Completed{}, f.err
+	}
+	return transaction.Completed{Transaction: txn, Charge: f.charge}, nil
+}
+
+func TestTransactor(t *testing.T) {
+	m := New()
+	txn := transaction.Transaction{
+		Source:       models.TxnCustomer{UserType: models.UserTypSubscriber},
+		Destination:  models.TxnCustomer{UserType: models.UserTypAgent},
+		TxnOperation: models.TxnOpWithdraw,
+		Amount:       models.Rupees(500),
+	}
+
+	completing := NewTransactor(fakeTransactor{charge: models.Paisas(1250)}, m)
+	for i := 0; i < 2; i++ {
+		if _, err := completing.Transact(txn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	failing := NewTransactor(fakeTransactor{err: errors.Error{Code: errors.EINVALID}}, m)
+	if _, err := failing.Transact(txn); err == nil {
+		t.Fatal("no error, want the error of the transactor")
+	}
+
+	labels := []string{"WITHDRAW", "subscriber", "agent"}
+	for _, c := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"transactions", testutil.ToFloat64(m.transactions.WithLabelValues(labels...)), 2},
+		{"amount", testutil.ToFloat64(m.transactionAmounts.WithLabelValues(labels...)), 1000},
+		{"fees", testutil.ToFloat64(m.transactionFees.WithLabelValues(labels...)), 25},
+		{"failures", testutil.ToFloat64(m.transactionFailures.WithLabelValues(append(labels, "invalid")...)), 1},
+	} {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+
+	if n := testutil.CollectAndCount(m.transactionDuration); n != 1 {
+		t.Errorf("%d latency histograms, want 1 for the pair", n)
+	}
+}
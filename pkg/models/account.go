@@ -21,6 +21,8 @@ package models
 // THE SOFTWARE.
 
 import (
+	"time"
+
 	"github.com/gofrs/uuid"
 	"gorm.io/gorm"
 )
@@ -44,12 +46,43 @@ const (
 	AccTypeSavings = AccountType("savings")
 	AccTypeCurrent = AccountType("current")
 	AccTypeUtility = AccountType("utility")
+
+	// system account, it is debit-normal: debits add to its balance
+	// which is the total paid out of the account
+	AccTypeExpense = AccountType("expense")
+
+	// system account, it is credit-normal like the accounts of customers:
+	// its balance is the total the wallet earned from them
+	AccTypeIncome = AccountType("income")
+)
+
+// SystemInterestExpenseUserID owns the system account that
+// interest paid to customers is debited from
+var SystemInterestExpenseUserID = uuid.Must(uuid.FromString("00000000-0000-0000-0000-000000000001"))
+
+// SystemIncomeUserID owns the system account that penalties
+// and charges taken from customers are credited to
+var SystemIncomeUserID = uuid.Must(uuid.FromString("00000000-0000-0000-0000-000000000002"))
+
+// CreditTier (none,bronze,silver,gold) decides the overdraft limit of
+// an account and what the facility costs
+type CreditTier string
+
+const (
+	TierNone   = CreditTier("none")
+	TierBronze = CreditTier("bronze")
+	TierSilver = CreditTier("silver")
+	TierGold   = CreditTier("gold")
 )
 
 // Account entity definition
 type Account struct {
 	ID uuid.UUID
 
+	// a generated number with a check digit, customers use it to
+	// receive money from other customers
+	AccountNumber string `gorm:"column:account_number;unique"`
+
 	// balance will be stored in Paisas
 	AvailableBalance Paisas `gorm:"column:available_balance"`
 
@@ -57,27 +90,75 @@ type Account struct {
 	AccountType AccountType   `gorm:"column:account_type"`
 	UserID      uuid.UUID     `gorm:"column:user_id;not null;unique"` // a user can only have one account
 
+	// the overdraft facility is opt-in and only offered to subscribers. The limit
+	// is set from the credit tier of the account and is 0 until the customer opts in
+	CreditTier     CreditTier `gorm:"column:credit_tier;default:'none'"`
+	OverdraftLimit Paisas     `gorm:"column:overdraft_limit"`
+	// outstanding overdraft including accrued interest and fees, it
+	// is repaid automatically from the next incoming credit
+	OverdraftUsed Paisas `gorm:"column:overdraft_used"`
+	// the day interest and fees were last charged on the overdraft
+	OverdraftAccruedAt *time.Time `gorm:"column:overdraft_accrued_at"`
+
 	gorm.Model
 }
 
 // Balance converts balance from Paisas
 func (acc Account) Balance() float64 {
-	return float64(acc.AvailableBalance / 100)
+	return acc.AvailableBalance.ToFloat()
+}
+
+// OverdraftBalance converts the outstanding overdraft from Paisas
+func (acc Account) OverdraftBalance() float64 {
+	return acc.OverdraftUsed.ToFloat()
+}
+
+// OverdraftAvailable is how much of the overdraft limit can still be drawn
+func (acc Account) OverdraftAvailable() Paisas {
+	if acc.OverdraftUsed >= acc.OverdraftLimit {
+		return 0
+	}
+	return acc.OverdraftLimit - acc.OverdraftUsed
+}
+
+// IsDebitNormal is true for system expense accounts
+func (acc Account) IsDebitNormal() bool {
+	return acc.AccountType == AccTypeExpense
 }
 
-// Credit add an amount to account balance and return it
-func (acc Account) Credit(amount Paisas) Paisas {
-	// convert incoming amount into Paisas and add to account balance
-	return amount + acc.AvailableBalance
+// Credit adds an amount to the account and returns the new balance and outstanding
+// overdraft. An outstanding overdraft is repaid first from the amount.
+func (acc Account) Credit(amount Paisas) (balance Paisas, overdraftUsed Paisas) {
+	if acc.IsDebitNormal() {
+		return acc.AvailableBalance - amount, acc.OverdraftUsed
+	}
+
+	repayment := amount
+	if repayment > acc.OverdraftUsed {
+		repayment = acc.OverdraftUsed
+	}
+	return acc.AvailableBalance + amount - repayment, acc.OverdraftUsed - repayment
 }
 
-// Debit subtract an amount from account balance and return it
-func (acc Account) Debit(amount Paisas) Paisas {
-	// convert incoming amount into Paisas and subtract to account balance
-	return acc.AvailableBalance - amount
+// Debit subtracts an amount from the account and returns the new balance and outstanding
+// overdraft. What the balance can't cover is drawn from the overdraft.
+func (acc Account) Debit(amount Paisas) (balance Paisas, overdraftUsed Paisas) {
+	if acc.IsDebitNormal() {
+		return acc.AvailableBalance + amount, acc.OverdraftUsed
+	}
+
+	if amount <= acc.AvailableBalance {
+		return acc.AvailableBalance - amount, acc.OverdraftUsed
+	}
+	return 0, acc.OverdraftUsed + amount - acc.AvailableBalance
 }
 
-// IsBalanceLessThanAmount converts amount into Paisas and returns true if balance is less than amount
+// IsBalanceLessThanAmount returns true if the balance together with the
+// overdraft still available is less than amount. A debit-normal account can
+// always be debited.
 func (acc Account) IsBalanceLessThanAmount(amount Paisas) bool {
-	return acc.AvailableBalance < amount
+	if acc.IsDebitNormal() {
+		return false
+	}
+	return acc.AvailableBalance+acc.OverdraftAvailable() < amount
 }
@@ -28,7 +28,7 @@ import (
 // Agent
 type Agent struct {
 	ID    uuid.UUID
-	Email string `gorm:"not null;unique"` // email is used as account number
+	Email string `gorm:"not null;unique"`
 
 	FirstName   string
 	LastName    string
@@ -38,7 +38,7 @@ type Agent struct {
 
 	// an agent is usually assigned an agent number that they use for
 	// transactions with other customers
-	// AgentNumber string `gorm:"column:agent_number;unique"`
+	AgentNumber string `gorm:"column:agent_number;unique"`
 
 	// an extra column/property that tells us if the agent is a super agent
 	SuperAgent SuperAgentStatus `gorm:"default:'0'"` // PS: bool values dont work well with gorm during updates
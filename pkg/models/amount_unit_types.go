@@ -37,3 +37,8 @@ type Paisas uint
 func (amt Paisas) ToRupees() Rupees {
 	return Rupees(amt / 100)
 }
+
+// ToFloat returns the amount in Rupees, keeping the Paisas as the fraction
+func (amt Paisas) ToFloat() float64 {
+	return float64(amt) / 100
+}
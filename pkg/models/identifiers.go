@@ -0,0 +1,109 @@
+package models
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"crypto/rand"
+	"log"
+	"math/big"
+)
+
+// lengths of the generated customer identifiers, the trailing check digit included.
+// The lengths are different so that a number alone tells us what it identifies.
+const (
+	AccountNumberLength = 12
+	TillNumberLength    = 7
+	AgentNumberLength   = 6
+)
+
+// NumberGenerationAttempts is how many times a generated number is retried when it
+// collides with an already assigned one.
+const NumberGenerationAttempts = 5
+
+// NewAccountNumber generates a random account number with a trailing check digit
+func NewAccountNumber() string {
+	return newNumber(AccountNumberLength)
+}
+
+// NewTillNumber generates a random merchant till number with a trailing check digit
+func NewTillNumber() string {
+	return newNumber(TillNumberLength)
+}
+
+// NewAgentNumber generates a random agent number with a trailing check digit
+func NewAgentNumber() string {
+	return newNumber(AgentNumberLength)
+}
+
+// IsValidNumber returns true if number is made up of digits only and its last digit
+// is the check digit of the rest of the number.
+func IsValidNumber(number string) bool {
+	if len(number) < 2 {
+		return false
+	}
+	for _, c := range number {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	payload := number[:len(number)-1]
+	return checkDigit(payload) == number[len(number)-1]
+}
+
+func newNumber(length int) string {
+	digits := make([]byte, length-1)
+	for i := range digits {
+		// the number should not start with a zero
+		max, offset := int64(10), byte('0')
+		if i == 0 {
+			max, offset = 9, '1'
+		}
+
+		n, err := rand.Int(rand.Reader, big.NewInt(max))
+		if err != nil {
+			log.Panic(err)
+		}
+		digits[i] = offset + byte(n.Int64())
+	}
+
+	payload := string(digits)
+	return payload + string(checkDigit(payload))
+}
+
+// checkDigit computes the check digit of a numeric payload using the Luhn algorithm
+func checkDigit(payload string) byte {
+	sum := 0
+	double := true // the rightmost digit of the payload is doubled
+	for i := len(payload) - 1; i >= 0; i-- {
+		d := int(payload[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
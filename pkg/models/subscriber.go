@@ -28,7 +28,7 @@ import (
 // Subscriber
 type Subscriber struct {
 	ID    uuid.UUID
-	Email string `gorm:"not null;unique"` // email is used as account number
+	Email string `gorm:"not null;unique"`
 
 	FirstName   string
 	LastName    string
@@ -35,6 +35,22 @@ const (
 
 	// only used when an admin is assigning float to a super agent
 	TxnFloatAssignment = TxnOperation("FLOAT_ASSIGNMENT")
+
+	// interest and fees charged daily on an outstanding overdraft
+	TxnOpOverdraftCharge = TxnOperation("OVERDRAFT_CHARGE")
+
+	// interest paid monthly on savings
+	TxnOpInterest = TxnOperation("INTEREST")
+
+	// money moved between the spendable balance and a pocket, and the
+	// penalty charged for withdrawing from a locked pocket
+	TxnOpPocketDeposit  = TxnOperation("POCKET_DEPOSIT")
+	TxnOpPocketWithdraw = TxnOperation("POCKET_WITHDRAW")
+	TxnOpPocketPenalty  = TxnOperation("POCKET_PENALTY")
+
+	// an entry posted to the ledger, once approved by an admin, so that it
+	// adds up to the balance of an account again
+	TxnOpReconciliation = TxnOperation("RECONCILIATION")
 )
 
 type TxnState string
@@ -36,11 +36,38 @@ type SignedUser struct {
 
 // User entity definition. Describes any of
 // admin, agent, merchant or subscriber
+//
+// The users table is the directory of every identity in the wallet, it maps a
+// user id to the user type and the identifiers the user is known by. Keeping
+// them in one table lets the database enforce that an email or a phone number
+// is not reused across user types.
 type User struct {
-	UserID   uuid.UUID
-	UserType UserType
+	UserID   uuid.UUID `gorm:"primaryKey"`
+	UserType UserType  `gorm:"not null"`
+
+	Email string `gorm:"not null;unique"`
+	// administrators have no phone number, null values don't clash
+	PhoneNumber *string `gorm:"unique"`
+	// till number of a merchant or agent number of an agent
+	CustomerNumber *string `gorm:"unique"`
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
+
+// NewUser creates a directory entry, empty identifiers are left out.
+func NewUser(userID uuid.UUID, userType UserType, email, phoneNumber, customerNumber string) User {
+	user := User{
+		UserID:   userID,
+		UserType: userType,
+		Email:    email,
+	}
+	if phoneNumber != "" {
+		user.PhoneNumber = &phoneNumber
+	}
+	if customerNumber != "" {
+		user.CustomerNumber = &customerNumber
+	}
+	return user
+}
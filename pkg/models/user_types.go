@@ -35,4 +35,8 @@ const (
 	UserTypSubscriber = UserType("subscriber")
 
 	UserTypSuperAgent = UserType("super_agent")
+
+	// UserTypSystem describes the wallet itself, e.g. as the counterparty of interest
+	// paid out of its expense account. It is never the type of a registered user.
+	UserTypSystem = UserType("system")
 )
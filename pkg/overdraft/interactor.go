@@ -0,0 +1,225 @@
+package overdraft
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"log"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/account"
+	"github.com/bhojpur/wallet/pkg/customer"
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/statement"
+	"github.com/bhojpur/wallet/pkg/worker"
+
+	"github.com/gofrs/uuid"
+)
+
+// how often the accrual job looks for overdrafts that have not been charged today
+const accrualCheckInterval = time.Hour
+
+type Interactor interface {
+	OptIn(userID uuid.UUID, userType models.UserType) (models.Account, error)
+	OptOut(userID uuid.UUID, userType models.UserType) (models.Account, error)
+	SetCreditTier(params CreditTierParams) (models.Account, error)
+	AccrueCharges(day time.Time) error
+}
+
+func NewInteractor(accRepo account.Repository, ledger statement.Ledger, accountant account.Accountant, finder customer.Finder, workers *worker.Group) Interactor {
+	intr := &interactor{
+		accRepo:        accRepo,
+		ledger:         ledger,
+		accountant:     accountant,
+		customerFinder: finder,
+	}
+
+	workers.Go("overdraft accrual job", intr.runAccrualJob)
+
+	return intr
+}
+
+type interactor struct {
+	accRepo        account.Repository
+	ledger         statement.Ledger
+	accountant     account.Accountant
+	customerFinder customer.Finder
+}
+
+func (i interactor) getAccount(userID uuid.UUID) (models.Account, error) {
+	acc, err := i.accRepo.GetAccountByUserID(userID)
+	if errors.ErrorCode(err) == errors.ENOTFOUND {
+		return models.Account{}, errors.Error{Message: errors.AccountNotCreated, Err: err}
+	} else if err != nil {
+		return models.Account{}, err
+	}
+
+	return acc, nil
+}
+
+// OptIn turns on the overdraft facility of a subscriber, the limit is
+// set from the credit tier the account has been assigned
+func (i interactor) OptIn(userID uuid.UUID, userType models.UserType) (models.Account, error) {
+	if userType != models.UserTypSubscriber {
+		return models.Account{}, errors.Error{Code: errors.EINVALID, Message: errors.OverdraftOnlyForSubscribers}
+	}
+
+	acc, err := i.getAccount(userID)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	policy := PolicyFor(acc.CreditTier)
+	if policy.Limit == 0 {
+		return models.Account{}, errors.Error{Code: errors.EINVALID, Message: errors.OverdraftNoCreditTier}
+	}
+
+	return i.accRepo.UpdateOverdraftLimit(userID, acc.CreditTier, policy.Limit)
+}
+
+// OptOut turns off the overdraft facility, it is only possible
+// once the outstanding overdraft has been repaid
+func (i interactor) OptOut(userID uuid.UUID, userType models.UserType) (models.Account, error) {
+	if userType != models.UserTypSubscriber {
+		return models.Account{}, errors.Error{Code: errors.EINVALID, Message: errors.OverdraftOnlyForSubscribers}
+	}
+
+	acc, err := i.getAccount(userID)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	if acc.OverdraftUsed > 0 {
+		return models.Account{}, errors.Error{Code: errors.EINVALID, Message: errors.OverdraftOutstanding}
+	}
+
+	return i.accRepo.UpdateOverdraftLimit(userID, acc.CreditTier, 0)
+}
+
+// SetCreditTier is an admin only operation that assigns a credit tier to a subscriber's
+// account. If the subscriber has opted in, the overdraft limit follows the new tier.
+func (i interactor) SetCreditTier(params CreditTierParams) (models.Account, error) {
+	sub, err := i.customerFinder.FindSubscriber(params.AccountNumber)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	acc, err := i.getAccount(sub.ID)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	var limit models.Paisas
+	if acc.OverdraftLimit > 0 {
+		limit = PolicyFor(params.Tier).Limit
+	}
+
+	return i.accRepo.UpdateOverdraftLimit(sub.ID, params.Tier, limit)
+}
+
+// AccrueCharges charges a day's interest and fees on every outstanding overdraft, they are
+// credited to the income system account. An account is charged at most once a day, running
+// it again on the same day charges nothing.
+func (i interactor) AccrueCharges(day time.Time) error {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+
+	income, err := i.accRepo.CreateSystemAccount(models.SystemIncomeUserID, models.AccTypeIncome)
+	if err != nil {
+		return err
+	}
+
+	accounts, err := i.accRepo.GetAccountsInOverdraft(day)
+	if err != nil {
+		return err
+	}
+
+	for _, acc := range accounts {
+		charge := PolicyFor(acc.CreditTier).DailyCharge(acc.OverdraftUsed)
+
+		err = i.chargeOverdraft(income, acc, charge, day)
+		if err != nil {
+			log.Printf("error happened while charging overdraft of account %v: %v", acc.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// chargeOverdraft adds the charge of a day to the outstanding overdraft of an account and
+// credits it to the income system account. When either side can't be recorded the charge
+// is released, so that the account is charged on the next run.
+func (i interactor) chargeOverdraft(income, acc models.Account, charge models.Paisas, day time.Time) error {
+	updated, err := i.accRepo.AccrueOverdraftCharge(acc.UserID, charge, day)
+	if errors.ErrorCode(err) == errors.ENOTFOUND {
+		// charged in the meantime
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if charge == 0 {
+		return nil
+	}
+
+	release := func() {
+		if e := i.accRepo.ReleaseOverdraftCharge(acc.UserID, charge, day, acc.OverdraftAccruedAt); e != nil {
+			log.Printf("error happened while releasing overdraft charge of account %v: %v", acc.ID, e)
+		}
+	}
+
+	// each side of the movement names the other as its counterparty
+	customer := models.TxnCustomer{UserID: acc.UserID, UserType: models.UserTypSubscriber}
+	system := models.TxnCustomer{UserID: income.UserID, UserType: models.UserTypSystem}
+
+	_, err = i.accountant.CreditAccount(income.UserID, charge, models.TxnOpOverdraftCharge, customer)
+	if err != nil {
+		release()
+		return err
+	}
+
+	err = i.ledger.Record(acc.UserID, updated, models.TxnOpOverdraftCharge, charge, statement.TypeDebit, system)
+	if err != nil {
+		release()
+		// take the charge back so that the income account matches what was charged
+		if _, e := i.accountant.DebitAccount(income.UserID, charge, models.TxnOpOverdraftCharge, customer); e != nil {
+			log.Printf("error happened while reversing overdraft income of %v: %v", charge, e)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (i interactor) runAccrualJob(done <-chan struct{}) {
+	ticker := time.NewTicker(accrualCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := i.AccrueCharges(time.Now()); err != nil {
+			log.Printf("error happened while accruing overdraft charges %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+	}
+}
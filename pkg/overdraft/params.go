@@ -0,0 +1,46 @@
+package overdraft
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// CreditTierParams are properties required when an admin sets the credit tier of a subscriber
+type CreditTierParams struct {
+	AccountNumber string            `json:"accountNo" schema:"accountNo" form:"accountNo"`
+	Tier          models.CreditTier `json:"tier" schema:"tier" form:"tier"`
+}
+
+func (req CreditTierParams) Validate() error {
+	err := validation.ValidateStruct(&req,
+		validation.Field(&req.AccountNumber, validation.Required.Error(string(errors.ErrorAccountNumberRequired))),
+		validation.Field(&req.Tier,
+			validation.Required.Error(string(errors.ErrorCreditTierRequired)),
+			validation.In(CreditTiers()...).Error(string(errors.ErrorInvalidCreditTier)),
+		),
+	)
+
+	return errors.ParseValidationErrorMap(err)
+}
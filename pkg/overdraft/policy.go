@@ -0,0 +1,63 @@
+package overdraft
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"github.com/bhojpur/wallet/pkg/models"
+)
+
+// Policy describes the overdraft facility offered to a credit tier
+type Policy struct {
+	// most that can be overdrawn on the account
+	Limit models.Paisas
+	// interest charged daily on the outstanding overdraft, in basis points
+	DailyInterestBps uint
+	// flat fee charged for every day the overdraft is outstanding
+	DailyFee models.Paisas
+}
+
+// policies per credit tier, an account without a tier is not offered an overdraft
+var policies = map[models.CreditTier]Policy{
+	models.TierNone:   {},
+	models.TierBronze: {Limit: models.Rupees(500).ToPaisas(), DailyFee: models.Rupees(1).ToPaisas()},
+	models.TierSilver: {Limit: models.Rupees(2000).ToPaisas(), DailyInterestBps: 5},
+	models.TierGold:   {Limit: models.Rupees(5000).ToPaisas(), DailyInterestBps: 4},
+}
+
+// PolicyFor returns the policy of a credit tier
+func PolicyFor(tier models.CreditTier) Policy {
+	return policies[tier]
+}
+
+// DailyCharge is the interest and fee charged for a day on the outstanding overdraft
+func (p Policy) DailyCharge(outstanding models.Paisas) models.Paisas {
+	if outstanding == 0 {
+		return 0
+	}
+
+	interest := models.Paisas(uint64(outstanding) * uint64(p.DailyInterestBps) / 10000)
+	return interest + p.DailyFee
+}
+
+// CreditTiers lists the valid credit tiers
+func CreditTiers() []interface{} {
+	return []interface{}{models.TierNone, models.TierBronze, models.TierSilver, models.TierGold}
+}
@@ -0,0 +1,222 @@
+package pocket
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"log"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/account"
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
+
+	"github.com/gofrs/uuid"
+)
+
+type Interactor interface {
+	Create(customer models.TxnCustomer, params CreateParams) (Pocket, error)
+	List(userID uuid.UUID) ([]Pocket, error)
+	Deposit(userID, pocketID uuid.UUID, params MoveParams) (Pocket, error)
+	Withdraw(userID, pocketID uuid.UUID, params MoveParams) (Pocket, error)
+	Close(userID, pocketID uuid.UUID) error
+}
+
+func NewInteractor(repository Repository, accRepo account.Repository, accountant account.Accountant, policy EarlyWithdrawalPolicy) Interactor {
+	return &interactor{
+		repository: repository,
+		accRepo:    accRepo,
+		accountant: accountant,
+		policy:     policy,
+	}
+}
+
+type interactor struct {
+	repository Repository
+	accRepo    account.Repository
+	accountant account.Accountant
+	policy     EarlyWithdrawalPolicy
+}
+
+func (i interactor) getAccount(userID uuid.UUID) (models.Account, error) {
+	acc, err := i.accRepo.GetAccountByUserID(userID)
+	if errors.ErrorCode(err) == errors.ENOTFOUND {
+		return models.Account{}, errors.Error{Message: errors.AccountNotCreated, Err: err}
+	} else if err != nil {
+		return models.Account{}, err
+	}
+
+	return acc, nil
+}
+
+// Create adds a new empty pocket to a subscriber's account
+func (i interactor) Create(customer models.TxnCustomer, params CreateParams) (Pocket, error) {
+	if customer.UserType != models.UserTypSubscriber {
+		return Pocket{}, errors.Error{Code: errors.EINVALID, Message: errors.PocketsOnlyForSubscribers}
+	}
+
+	acc, err := i.getAccount(customer.UserID)
+	if err != nil {
+		return Pocket{}, err
+	}
+
+	return i.repository.Add(Pocket{
+		AccountID:   acc.ID,
+		UserID:      customer.UserID,
+		Name:        params.Name,
+		Target:      params.Target.ToPaisas(),
+		LockedUntil: params.lockedUntil(),
+	})
+}
+
+// List returns the pockets of a customer
+func (i interactor) List(userID uuid.UUID) ([]Pocket, error) {
+	return i.repository.FetchByUser(userID)
+}
+
+// Deposit moves money from the spendable balance into a pocket. The overdraft
+// facility can't be used to fund a pocket.
+func (i interactor) Deposit(userID, pocketID uuid.UUID, params MoveParams) (Pocket, error) {
+	pocket, err := i.repository.FindByID(pocketID, userID)
+	if err != nil {
+		return Pocket{}, err
+	}
+
+	amount := params.Amount.ToPaisas()
+
+	_, err = i.accountant.DebitAvailableBalance(userID, amount, models.TxnOpPocketDeposit, models.TxnCustomer{})
+	if err != nil {
+		return Pocket{}, err
+	}
+
+	pocket, err = i.repository.AddToBalance(pocket.ID, amount)
+	if err != nil {
+		// put the money back into the spendable balance
+		if _, e := i.accountant.CreditAccount(userID, amount, models.TxnOpPocketWithdraw, models.TxnCustomer{}); e != nil {
+			log.Printf("error happened while returning %v to account of user %v: %v", amount, userID, e)
+		}
+		return Pocket{}, err
+	}
+
+	return pocket, nil
+}
+
+// Withdraw moves money from a pocket back into the spendable balance. Withdrawing from
+// a locked pocket is refused or penalised, as the early withdrawal policy says. The money
+// stays in the pocket when the penalty can't be charged.
+func (i interactor) Withdraw(userID, pocketID uuid.UUID, params MoveParams) (Pocket, error) {
+	pocket, err := i.repository.FindByID(pocketID, userID)
+	if err != nil {
+		return Pocket{}, err
+	}
+
+	amount := params.Amount.ToPaisas()
+
+	var penalty models.Paisas
+	if pocket.IsLocked(time.Now()) {
+		if i.policy.Refuse {
+			return Pocket{}, errors.Error{Code: errors.EINVALID, Message: errors.ErrPocketLocked}
+		}
+
+		penalty = i.policy.Penalty(amount)
+		if penalty > 0 && !params.AcceptPenalty {
+			return Pocket{}, errors.Error{Code: errors.EINVALID, Message: errors.ErrPocketPenaltyNotAccepted(penalty)}
+		}
+	}
+
+	pocket, err = i.repository.SubtractFromBalance(pocket.ID, amount)
+	if err != nil {
+		return Pocket{}, err
+	}
+
+	_, err = i.accountant.CreditAccount(userID, amount, models.TxnOpPocketWithdraw, models.TxnCustomer{})
+	if err != nil {
+		// put the money back into the pocket
+		if _, e := i.repository.AddToBalance(pocket.ID, amount); e != nil {
+			log.Printf("error happened while returning %v to pocket %v: %v", amount, pocket.ID, e)
+		}
+		return Pocket{}, err
+	}
+
+	if penalty > 0 {
+		err = i.chargePenalty(userID, penalty)
+		if err != nil {
+			// the withdrawal doesn't go through without its penalty
+			i.returnToPocket(userID, pocket.ID, amount)
+			return Pocket{}, err
+		}
+	}
+
+	return pocket, nil
+}
+
+// returnToPocket reverses a withdrawal, the money goes from the spendable balance back into the pocket
+func (i interactor) returnToPocket(userID, pocketID uuid.UUID, amount models.Paisas) {
+	if _, err := i.accountant.DebitAccount(userID, amount, models.TxnOpPocketDeposit, models.TxnCustomer{}); err != nil {
+		log.Printf("error happened while returning %v to pocket %v: %v", amount, pocketID, err)
+		return
+	}
+	if _, err := i.repository.AddToBalance(pocketID, amount); err != nil {
+		log.Printf("error happened while returning %v to pocket %v: %v", amount, pocketID, err)
+	}
+}
+
+// chargePenalty moves an early withdrawal penalty from the customer's account to the income
+// system account. The penalty is charged against the spendable balance, never the overdraft.
+func (i interactor) chargePenalty(userID uuid.UUID, penalty models.Paisas) error {
+	income, err := i.accRepo.CreateSystemAccount(models.SystemIncomeUserID, models.AccTypeIncome)
+	if err != nil {
+		return err
+	}
+
+	// each side of the movement names the other as its counterparty
+	customer := models.TxnCustomer{UserID: userID, UserType: models.UserTypSubscriber}
+	system := models.TxnCustomer{UserID: income.UserID, UserType: models.UserTypSystem}
+
+	_, err = i.accountant.DebitAvailableBalance(userID, penalty, models.TxnOpPocketPenalty, system)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.accountant.CreditAccount(income.UserID, penalty, models.TxnOpPocketPenalty, customer)
+	if err != nil {
+		// refund the penalty so that the income account matches what was charged
+		if _, e := i.accountant.CreditAccount(userID, penalty, models.TxnOpPocketPenalty, system); e != nil {
+			log.Printf("error happened while refunding early withdrawal penalty of %v to user %v: %v", penalty, userID, e)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Close removes an empty pocket
+func (i interactor) Close(userID, pocketID uuid.UUID) error {
+	pocket, err := i.repository.FindByID(pocketID, userID)
+	if err != nil {
+		return err
+	}
+
+	if pocket.Balance > 0 {
+		return errors.Error{Code: errors.EINVALID, Message: errors.ErrPocketNotEmpty}
+	}
+
+	return i.repository.Delete(pocket)
+}
@@ -0,0 +1,79 @@
+package pocket
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/helpers"
+	"github.com/bhojpur/wallet/pkg/models"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// CreateParams are properties required when a customer creates a pocket
+type CreateParams struct {
+	Name   string        `json:"name" schema:"name" form:"name"`
+	Target models.Rupees `json:"target" schema:"target" form:"target"`
+	// money can't be withdrawn without penalty before this day, YYYY-MM-DD
+	LockedUntil string `json:"lockedUntil" schema:"lockedUntil" form:"lockedUntil"`
+}
+
+func (req CreateParams) Validate() error {
+	err := validation.ValidateStruct(&req,
+		validation.Field(&req.Name,
+			validation.Required.Error(string(errors.ErrorPocketNameRequired)),
+			validation.RuneLength(0, 50).Error(string(errors.ErrorPocketNameTooLong)),
+		),
+		validation.Field(&req.LockedUntil, validation.Date(helpers.DateLayout).Error(string(errors.ErrorInvalidDate))),
+	)
+
+	return errors.ParseValidationErrorMap(err)
+}
+
+// lockedUntil returns the start of the lock-until day, or nil if the pocket isn't locked
+func (req CreateParams) lockedUntil() *time.Time {
+	if req.LockedUntil == "" {
+		return nil
+	}
+
+	day, err := time.ParseInLocation(helpers.DateLayout, req.LockedUntil, time.Local)
+	if err != nil {
+		return nil
+	}
+	return &day
+}
+
+// MoveParams are properties required when moving money in or out of a pocket
+type MoveParams struct {
+	Amount models.Rupees `json:"amount" schema:"amount" form:"amount"`
+	// the customer agrees to pay the penalty of withdrawing from a locked pocket
+	AcceptPenalty bool `json:"acceptPenalty" schema:"acceptPenalty" form:"acceptPenalty"`
+}
+
+func (req MoveParams) Validate() error {
+	err := validation.ValidateStruct(&req,
+		validation.Field(&req.Amount, validation.Required.Error(string(errors.ErrorAmountRequired))),
+	)
+
+	return errors.ParseValidationErrorMap(err)
+}
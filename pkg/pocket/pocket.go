@@ -0,0 +1,70 @@
+package pocket
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Pocket is a named savings goal inside a customer's wallet. Money moved into a pocket
+// leaves the spendable balance of the account, so it can't be debited by transactions.
+type Pocket struct {
+	ID        uuid.UUID
+	AccountID uuid.UUID `gorm:"column:account_id;not null;uniqueIndex:idx_pocket_account_name"`
+	UserID    uuid.UUID `gorm:"column:user_id;not null;index"`
+	Name      string    `gorm:"column:name;not null;uniqueIndex:idx_pocket_account_name"`
+
+	Balance models.Paisas `gorm:"column:balance"`
+	// optional amount the customer is saving towards
+	Target models.Paisas `gorm:"column:target"`
+	// optional date before which withdrawals are refused or penalised
+	LockedUntil *time.Time `gorm:"column:locked_until"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (p *Pocket) BeforeCreate(tx *gorm.DB) error {
+	p.ID, _ = uuid.NewV4()
+	return nil
+}
+
+func (Pocket) TableName() string {
+	return "pockets"
+}
+
+// IsLocked returns true if the pocket is locked at the given time
+func (p Pocket) IsLocked(at time.Time) bool {
+	return p.LockedUntil != nil && at.Before(*p.LockedUntil)
+}
+
+// Progress is the share of the target saved, in percent
+func (p Pocket) Progress() float64 {
+	if p.Target == 0 {
+		return 0
+	}
+	return float64(p.Balance) * 100 / float64(p.Target)
+}
@@ -0,0 +1,131 @@
+package pocket
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/storage"
+
+	"github.com/gofrs/uuid"
+	"github.com/jackc/pgconn"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Add(Pocket) (Pocket, error)
+	Delete(Pocket) error
+	FindByID(id, userID uuid.UUID) (Pocket, error)
+	FetchByUser(userID uuid.UUID) ([]Pocket, error)
+	AddToBalance(id uuid.UUID, amount models.Paisas) (Pocket, error)
+	SubtractFromBalance(id uuid.UUID, amount models.Paisas) (Pocket, error)
+}
+
+func NewRepository(database *storage.Database) Repository {
+	return &repository{db: database}
+}
+
+type repository struct {
+	db *storage.Database
+}
+
+// Add a pocket, a customer can't have two pockets with the same name
+func (r repository) Add(pocket Pocket) (Pocket, error) {
+	result := r.db.Create(&pocket)
+	if err := result.Error; err != nil {
+		// we check if the error is a postgres unique constraint violation
+		if pgerr, ok := err.(*pgconn.PgError); ok && pgerr.Code == "23505" {
+			return Pocket{}, errors.Error{Code: errors.ECONFLICT, Message: errors.ErrPocketExists}
+		}
+		return Pocket{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return pocket, nil
+}
+
+// Delete a pocket
+func (r repository) Delete(pocket Pocket) error {
+	result := r.db.Delete(&pocket)
+	if result.Error != nil {
+		return errors.Error{Err: result.Error, Code: errors.EINTERNAL}
+	}
+	return nil
+}
+
+// FindByID searches a pocket owned by the user
+func (r repository) FindByID(id, userID uuid.UUID) (Pocket, error) {
+	var pocket Pocket
+	result := r.db.Where(Pocket{ID: id, UserID: userID}).First(&pocket)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return Pocket{}, errors.Error{Code: errors.ENOTFOUND, Message: errors.ErrPocketNotFound}
+	} else if result.Error != nil {
+		return Pocket{}, errors.Error{Err: result.Error, Code: errors.EINTERNAL}
+	}
+
+	return pocket, nil
+}
+
+// FetchByUser gets all pockets of a user
+func (r repository) FetchByUser(userID uuid.UUID) ([]Pocket, error) {
+	var pockets []Pocket
+	result := r.db.Where(Pocket{UserID: userID}).Order("created_at").Find(&pockets)
+	if err := result.Error; err != nil {
+		return nil, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return pockets, nil
+}
+
+// AddToBalance adds an amount to the pocket's balance
+func (r repository) AddToBalance(id uuid.UUID, amount models.Paisas) (Pocket, error) {
+	result := r.db.Model(Pocket{}).Where("id = ?", id).
+		Update("balance", gorm.Expr("balance + ?", amount))
+	if err := result.Error; err != nil {
+		return Pocket{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return r.findByID(id)
+}
+
+// SubtractFromBalance takes an amount from the pocket's balance, it
+// fails without changing the balance if the pocket holds less than amount
+func (r repository) SubtractFromBalance(id uuid.UUID, amount models.Paisas) (Pocket, error) {
+	result := r.db.Model(Pocket{}).Where("id = ? AND balance >= ?", id, amount).
+		Update("balance", gorm.Expr("balance - ?", amount))
+	if err := result.Error; err != nil {
+		return Pocket{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+	if result.RowsAffected == 0 {
+		return Pocket{}, errors.Error{Code: errors.EINVALID, Message: errors.ErrPocketBalanceTooLow}
+	}
+
+	return r.findByID(id)
+}
+
+func (r repository) findByID(id uuid.UUID) (Pocket, error) {
+	var pocket Pocket
+	result := r.db.Where(Pocket{ID: id}).First(&pocket)
+	if err := result.Error; err != nil {
+		return Pocket{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return pocket, nil
+}
@@ -21,11 +21,12 @@ package ports
 // THE SOFTWARE.
 
 import (
+	"log"
+
 	"github.com/bhojpur/wallet/pkg/customer"
 	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/receipt"
 	"github.com/bhojpur/wallet/pkg/transaction"
-
-	"github.com/gofrs/uuid"
 )
 
 // TransactorPort is not a good name. Its function is to expose an interface to the application layer that it
@@ -33,31 +34,43 @@ import (
 //
 // To keep the Transaction context clean from a dependency of the agent, merchant and subscriber contexts,
 // i chose to create this port separately.
+//
+// Every completed transaction gets a signed receipt.
 type TransactorPort interface {
-	Deposit(depositor models.TxnCustomer, customerNumber string, customerType models.UserType, amount models.Rupees) error
-	Transfer(source models.TxnCustomer, destAccNumber string, destCustomerType models.UserType, amount models.Rupees) error
-	Withdraw(withdrawer models.TxnCustomer, agentNumber string, amount models.Rupees) error
+	Deposit(depositor models.TxnCustomer, customerNumber string, customerType models.UserType, amount models.Rupees) (Completed, error)
+	Transfer(source models.TxnCustomer, destAccNumber string, destCustomerType models.UserType, amount models.Rupees) (Completed, error)
+	Withdraw(withdrawer models.TxnCustomer, agentNumber string, amount models.Rupees) (Completed, error)
 }
 
-func NewTransactor(finder customer.Finder, transactor transaction.Transactor) TransactorPort {
+// Completed is a completed transaction with its signed receipt. The money has moved even when the
+// receipt could not be issued, Receipt is then nil and ReceiptError tells why.
+type Completed struct {
+	transaction.Completed
+	Receipt      *receipt.Signed
+	ReceiptError error
+}
+
+func NewTransactor(finder customer.Finder, transactor transaction.Transactor, issuer receipt.Interactor) TransactorPort {
 	return &transactorAdapter{
 		customerFinder: finder,
 		transactor:     transactor,
+		issuer:         issuer,
 	}
 }
 
 type transactorAdapter struct {
 	customerFinder customer.Finder
 	transactor     transaction.Transactor
+	issuer         receipt.Interactor
 }
 
 // Deposit is a transaction between a customer and an agent. The customer's account is credited from the
 // agent's account. Money moves from the agent's account to the customer's account.
 // It is important to remember that it is the agent that does the deposit operation on behalf of the customer.
-func (tr transactorAdapter) Deposit(depositor models.TxnCustomer, customerNumber string, customerType models.UserType, amount models.Rupees) error {
-	customerID, err := tr.customerFinder.FindIDByEmail(customerNumber, customerType)
+func (tr transactorAdapter) Deposit(depositor models.TxnCustomer, customerNumber string, customerType models.UserType, amount models.Rupees) (Completed, error) {
+	customerID, err := tr.customerFinder.FindID(customerNumber, customerType)
 	if err != nil {
-		return err
+		return Completed{}, err
 	}
 
 	tx := transaction.Transaction{
@@ -70,20 +83,20 @@ func (tr transactorAdapter) Deposit(depositor models.TxnCustomer, customerNumber
 		TxnOperation: models.TxnOpDeposit,
 		Amount:       amount,
 	}
-	err = tr.transactor.Transact(tx)
+	completed, err := tr.transactor.Transact(tx)
 	if err != nil {
-		return err
+		return Completed{}, err
 	}
 
-	return nil
+	return tr.complete(completed), nil
 }
 
 // Withdraw is a transaction between a customer and an agent. The customer's account is debited and the
 // agent's account credited. Money moves from the customer's account to the agent's account.
-func (tr transactorAdapter) Withdraw(withdrawer models.TxnCustomer, agentNumber string, amount models.Rupees) error {
-	agt, err := tr.customerFinder.FindAgentByEmail(agentNumber)
+func (tr transactorAdapter) Withdraw(withdrawer models.TxnCustomer, agentNumber string, amount models.Rupees) (Completed, error) {
+	agt, err := tr.customerFinder.FindAgent(agentNumber)
 	if err != nil {
-		return err
+		return Completed{}, err
 	}
 
 	tx := transaction.Transaction{
@@ -96,57 +109,52 @@ func (tr transactorAdapter) Withdraw(withdrawer models.TxnCustomer, agentNumber
 		TxnOperation: models.TxnOpWithdraw,
 		Amount:       amount,
 	}
-	err = tr.transactor.Transact(tx)
+	completed, err := tr.transactor.Transact(tx)
 	if err != nil {
-		return err
+		return Completed{}, err
 	}
 
-	return nil
+	return tr.complete(completed), nil
 }
 
 // Transfer is a transaction describing a general movement of funds from a customer to another customer. One customer's
 // account is debited (the source) and the other customer's account credited (the destination). Money moves from the
 // source to the destination account.
-func (tr transactorAdapter) Transfer(source models.TxnCustomer, destAccNumber string, destCustomerType models.UserType, amount models.Rupees) error {
-	var customerID uuid.UUID
-	switch destCustomerType {
-	case models.UserTypAgent:
-		agt, err := tr.customerFinder.FindAgentByEmail(destAccNumber)
-		if err != nil {
-			return err
-		}
-
-		customerID = agt.ID
-	case models.UserTypMerchant:
-		merch, err := tr.customerFinder.FindMerchantByEmail(destAccNumber)
-		if err != nil {
-			return err
-		}
-
-		customerID = merch.ID
-	case models.UserTypSubscriber:
-		sub, err := tr.customerFinder.FindSubscriberByEmail(destAccNumber)
-		if err != nil {
-			return err
-		}
-
-		customerID = sub.ID
+//
+// The destination customer type is optional, when empty it is looked up from the users directory.
+func (tr transactorAdapter) Transfer(source models.TxnCustomer, destAccNumber string, destCustomerType models.UserType, amount models.Rupees) (Completed, error) {
+	dest, err := tr.customerFinder.FindUser(destAccNumber, destCustomerType)
+	if err != nil {
+		return Completed{}, err
 	}
 
 	tx := transaction.Transaction{
 		Source: source,
 		Destination: models.TxnCustomer{
-			UserID:   customerID,
-			UserType: destCustomerType,
+			UserID:   dest.UserID,
+			UserType: dest.UserType,
 		},
 
 		TxnOperation: models.TxnOpTransfer,
 		Amount:       amount,
 	}
-	err := tr.transactor.Transact(tx)
+	completed, err := tr.transactor.Transact(tx)
+	if err != nil {
+		return Completed{}, err
+	}
+
+	return tr.complete(completed), nil
+}
+
+// complete issues the receipt of a completed transaction. A receipt that can't be issued doesn't fail the
+// transaction, the client would retry it and move the money twice.
+func (tr transactorAdapter) complete(completed transaction.Completed) Completed {
+	signed, err := tr.issuer.Issue(completed)
 	if err != nil {
-		return err
+		log.Printf("error happened while issuing the receipt of a %s from %v to %v: %v",
+			completed.TxnOperation, completed.Source.UserID, completed.Destination.UserID, err)
+		return Completed{Completed: completed, ReceiptError: err}
 	}
 
-	return nil
+	return Completed{Completed: completed, Receipt: &signed}
 }
@@ -0,0 +1,69 @@
+package ports
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/receipt"
+	"github.com/bhojpur/wallet/pkg/transaction"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestCompleteWithoutReceipt(t *testing.T) {
+	txn := transaction.Completed{
+		Transaction: transaction.Transaction{
+			Source:       models.TxnCustomer{UserID: uuid.Must(uuid.NewV4()), UserType: models.UserTypSubscriber},
+			Destination:  models.TxnCustomer{UserID: uuid.Must(uuid.NewV4()), UserType: models.UserTypMerchant},
+			TxnOperation: models.TxnOpTransfer,
+			Amount:       500,
+		},
+		CompletedAt: time.Now(),
+	}
+
+	issueErr := errors.New("database is down")
+	tr := transactorAdapter{issuer: failingIssuer{err: issueErr}}
+
+	completed := tr.complete(txn)
+	if completed.Receipt != nil {
+		t.Errorf("complete handed out receipt %+v, want none", completed.Receipt)
+	}
+	if completed.ReceiptError != issueErr {
+		t.Errorf("complete receipt error = %v, want %v", completed.ReceiptError, issueErr)
+	}
+	if completed.Completed != txn {
+		t.Errorf("complete transaction = %+v, want %+v", completed.Completed, txn)
+	}
+}
+
+// failingIssuer fails to issue receipts, as when their record can't be written
+type failingIssuer struct {
+	receipt.Interactor
+	err error
+}
+
+func (i failingIssuer) Issue(transaction.Completed) (receipt.Signed, error) {
+	return receipt.Signed{}, i.err
+}
@@ -0,0 +1,126 @@
+package receipt
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/hex"
+
+	"github.com/bhojpur/wallet/pkg/engine"
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/transaction"
+
+	"github.com/gofrs/uuid"
+)
+
+type Interactor interface {
+	Issue(transaction.Completed) (Signed, error)
+	Verify(data []byte) (Verification, error)
+	PublicKey() string
+}
+
+// Verification is the outcome of checking a receipt someone presented
+type Verification struct {
+	Valid bool
+	// why the receipt is not valid
+	Reason string
+	// whether the receipt is the one that was issued for the transaction
+	Recorded bool
+	Receipt  *Receipt
+}
+
+func NewInteractor(repository Repository, signer *engine.Wallet) Interactor {
+	return &interactor{
+		repository: repository,
+		signer:     signer,
+	}
+}
+
+type interactor struct {
+	repository Repository
+	signer     *engine.Wallet
+}
+
+// Issue signs a receipt for a completed transaction and keeps a record of it. A receipt is
+// only handed out once its record is kept, so that Verify finds every receipt issued.
+func (i interactor) Issue(txn transaction.Completed) (Signed, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return Signed{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	rct := Receipt{
+		ID:          id,
+		Operation:   txn.TxnOperation,
+		Source:      Party{UserID: txn.Source.UserID, UserType: txn.Source.UserType},
+		Destination: Party{UserID: txn.Destination.UserID, UserType: txn.Destination.UserType},
+		Amount:      txn.Amount.ToPaisas(),
+		Charge:      txn.Charge,
+		Currency:    currency,
+		CompletedAt: txn.CompletedAt.UTC(),
+	}
+
+	signed, content, err := sign(rct, i.signer)
+	if err != nil {
+		return Signed{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	record := Record{
+		ID:            rct.ID,
+		SourceID:      rct.Source.UserID,
+		DestinationID: rct.Destination.UserID,
+		Content:       content,
+		Signature:     signed.Signature,
+	}
+	if err := i.repository.Add(record); err != nil {
+		return Signed{}, err
+	}
+
+	return signed, nil
+}
+
+// Verify checks a signed receipt against the key of the engine wallet and
+// looks up whether it is the receipt that was issued for the transaction
+func (i interactor) Verify(data []byte) (Verification, error) {
+	signed, err := Verify(data, i.signer.PublicKey)
+	switch err {
+	case nil:
+	case ErrMalformed, ErrUnknownAlgorithm, ErrUntrustedKey, ErrBadSignature:
+		return Verification{Reason: err.Error()}, nil
+	default:
+		return Verification{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	record, err := i.repository.Find(signed.Receipt.ID)
+	if err != nil && errors.ErrorCode(err) != errors.ENOTFOUND {
+		return Verification{}, err
+	}
+
+	return Verification{
+		Valid:    true,
+		Recorded: err == nil && record.Signature == signed.Signature,
+		Receipt:  &signed.Receipt,
+	}, nil
+}
+
+// PublicKey returns the hex encoded key receipts are signed with
+func (i interactor) PublicKey() string {
+	return hex.EncodeToString(i.signer.PublicKey)
+}
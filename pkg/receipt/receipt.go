@@ -0,0 +1,103 @@
+package receipt
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/models"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// Algorithm names how receipts are signed, an ASN.1 ECDSA signature by a P-256
+	// key over the SHA-256 hash of the canonical JSON of the receipt
+	Algorithm = "ECDSA-P256-SHA256"
+
+	currency = "INR"
+)
+
+// Party is a customer taking part in a transaction
+type Party struct {
+	UserID   uuid.UUID       `json:"userId"`
+	UserType models.UserType `json:"userType"`
+}
+
+// Receipt is the proof handed out for a completed transaction. Amounts are in paisas
+// so that the canonical JSON of a receipt never depends on how floats are formatted.
+type Receipt struct {
+	ID          uuid.UUID           `json:"id"`
+	Operation   models.TxnOperation `json:"operation"`
+	Source      Party               `json:"source"`
+	Destination Party               `json:"destination"`
+	Amount      models.Paisas       `json:"amount"`
+	Charge      models.Paisas       `json:"charge"`
+	Currency    string              `json:"currency"`
+	CompletedAt time.Time           `json:"completedAt"`
+}
+
+// Signed is a receipt together with the hex encoded public key of the
+// engine wallet that signed it and the hex encoded signature
+type Signed struct {
+	Receipt   Receipt `json:"receipt"`
+	Algorithm string  `json:"algorithm"`
+	PublicKey string  `json:"publicKey"`
+	Signature string  `json:"signature"`
+}
+
+// Canonical re-encodes a JSON document with the keys of every object sorted and without
+// insignificant whitespace. Numbers are kept as they were written, so a receipt gives
+// the same bytes however it was formatted or reordered on its way to the verifier.
+func Canonical(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON document")
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// canonical returns the canonical JSON of the receipt
+func (r Receipt) canonical() ([]byte, error) {
+	content, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return Canonical(content)
+}
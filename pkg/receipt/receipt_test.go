@@ -0,0 +1,197 @@
+package receipt
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/engine"
+	walleterrors "github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/transaction"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestCanonical(t *testing.T) {
+	want := `{"a":{"c":"x","d":[1,2.50]},"b":1}`
+	for _, doc := range []string{
+		want,
+		`{"b": 1, "a": {"d": [1, 2.50], "c": "x"}}`,
+		"{\n  \"a\": {\n    \"c\": \"x\",\n    \"d\": [1, 2.50]\n  },\n  \"b\": 1\n}\n",
+	} {
+		got, err := Canonical([]byte(doc))
+		if err != nil {
+			t.Fatalf("Canonical(%q) failed: %v", doc, err)
+		}
+		if string(got) != want {
+			t.Errorf("Canonical(%q) = %s, want %s", doc, got, want)
+		}
+	}
+
+	for _, doc := range []string{"", `{"a":1`, `{"a":1} {"b":2}`} {
+		if _, err := Canonical([]byte(doc)); err == nil {
+			t.Errorf("Canonical(%q) succeeded, want an error", doc)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	signer := engine.NewWallet()
+	signed, _, err := sign(testReceipt(t), signer)
+	if err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+	document, err := json.Marshal(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Verify(document, signer.PublicKey)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if got != signed {
+		t.Errorf("Verify = %+v, want %+v", got, signed)
+	}
+
+	// a receipt re-encoded on its way to the verifier, with its keys sorted
+	// rather than in the order they were signed in and indented
+	var decoded map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(document))
+	dec.UseNumber()
+	if err := dec.Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	reformatted, err := json.MarshalIndent(decoded, "", "    ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(reformatted, document) {
+		t.Fatal("reformatted receipt is the signed document")
+	}
+	if _, err := Verify(reformatted, signer.PublicKey); err != nil {
+		t.Errorf("Verify of the reformatted receipt failed: %v", err)
+	}
+
+	amount := `"amount":12345`
+	if !strings.Contains(string(document), amount) {
+		t.Fatalf("signed receipt %s has no %s", document, amount)
+	}
+	tampered := strings.Replace(string(document), amount, `"amount":123450`, 1)
+	if _, err := Verify([]byte(tampered), signer.PublicKey); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Verify of a tampered amount = %v, want %v", err, ErrBadSignature)
+	}
+
+	other := engine.NewWallet()
+	if _, err := Verify(document, other.PublicKey); !errors.Is(err, ErrUntrustedKey) {
+		t.Errorf("Verify with another key = %v, want %v", err, ErrUntrustedKey)
+	}
+	forged, _, err := sign(testReceipt(t), other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forgedDocument, err := json.Marshal(forged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(forgedDocument, signer.PublicKey); !errors.Is(err, ErrUntrustedKey) {
+		t.Errorf("Verify of a receipt signed with another key = %v, want %v", err, ErrUntrustedKey)
+	}
+}
+
+func TestIssue(t *testing.T) {
+	signer := engine.NewWallet()
+	txn := transaction.Completed{
+		Transaction: transaction.Transaction{
+			Source:       models.TxnCustomer{UserID: uuid.Must(uuid.NewV4()), UserType: models.UserTypSubscriber},
+			Destination:  models.TxnCustomer{UserID: uuid.Must(uuid.NewV4()), UserType: models.UserTypMerchant},
+			TxnOperation: models.TxnOpTransfer,
+			Amount:       500,
+		},
+		Charge:      1000,
+		CompletedAt: time.Now(),
+	}
+
+	repository := &memoryRepository{records: map[uuid.UUID]Record{}}
+	issuer := NewInteractor(repository, signer)
+	signed, err := issuer.Issue(txn)
+	if err != nil {
+		t.Fatalf("Issue failed: %v", err)
+	}
+	document, err := json.Marshal(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verification, err := issuer.Verify(document)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !verification.Valid || !verification.Recorded {
+		t.Errorf("Verify of an issued receipt = %+v, want valid and recorded", verification)
+	}
+
+	// a receipt that can't be recorded is not handed out
+	repository.err = errors.New("database is down")
+	if _, err := issuer.Issue(txn); err == nil {
+		t.Error("Issue succeeded without recording the receipt")
+	}
+}
+
+func testReceipt(t *testing.T) Receipt {
+	t.Helper()
+	return Receipt{
+		ID:          uuid.Must(uuid.NewV4()),
+		Operation:   models.TxnOpTransfer,
+		Source:      Party{UserID: uuid.Must(uuid.NewV4()), UserType: models.UserTypSubscriber},
+		Destination: Party{UserID: uuid.Must(uuid.NewV4()), UserType: models.UserTypMerchant},
+		Amount:      12345,
+		Charge:      1000,
+		Currency:    currency,
+		CompletedAt: time.Date(2022, 3, 14, 9, 26, 53, 0, time.UTC),
+	}
+}
+
+type memoryRepository struct {
+	records map[uuid.UUID]Record
+	err     error
+}
+
+func (r *memoryRepository) Add(record Record) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.records[record.ID] = record
+	return nil
+}
+
+func (r *memoryRepository) Find(id uuid.UUID) (Record, error) {
+	record, ok := r.records[id]
+	if !ok {
+		return Record{}, walleterrors.Error{Code: walleterrors.ENOTFOUND, Message: walleterrors.ErrReceiptNotFound}
+	}
+	return record, nil
+}
@@ -0,0 +1,46 @@
+package receipt
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Record is a receipt as it was issued, kept so that a receipt presented
+// later can be matched with the transaction it was issued for
+type Record struct {
+	// same as the id of the receipt
+	ID        uuid.UUID
+	CreatedAt time.Time
+
+	SourceID      uuid.UUID `gorm:"column:source_id;type:uuid;index"`
+	DestinationID uuid.UUID `gorm:"column:destination_id;type:uuid;index"`
+
+	// canonical JSON of the receipt and its hex encoded signature
+	Content   []byte `gorm:"column:content;not null"`
+	Signature string `gorm:"column:signature;not null"`
+}
+
+func (Record) TableName() string {
+	return "receipts"
+}
@@ -0,0 +1,64 @@
+package receipt
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/storage"
+
+	"github.com/gofrs/uuid"
+)
+
+type Repository interface {
+	Add(Record) error
+	Find(id uuid.UUID) (Record, error)
+}
+
+func NewRepository(database *storage.Database) Repository {
+	return &repository{db: database}
+}
+
+type repository struct {
+	db *storage.Database
+}
+
+func (r repository) Add(record Record) error {
+	result := r.db.Create(&record)
+	if err := result.Error; err != nil {
+		return errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return nil
+}
+
+func (r repository) Find(id uuid.UUID) (Record, error) {
+	var record Record
+
+	result := r.db.Where(Record{ID: id}).Limit(1).Find(&record)
+	if err := result.Error; err != nil {
+		return Record{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+	if result.RowsAffected == 0 {
+		return Record{}, errors.Error{Code: errors.ENOTFOUND, Message: errors.ErrReceiptNotFound}
+	}
+
+	return record, nil
+}
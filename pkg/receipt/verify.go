@@ -0,0 +1,107 @@
+package receipt
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bhojpur/wallet/pkg/engine"
+)
+
+var (
+	ErrMalformed        = errors.New("receipt is not a signed receipt")
+	ErrUnknownAlgorithm = fmt.Errorf("receipt is not signed with %s", Algorithm)
+	ErrUntrustedKey     = errors.New("receipt is not signed with the trusted key")
+	ErrBadSignature     = errors.New("receipt signature does not match its content")
+)
+
+// signed is a signed receipt with the receipt left as it was sent, the
+// signature is checked against these bytes rather than a decoded copy
+type signed struct {
+	Receipt   json.RawMessage `json:"receipt"`
+	Algorithm string          `json:"algorithm"`
+	PublicKey string          `json:"publicKey"`
+	Signature string          `json:"signature"`
+}
+
+// Verify checks that data is a receipt signed with the given public key, the X and Y
+// coordinates of an engine wallet key. It needs nothing but the key, so receipts can
+// be verified away from the server. The key the receipt names is only compared with
+// the trusted one, it can't be trusted on its own.
+func Verify(data []byte, publicKey []byte) (Signed, error) {
+	var s signed
+	if err := json.Unmarshal(data, &s); err != nil || len(s.Receipt) == 0 {
+		return Signed{}, ErrMalformed
+	}
+	if s.Algorithm != Algorithm {
+		return Signed{}, ErrUnknownAlgorithm
+	}
+	if s.PublicKey != hex.EncodeToString(publicKey) {
+		return Signed{}, ErrUntrustedKey
+	}
+
+	content, err := Canonical(s.Receipt)
+	if err != nil {
+		return Signed{}, ErrMalformed
+	}
+	sig, err := hex.DecodeString(s.Signature)
+	if err != nil {
+		return Signed{}, ErrMalformed
+	}
+
+	ok, err := engine.Verify(publicKey, engine.Sha256(content), sig)
+	if err != nil {
+		return Signed{}, err
+	}
+	if !ok {
+		return Signed{}, ErrBadSignature
+	}
+
+	var rct Receipt
+	if err := json.Unmarshal(s.Receipt, &rct); err != nil {
+		return Signed{}, ErrMalformed
+	}
+
+	return Signed{Receipt: rct, Algorithm: s.Algorithm, PublicKey: s.PublicKey, Signature: s.Signature}, nil
+}
+
+// sign signs the canonical JSON of the receipt with the engine wallet
+func sign(rct Receipt, signer *engine.Wallet) (Signed, []byte, error) {
+	content, err := rct.canonical()
+	if err != nil {
+		return Signed{}, nil, err
+	}
+
+	sig, err := signer.Sign(engine.Sha256(content))
+	if err != nil {
+		return Signed{}, nil, err
+	}
+
+	return Signed{
+		Receipt:   rct,
+		Algorithm: Algorithm,
+		PublicKey: hex.EncodeToString(signer.PublicKey),
+		Signature: hex.EncodeToString(sig),
+	}, content, nil
+}
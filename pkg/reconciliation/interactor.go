@@ -0,0 +1,217 @@
+package reconciliation
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"log"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/statement"
+	"github.com/bhojpur/wallet/pkg/user"
+	"github.com/bhojpur/wallet/pkg/worker"
+
+	"github.com/gofrs/uuid"
+)
+
+// how often every balance is checked against the ledger
+const jobInterval = 24 * time.Hour
+
+type Interactor interface {
+	Reconcile() (Report, error)
+	GetOpenMismatches() ([]Mismatch, error)
+	Repair(mismatchID, adminID uuid.UUID) (Mismatch, error)
+}
+
+// Report is the outcome of checking every account against the ledger
+type Report struct {
+	Accounts   int
+	Mismatches []Mismatch
+	// open mismatches of earlier runs that agree again
+	Resolved int
+}
+
+func NewInteractor(repository Repository, userRepo user.Repository, ledger statement.Ledger, workers *worker.Group) Interactor {
+	intr := &interactor{
+		repository: repository,
+		userRepo:   userRepo,
+		ledger:     ledger,
+	}
+
+	workers.Go("reconciliation job", intr.runReconciliationJob)
+
+	return intr
+}
+
+type interactor struct {
+	repository Repository
+	userRepo   user.Repository
+	ledger     statement.Ledger
+}
+
+// Reconcile works out the balance of every account from its statement entries and
+// compares it with the balance on the account. Accounts that disagree are recorded as
+// open mismatches, nothing is changed until an admin approves a repair.
+func (i interactor) Reconcile() (Report, error) {
+	balances, err := i.repository.GetBalances()
+	if err != nil {
+		return Report{}, err
+	}
+
+	mismatches, err := i.repository.GetOpenMismatches()
+	if err != nil {
+		return Report{}, err
+	}
+	open := map[uuid.UUID]Mismatch{}
+	for _, m := range mismatches {
+		open[m.AccountID] = m
+	}
+
+	report := Report{Accounts: len(balances)}
+	now := time.Now()
+	for _, b := range balances {
+		m, wasOpen := open[b.ID]
+
+		if b.AccountBalance() == b.LedgerBalance() {
+			if !wasOpen {
+				continue
+			}
+			m.Status, m.ResolvedAt = StatusResolved, &now
+			if _, err := i.repository.SaveMismatch(m); err != nil {
+				return report, err
+			}
+			report.Resolved++
+			continue
+		}
+
+		m.AccountID, m.UserID, m.AccountNumber = b.ID, b.UserID, b.AccountNumber
+		m.AccountBalance, m.LedgerBalance, m.Entries = b.AccountBalance(), b.LedgerBalance(), b.Entries
+		m.Status, m.DetectedAt = StatusOpen, now
+
+		m, err = i.repository.SaveMismatch(m)
+		if err != nil {
+			return report, err
+		}
+
+		log.Printf("account %v balance %v does not match ledger balance %v from %v entries",
+			m.AccountNumber, m.AccountBalance, m.LedgerBalance, m.Entries)
+		report.Mismatches = append(report.Mismatches, m)
+	}
+
+	return report, nil
+}
+
+func (i interactor) GetOpenMismatches() ([]Mismatch, error) {
+	return i.repository.GetOpenMismatches()
+}
+
+// Repair posts a correcting entry to the ledger of an account with an open mismatch, so that
+// its entries add up to the balance again. The balance itself is never edited. The difference
+// is worked out again at the time of repair, a mismatch that went away is only resolved.
+func (i interactor) Repair(mismatchID, adminID uuid.UUID) (Mismatch, error) {
+	approver, err := i.userRepo.FindByID(adminID)
+	if errors.ErrorCode(err) == errors.ENOTFOUND || (err == nil && approver.UserType != models.UserTypAdmin) {
+		return Mismatch{}, errors.Error{Code: errors.EINVALID, Message: errors.ErrRepairNeedsAdmin}
+	} else if err != nil {
+		return Mismatch{}, err
+	}
+
+	m, err := i.repository.FindMismatch(mismatchID)
+	if err != nil {
+		return Mismatch{}, err
+	}
+
+	// claim the mismatch so that it is only ever repaired once
+	claimed, err := i.repository.ClaimMismatch(m.ID, StatusOpen, StatusRepaired)
+	if err != nil {
+		return Mismatch{}, err
+	}
+	if !claimed {
+		return Mismatch{}, errors.Error{Code: errors.ECONFLICT, Message: errors.ErrMismatchNotOpen}
+	}
+
+	m, err = i.repair(m, adminID)
+	if err != nil {
+		if _, e := i.repository.ClaimMismatch(m.ID, StatusRepaired, StatusOpen); e != nil {
+			log.Printf("error happened while reopening mismatch %v: %v", m.ID, e)
+		}
+		return Mismatch{}, err
+	}
+
+	return m, nil
+}
+
+func (i interactor) repair(m Mismatch, adminID uuid.UUID) (Mismatch, error) {
+	b, err := i.repository.GetAccountBalances(m.AccountID)
+	if err != nil {
+		return m, err
+	}
+
+	now := time.Now()
+	m.AccountBalance, m.LedgerBalance, m.Entries = b.AccountBalance(), b.LedgerBalance(), b.Entries
+	m.ApprovedBy, m.ResolvedAt = &adminID, &now
+
+	diff := m.Difference()
+	if diff == 0 {
+		m.Status = StatusResolved
+		return i.repository.SaveMismatch(m)
+	}
+
+	// a credit raises the ledger balance of a credit-normal account, a debit that of a debit-normal one
+	stmtType := statement.TypeCredit
+	if (diff < 0) != b.IsDebitNormal() {
+		stmtType = statement.TypeDebit
+	}
+	if diff < 0 {
+		diff = -diff
+	}
+
+	approver := models.TxnCustomer{UserID: adminID, UserType: models.UserTypAdmin}
+	err = i.ledger.Record(b.UserID, b.Account, models.TxnOpReconciliation, models.Paisas(diff), stmtType, approver)
+	if err != nil {
+		return m, err
+	}
+
+	m.LedgerBalance = m.AccountBalance
+	m.Entries++
+	m.Status = StatusRepaired
+	return i.repository.SaveMismatch(m)
+}
+
+func (i interactor) runReconciliationJob(done <-chan struct{}) {
+	ticker := time.NewTicker(jobInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+		report, err := i.Reconcile()
+		if err != nil {
+			log.Printf("error happened while reconciling balances %v", err)
+			continue
+		}
+		log.Printf("reconciled %d accounts, %d mismatches, %d resolved", report.Accounts, len(report.Mismatches), report.Resolved)
+	}
+}
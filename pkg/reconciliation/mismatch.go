@@ -0,0 +1,104 @@
+package reconciliation
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type Status string
+
+const (
+	// the balance and the ledger disagree
+	StatusOpen = Status("open")
+	// a correcting entry was posted once an admin approved it
+	StatusRepaired = Status("repaired")
+	// they agreed again without a repair, e.g. a transaction was in flight
+	StatusResolved = Status("resolved")
+)
+
+// Mismatch records an account whose balance isn't what its statement entries add up to.
+// Amounts are signed paisas net of any overdraft, an account has one open mismatch at most.
+type Mismatch struct {
+	ID            uuid.UUID
+	AccountID     uuid.UUID `gorm:"column:account_id;not null;index"`
+	UserID        uuid.UUID `gorm:"column:user_id;not null"`
+	AccountNumber string    `gorm:"column:account_number"`
+
+	// net balance on the account and what its statement entries add up to
+	AccountBalance int64 `gorm:"column:account_balance"`
+	LedgerBalance  int64 `gorm:"column:ledger_balance"`
+	// number of statement entries the ledger balance was worked out from
+	Entries int64 `gorm:"column:entries"`
+
+	Status     Status    `gorm:"column:status;not null;index"`
+	DetectedAt time.Time `gorm:"column:detected_at"`
+
+	ApprovedBy *uuid.UUID `gorm:"column:approved_by;type:uuid"`
+	ResolvedAt *time.Time `gorm:"column:resolved_at"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Difference is what the ledger is missing, positive when it needs a credit
+func (m Mismatch) Difference() int64 {
+	return m.AccountBalance - m.LedgerBalance
+}
+
+func (m *Mismatch) BeforeCreate(tx *gorm.DB) error {
+	m.ID, _ = uuid.NewV4()
+	return nil
+}
+
+func (Mismatch) TableName() string {
+	return "reconciliation_mismatches"
+}
+
+// Balances is an account with the totals of its statement entries
+type Balances struct {
+	models.Account
+
+	Credits int64 // paisas
+	Debits  int64 // paisas
+	Entries int64
+}
+
+// AccountBalance returns the net balance of the account, negative while an overdraft is drawn
+func (b Balances) AccountBalance() int64 {
+	if b.IsDebitNormal() {
+		return int64(b.AvailableBalance)
+	}
+	return int64(b.AvailableBalance) - int64(b.OverdraftUsed)
+}
+
+// LedgerBalance returns what the statement entries of the account add up to
+func (b Balances) LedgerBalance() int64 {
+	if b.IsDebitNormal() {
+		return b.Debits - b.Credits
+	}
+	return b.Credits - b.Debits
+}
@@ -0,0 +1,134 @@
+package reconciliation
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/storage"
+
+	"github.com/gofrs/uuid"
+)
+
+// balancesQuery joins every account with the totals of its statement entries, entries are
+// rounded to paisas one by one as that is how they were recorded
+const balancesQuery = `
+SELECT a.*, COALESCE(s.credits, 0) AS credits, COALESCE(s.debits, 0) AS debits, COALESCE(s.entries, 0) AS entries
+FROM accounts a
+LEFT JOIN (
+	SELECT account_id,
+		SUM(ROUND(credit_amount * 100))::bigint AS credits,
+		SUM(ROUND(debit_amount * 100))::bigint AS debits,
+		COUNT(*) AS entries
+	FROM statements
+	GROUP BY account_id
+) s ON s.account_id = a.id
+WHERE a.deleted_at IS NULL`
+
+type Repository interface {
+	GetBalances() ([]Balances, error)
+	GetAccountBalances(accountID uuid.UUID) (Balances, error)
+
+	GetOpenMismatches() ([]Mismatch, error)
+	FindMismatch(id uuid.UUID) (Mismatch, error)
+	SaveMismatch(Mismatch) (Mismatch, error)
+	ClaimMismatch(id uuid.UUID, from, to Status) (bool, error)
+}
+
+func NewRepository(database *storage.Database) Repository {
+	return &repository{db: database}
+}
+
+type repository struct {
+	db *storage.Database
+}
+
+// GetBalances fetches every account with the totals of its statement entries
+func (r repository) GetBalances() ([]Balances, error) {
+	var balances []Balances
+
+	result := r.db.Raw(balancesQuery + " ORDER BY a.created_at").Scan(&balances)
+	if err := result.Error; err != nil {
+		return nil, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return balances, nil
+}
+
+// GetAccountBalances fetches an account with the totals of its statement entries
+func (r repository) GetAccountBalances(accountID uuid.UUID) (Balances, error) {
+	var balances Balances
+
+	result := r.db.Raw(balancesQuery+" AND a.id = ?", accountID).Scan(&balances)
+	if err := result.Error; err != nil {
+		return Balances{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+	if result.RowsAffected == 0 {
+		return Balances{}, errors.Error{Code: errors.ENOTFOUND, Message: errors.AccountNotCreated}
+	}
+
+	return balances, nil
+}
+
+func (r repository) GetOpenMismatches() ([]Mismatch, error) {
+	var mismatches []Mismatch
+
+	result := r.db.Where(Mismatch{Status: StatusOpen}).Order("detected_at").Find(&mismatches)
+	if err := result.Error; err != nil {
+		return nil, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return mismatches, nil
+}
+
+func (r repository) FindMismatch(id uuid.UUID) (Mismatch, error) {
+	var mismatch Mismatch
+
+	result := r.db.Where(Mismatch{ID: id}).Limit(1).Find(&mismatch)
+	if err := result.Error; err != nil {
+		return Mismatch{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+	if result.RowsAffected == 0 {
+		return Mismatch{}, errors.Error{Code: errors.ENOTFOUND, Message: errors.ErrMismatchNotFound}
+	}
+
+	return mismatch, nil
+}
+
+// SaveMismatch creates a mismatch or updates an existing one
+func (r repository) SaveMismatch(mismatch Mismatch) (Mismatch, error) {
+	result := r.db.Save(&mismatch)
+	if err := result.Error; err != nil {
+		return Mismatch{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return mismatch, nil
+}
+
+// ClaimMismatch moves a mismatch from one status to another, it returns false if the
+// mismatch wasn't in the from status. Two admins repairing at once can't both post.
+func (r repository) ClaimMismatch(id uuid.UUID, from, to Status) (bool, error) {
+	result := r.db.Model(&Mismatch{}).Where("id = ? AND status = ?", id, from).Update("status", to)
+	if err := result.Error; err != nil {
+		return false, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -31,21 +31,11 @@ type Channels struct {
 }
 
 func NewChannels() *Channels {
-	chanNewUsers := make(chan data.CustomerContract, 10)
-	chanNewTransactions := make(chan data.TransactionContract, 50)
 	// chanNewTxnEvents := make(chan models.TxnEvent, 100)
 
 	return &Channels{
-		ChannelNewUsers: data.ChanNewCustomers{
-			Channel: chanNewUsers,
-			Reader:  chanNewUsers,
-			Writer:  chanNewUsers,
-		},
-		ChannelNewTransactions: data.ChanNewTransactions{
-			Channel: chanNewTransactions,
-			Reader:  chanNewTransactions,
-			Writer:  chanNewTransactions,
-		},
+		ChannelNewUsers:        data.NewChanNewCustomers(10),
+		ChannelNewTransactions: data.NewChanNewTransactions(50),
 		// ChannelTxnEvents: data.ChanNewTxnEvents{
 		// 	Channel: chanNewTxnEvents,
 		// 	Reader:  chanNewTxnEvents,
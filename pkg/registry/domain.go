@@ -21,18 +21,34 @@ package registry
 // THE SOFTWARE.
 
 import (
+	"context"
+	"log"
+
 	"github.com/bhojpur/wallet/pkg/account"
 	"github.com/bhojpur/wallet/pkg/admin"
 	"github.com/bhojpur/wallet/pkg/agent"
 	"github.com/bhojpur/wallet/pkg/config"
 	"github.com/bhojpur/wallet/pkg/customer"
+	"github.com/bhojpur/wallet/pkg/engine"
+	"github.com/bhojpur/wallet/pkg/estatement"
+	"github.com/bhojpur/wallet/pkg/export"
+	"github.com/bhojpur/wallet/pkg/health"
+	"github.com/bhojpur/wallet/pkg/interest"
 	"github.com/bhojpur/wallet/pkg/merchant"
+	"github.com/bhojpur/wallet/pkg/metrics"
+	"github.com/bhojpur/wallet/pkg/overdraft"
+	"github.com/bhojpur/wallet/pkg/pocket"
 	"github.com/bhojpur/wallet/pkg/ports"
+	"github.com/bhojpur/wallet/pkg/receipt"
+	"github.com/bhojpur/wallet/pkg/reconciliation"
 	"github.com/bhojpur/wallet/pkg/statement"
 	"github.com/bhojpur/wallet/pkg/storage"
+	"github.com/bhojpur/wallet/pkg/storage/postgres"
 	"github.com/bhojpur/wallet/pkg/subscriber"
 	"github.com/bhojpur/wallet/pkg/tariff"
 	"github.com/bhojpur/wallet/pkg/transaction"
+	"github.com/bhojpur/wallet/pkg/user"
+	"github.com/bhojpur/wallet/pkg/worker"
 )
 
 type Domain struct {
@@ -42,40 +58,121 @@ type Domain struct {
 	Subscriber subscriber.Interactor
 
 	Account     account.Interactor
+	Overdraft   overdraft.Interactor
+	Interest    interest.Interactor
+	Pocket      pocket.Interactor
 	Transaction transaction.Interactor
 	Statement   statement.Interactor
+	Export      export.Interactor
+	EStatement  estatement.Interactor
+	Reconciler  reconciliation.Interactor
+	Auditor     statement.Auditor
+	Receipt     receipt.Interactor
 	Tariff      tariff.Manager
 
 	Transactor ports.TransactorPort
+
+	// Health checks the database and the background of the domain
+	Health *health.Checker
+	// Metrics of the transactions, the database and the background of the domain
+	Metrics *metrics.Metrics
+
+	database *storage.Database
+	channels *Channels
+	workers  *worker.Group
 }
 
-func NewDomain(config config.Config, database *storage.Database, channels *Channels) *Domain {
+func NewDomain(config config.Config, database *storage.Database, channels *Channels, signer *engine.Wallet) *Domain {
 	adminRepo := admin.NewRepository(database)
 	agentRepo := agent.NewRepository(database)
 	merchantRepo := merchant.NewRepository(database)
 	subscriberRepo := subscriber.NewRepository(database)
+	userRepo := user.NewRepository(database)
 
 	accRepo := account.NewRepository(database)
 	txnRepo := transaction.NewRepository(database)
 	statementRepo := statement.NewRepository(database)
 	tariffRepo := tariff.NewRepository(database)
+	interestRepo := interest.NewRepository(database)
+	pocketRepo := pocket.NewRepository(database)
+	eStatementRepo := estatement.NewRepository(database)
+	reconciliationRepo := reconciliation.NewRepository(database)
+	receiptRepo := receipt.NewRepository(database)
+
+	// the background workers of the domain, stopped by Shutdown
+	workers := worker.NewGroup()
 
 	// initialize ports and adapters
 	ledger := statement.NewLedger(statementRepo)
-	tariffManager := tariff.NewManager(tariffRepo)
+	tariffManager := tariff.NewManager(tariffRepo, workers)
 	accountant := account.NewAccountant(accRepo, ledger)
-	customerFinder := customer.NewFinder(agentRepo, merchantRepo, subscriberRepo)
-	transactor := transaction.NewTransactor(accountant, tariffManager)
+	customerFinder := customer.NewFinder(userRepo, agentRepo, merchantRepo, subscriberRepo, accRepo)
+	m := metrics.New()
+	if err := m.WatchDatabase(database); err != nil {
+		log.Printf("error watching the database for metrics %v", err)
+	}
+	m.WatchChannel("new_customers", channels.ChannelNewUsers.Pending)
+	m.WatchChannel("new_transactions", channels.ChannelNewTransactions.Pending)
+
+	transactor := metrics.NewTransactor(transaction.NewTransactor(accountant, tariffManager), m)
+	exporter := export.NewInteractor(statementRepo, accRepo, export.DefaultBranding)
+	issuer := receipt.NewInteractor(receiptRepo, signer)
+
+	checker := health.NewChecker()
+	checker.Add("database", database.Ping)
+	checker.Add("migrations", func(ctx context.Context) error {
+		return postgres.CheckMigrations(ctx, database)
+	})
+	checker.Add("tariff", tariffManager.Ready)
+	// a dead worker only comes back with a restart
+	checker.AddLive("workers", workers.Check)
 
 	return &Domain{
 		Admin:       admin.NewInteractor(config, adminRepo, accountant, customerFinder),
 		Agent:       agent.NewInteractor(config, agentRepo, channels.ChannelNewUsers),
 		Merchant:    merchant.NewInteractor(config, merchantRepo, channels.ChannelNewUsers),
 		Subscriber:  subscriber.NewInteractor(config, subscriberRepo, channels.ChannelNewUsers),
-		Account:     account.NewInteractor(accRepo, channels.ChannelNewUsers, channels.ChannelNewTransactions),
-		Transaction: transaction.NewInteractor(txnRepo, channels.ChannelNewTransactions),
+		Account:     account.NewInteractor(accRepo, channels.ChannelNewUsers, channels.ChannelNewTransactions, workers),
+		Overdraft:   overdraft.NewInteractor(accRepo, ledger, accountant, customerFinder, workers),
+		Interest:    interest.NewInteractor(interestRepo, accRepo, statementRepo, accountant, customerFinder, workers),
+		Pocket:      pocket.NewInteractor(pocketRepo, accRepo, accountant, pocket.DefaultPolicy),
+		Transaction: transaction.NewInteractor(txnRepo, channels.ChannelNewTransactions, workers),
 		Statement:   statement.NewInteractor(statementRepo),
-		Transactor:  ports.NewTransactor(customerFinder, transactor),
+		Export:      exporter,
+		EStatement:  estatement.NewInteractor(eStatementRepo, exporter, estatement.LogNotifier{}, workers),
+		Reconciler:  reconciliation.NewInteractor(reconciliationRepo, userRepo, ledger, workers),
+		Auditor:     statement.NewAuditor(statementRepo, signer, workers),
+		Receipt:     issuer,
+		Transactor:  ports.NewTransactor(customerFinder, transactor, issuer),
 		Tariff:      tariffManager,
+		Health:      checker,
+		Metrics:     m,
+
+		database: database,
+		channels: channels,
+		workers:  workers,
+	}
+}
+
+// Start runs the background workers of the domain, the listeners on its channels
+// and its scheduled jobs. Only the server starts them, commands that run a job
+// once use the domain without them.
+func (d *Domain) Start() {
+	d.workers.Start()
+}
+
+// Shutdown stops the background workers and closes the database. The workers
+// finish the runs of their jobs and what the channels hold first, those still
+// running when ctx ends are left behind.
+func (d *Domain) Shutdown(ctx context.Context) error {
+	err := d.workers.Stop(ctx)
+	if err != nil {
+		log.Printf("%v, %d new customers and %d transactions were not processed", err,
+			d.channels.ChannelNewUsers.Pending(), d.channels.ChannelNewTransactions.Pending())
+	}
+
+	if closeErr := d.database.Close(); closeErr != nil && err == nil {
+		err = closeErr
 	}
+	return err
 }
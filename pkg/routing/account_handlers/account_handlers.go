@@ -27,6 +27,7 @@ import (
 	"github.com/bhojpur/wallet/pkg/auth"
 	"github.com/bhojpur/wallet/pkg/errors"
 	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/overdraft"
 	"github.com/bhojpur/wallet/pkg/routing/responses"
 	"github.com/bhojpur/wallet/pkg/statement"
 
@@ -49,12 +50,12 @@ func BalanceEnquiry(interactor account.Interactor) fiber.Handler {
 			return errors.Error{Code: errors.EINVALID, Message: errors.UserCantHaveAccount}
 		}
 
-		balance, err := interactor.GetBalance(userDetails.UserID)
+		acc, err := interactor.GetAccount(userDetails.UserID)
 		if err != nil {
 			return err
 		}
 
-		return ctx.Status(http.StatusOK).JSON(responses.BalanceResponse(userDetails.UserID, balance))
+		return ctx.Status(http.StatusOK).JSON(responses.BalanceResponse(userDetails.UserID, acc))
 	}
 }
 
@@ -78,3 +79,73 @@ func MiniStatement(interactor statement.Interactor) fiber.Handler {
 		return ctx.Status(http.StatusOK).JSON(responses.MiniStatementResponse(userDetails.UserID, statements))
 	}
 }
+
+// Statement returns a page of the transactions on an account in a period,
+// with the running balance and the opening and closing balances of the period.
+func Statement(interactor statement.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		var userDetails auth.UserAuthDetails
+		if details, ok := ctx.Locals("userDetails").(auth.UserAuthDetails); !ok {
+			return errors.Error{Code: errors.EINTERNAL}
+		} else {
+			userDetails = details
+		}
+
+		var params statement.Params
+		_ = ctx.QueryParser(&params)
+
+		err := params.Validate()
+		if err != nil {
+			return err
+		}
+
+		filter := params.Filter()
+		page, err := interactor.GetStatementPage(userDetails.UserID, filter)
+		if err != nil {
+			return err
+		}
+
+		return ctx.Status(http.StatusOK).JSON(responses.StatementResponse(userDetails.UserID, filter, page))
+	}
+}
+
+// OverdraftOptIn turns on the overdraft facility of a subscriber's account
+func OverdraftOptIn(interactor overdraft.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		var userDetails auth.UserAuthDetails
+		if details, ok := ctx.Locals("userDetails").(auth.UserAuthDetails); !ok {
+			return errors.Error{Code: errors.EINTERNAL}
+		} else {
+			userDetails = details
+		}
+
+		acc, err := interactor.OptIn(userDetails.UserID, userDetails.UserType)
+		if err != nil {
+			return err
+		}
+
+		return ctx.Status(http.StatusOK).JSON(responses.OverdraftResponse("overdraft facility turned on", acc))
+	}
+}
+
+// OverdraftOptOut turns off the overdraft facility of a subscriber's account
+func OverdraftOptOut(interactor overdraft.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		var userDetails auth.UserAuthDetails
+		if details, ok := ctx.Locals("userDetails").(auth.UserAuthDetails); !ok {
+			return errors.Error{Code: errors.EINTERNAL}
+		} else {
+			userDetails = details
+		}
+
+		acc, err := interactor.OptOut(userDetails.UserID, userDetails.UserType)
+		if err != nil {
+			return err
+		}
+
+		return ctx.Status(http.StatusOK).JSON(responses.OverdraftResponse("overdraft facility turned off", acc))
+	}
+}
@@ -0,0 +1,83 @@
+package account_handlers
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/auth"
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/estatement"
+	"github.com/bhojpur/wallet/pkg/routing/responses"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListEStatements returns the monthly statements generated for the customer
+func ListEStatements(interactor estatement.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		var userDetails auth.UserAuthDetails
+		if details, ok := ctx.Locals("userDetails").(auth.UserAuthDetails); !ok {
+			return errors.Error{Code: errors.EINTERNAL}
+		} else {
+			userDetails = details
+		}
+
+		docs, err := interactor.List(userDetails.UserID)
+		if err != nil {
+			return err
+		}
+
+		return ctx.Status(http.StatusOK).JSON(responses.EStatementsResponse(docs))
+	}
+}
+
+// DownloadEStatement returns the statement of a month, given as YYYY-MM
+func DownloadEStatement(interactor estatement.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		var userDetails auth.UserAuthDetails
+		if details, ok := ctx.Locals("userDetails").(auth.UserAuthDetails); !ok {
+			return errors.Error{Code: errors.EINTERNAL}
+		} else {
+			userDetails = details
+		}
+
+		month, err := time.ParseInLocation("2006-01", ctx.Params("month"), time.Local)
+		if err != nil {
+			return errors.Error{Code: errors.ENOTFOUND, Message: errors.EStatementNotFound}
+		}
+
+		doc, err := interactor.Get(userDetails.UserID, month)
+		if err != nil {
+			return err
+		}
+
+		ctx.Set(fiber.HeaderContentType, doc.Format.ContentType())
+		ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", doc.FileName))
+		ctx.Set(fiber.HeaderETag, fmt.Sprintf("%q", doc.ContentHash))
+
+		return ctx.Status(http.StatusOK).Send(doc.Content)
+	}
+}
@@ -0,0 +1,83 @@
+package account_handlers
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+
+	"github.com/bhojpur/wallet/pkg/auth"
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/export"
+	"github.com/bhojpur/wallet/pkg/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExportStatement downloads the statement of the customer's account in a period
+// as csv, pdf, ofx, mt940 or camt053
+func ExportStatement(interactor export.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		var userDetails auth.UserAuthDetails
+		if details, ok := ctx.Locals("userDetails").(auth.UserAuthDetails); !ok {
+			return errors.Error{Code: errors.EINTERNAL}
+		} else {
+			userDetails = details
+		}
+
+		// we check if user is admin, we return error
+		if userDetails.UserType == models.UserTypAdmin {
+			return errors.Error{Code: errors.EINVALID, Message: errors.UserCantHaveAccount}
+		}
+
+		var params export.Params
+		_ = ctx.QueryParser(&params)
+
+		err := params.Validate()
+		if err != nil {
+			return err
+		}
+
+		exp, err := interactor.Export(userDetails.UserID, params)
+		if err != nil {
+			return err
+		}
+
+		return StreamExport(ctx, exp)
+	}
+}
+
+// StreamExport writes out an export as a download. The status has been sent by the
+// time the statement is read, so errors after that can only be logged.
+func StreamExport(ctx *fiber.Ctx, exp export.Export) error {
+	ctx.Set(fiber.HeaderContentType, exp.Format.ContentType())
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", exp.FileName()))
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := exp.Render(w); err != nil {
+			log.Printf("error happened while exporting statement %v: %v", exp.FileName(), err)
+		}
+	})
+
+	return nil
+}
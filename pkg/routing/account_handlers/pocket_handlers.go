@@ -0,0 +1,186 @@
+package account_handlers
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"net/http"
+
+	"github.com/bhojpur/wallet/pkg/auth"
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/pocket"
+	"github.com/bhojpur/wallet/pkg/routing/responses"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofrs/uuid"
+)
+
+// pocketID reads the id of the pocket from the route
+func pocketID(ctx *fiber.Ctx) (uuid.UUID, error) {
+	id, err := uuid.FromString(ctx.Params("pocket_id"))
+	if err != nil {
+		return uuid.Nil, errors.Error{Code: errors.ENOTFOUND, Message: errors.ErrPocketNotFound}
+	}
+	return id, nil
+}
+
+// ListPockets returns the pockets of the customer
+func ListPockets(interactor pocket.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		var userDetails auth.UserAuthDetails
+		if details, ok := ctx.Locals("userDetails").(auth.UserAuthDetails); !ok {
+			return errors.Error{Code: errors.EINTERNAL}
+		} else {
+			userDetails = details
+		}
+
+		pockets, err := interactor.List(userDetails.UserID)
+		if err != nil {
+			return err
+		}
+
+		return ctx.Status(http.StatusOK).JSON(responses.PocketsResponse("pockets retrieved", pockets...))
+	}
+}
+
+// CreatePocket adds a savings goal to the customer's account
+func CreatePocket(interactor pocket.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		var userDetails auth.UserAuthDetails
+		if details, ok := ctx.Locals("userDetails").(auth.UserAuthDetails); !ok {
+			return errors.Error{Code: errors.EINTERNAL}
+		} else {
+			userDetails = details
+		}
+
+		var params pocket.CreateParams
+		_ = ctx.BodyParser(&params)
+
+		err := params.Validate()
+		if err != nil {
+			return err
+		}
+
+		customer := models.TxnCustomer{UserID: userDetails.UserID, UserType: userDetails.UserType}
+		p, err := interactor.Create(customer, params)
+		if err != nil {
+			return err
+		}
+
+		return ctx.Status(http.StatusOK).JSON(responses.PocketsResponse("pocket created", p))
+	}
+}
+
+// PocketDeposit moves money from the spendable balance into a pocket
+func PocketDeposit(interactor pocket.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		var userDetails auth.UserAuthDetails
+		if details, ok := ctx.Locals("userDetails").(auth.UserAuthDetails); !ok {
+			return errors.Error{Code: errors.EINTERNAL}
+		} else {
+			userDetails = details
+		}
+
+		id, err := pocketID(ctx)
+		if err != nil {
+			return err
+		}
+
+		var params pocket.MoveParams
+		_ = ctx.BodyParser(&params)
+
+		err = params.Validate()
+		if err != nil {
+			return err
+		}
+
+		p, err := interactor.Deposit(userDetails.UserID, id, params)
+		if err != nil {
+			return err
+		}
+
+		return ctx.Status(http.StatusOK).JSON(responses.PocketsResponse("money moved into pocket", p))
+	}
+}
+
+// PocketWithdraw moves money from a pocket back into the spendable balance
+func PocketWithdraw(interactor pocket.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		var userDetails auth.UserAuthDetails
+		if details, ok := ctx.Locals("userDetails").(auth.UserAuthDetails); !ok {
+			return errors.Error{Code: errors.EINTERNAL}
+		} else {
+			userDetails = details
+		}
+
+		id, err := pocketID(ctx)
+		if err != nil {
+			return err
+		}
+
+		var params pocket.MoveParams
+		_ = ctx.BodyParser(&params)
+
+		err = params.Validate()
+		if err != nil {
+			return err
+		}
+
+		p, err := interactor.Withdraw(userDetails.UserID, id, params)
+		if err != nil {
+			return err
+		}
+
+		return ctx.Status(http.StatusOK).JSON(responses.PocketsResponse("money moved out of pocket", p))
+	}
+}
+
+// ClosePocket removes an empty pocket
+func ClosePocket(interactor pocket.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		var userDetails auth.UserAuthDetails
+		if details, ok := ctx.Locals("userDetails").(auth.UserAuthDetails); !ok {
+			return errors.Error{Code: errors.EINTERNAL}
+		} else {
+			userDetails = details
+		}
+
+		id, err := pocketID(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = interactor.Close(userDetails.UserID, id)
+		if err != nil {
+			return err
+		}
+
+		return ctx.Status(http.StatusOK).JSON(responses.SuccessResponse{
+			Status:  "success",
+			Message: "pocket closed",
+		})
+	}
+}
@@ -38,6 +38,13 @@ func ErrorHandler(ctx *fiber.Ctx, err error) error {
 		return ctx.Status(res.Status).JSON(res)
 	}
 
+	// if error corresponds to forbidden
+	if e, ok := err.(errors.Forbidden); ok {
+		log.Println(err)
+		res := errors.ForbiddenResponse(e.Error())
+		return ctx.Status(res.Status).JSON(res)
+	}
+
 	// if error is our custom validation errors slice type
 	if e, ok := err.(errors.ValidationErrors); ok {
 		log.Println(err)
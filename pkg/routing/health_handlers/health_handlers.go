@@ -0,0 +1,54 @@
+package health_handlers
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"net/http"
+
+	"github.com/bhojpur/wallet/pkg/health"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Liveness answers the liveness probe, the server is restarted when it fails
+func Liveness(checker *health.Checker) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		return report(ctx, checker.Live(ctx.Context()))
+	}
+}
+
+// Readiness answers the readiness probe, the server gets no requests while
+// it fails
+func Readiness(checker *health.Checker) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		return report(ctx, checker.Ready(ctx.Context()))
+	}
+}
+
+// report sends the status of each component, with 503 when one is down
+func report(ctx *fiber.Ctx, report health.Report) error {
+	if !report.Up() {
+		return ctx.Status(http.StatusServiceUnavailable).JSON(report)
+	}
+	return ctx.Status(http.StatusOK).JSON(report)
+}
@@ -21,12 +21,10 @@ package middleware
 // THE SOFTWARE.
 
 import (
-	"strings"
-
 	"github.com/bhojpur/wallet/pkg/auth"
 	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -40,26 +38,25 @@ func AuthByBearerToken(secret string) fiber.Handler {
 			return errors.Unauthorized{Message: "authorization header not set"}
 		}
 
-		// check that the token value in header is set
-		bearer := strings.Split(header, " ")
-		if len(bearer) < 2 || bearer[1] == "" {
-			return errors.Unauthorized{Message: "authentication token not set"}
+		userDetails, err := auth.Authenticate(header, secret)
+		if err != nil {
+			return err
 		}
 
-		var claims auth.TokenClaims
-		token, err := auth.ParseToken(bearer[1], secret, &claims)
-		if err != nil {
-			if err == jwt.ErrSignatureInvalid {
-				return errors.Unauthorized{Message: "invalid signature on token"}
-			}
+		ctx.Locals("userDetails", userDetails)
 
-			return errors.Unauthorized{Message: "token has expired or is invalid"}
-		}
-		if valid := auth.ValidateToken(token); !valid {
-			return errors.Unauthorized{Message: "invalid token"}
-		}
+		return ctx.Next()
+	}
+}
+
+// AdminOnly only lets administrators through, it goes after AuthByBearerToken
+func AdminOnly() fiber.Handler {
 
-		ctx.Locals("userDetails", claims.User)
+	return func(ctx *fiber.Ctx) error {
+		details, ok := ctx.Locals("userDetails").(auth.UserAuthDetails)
+		if !ok || details.UserType != models.UserTypAdmin {
+			return errors.ErrAdminOnly
+		}
 
 		return ctx.Next()
 	}
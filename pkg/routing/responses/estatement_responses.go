@@ -0,0 +1,50 @@
+package responses
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/estatement"
+)
+
+type eStatementResponse struct {
+	Month       string     `json:"month"`
+	FileName    string     `json:"fileName"`
+	ContentHash string     `json:"sha256"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	NotifiedAt  *time.Time `json:"sentAt,omitempty"`
+}
+
+func EStatementsResponse(docs []estatement.Document) SuccessResponse {
+	resp := []eStatementResponse{}
+	for _, doc := range docs {
+		resp = append(resp, eStatementResponse{
+			Month:       doc.Month.Format("2006-01"),
+			FileName:    doc.FileName,
+			ContentHash: doc.ContentHash,
+			CreatedAt:   doc.CreatedAt,
+			NotifiedAt:  doc.NotifiedAt,
+		})
+	}
+
+	return successResponse("monthly statements retrieved", resp)
+}
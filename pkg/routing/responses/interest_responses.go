@@ -0,0 +1,81 @@
+package responses
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/helpers"
+	"github.com/bhojpur/wallet/pkg/interest"
+	"github.com/bhojpur/wallet/pkg/models"
+
+	"github.com/gofrs/uuid"
+)
+
+type interestRateResponse struct {
+	AccountType   models.AccountType `json:"accountType"`
+	AnnualRateBps uint               `json:"rate"`
+}
+
+func InterestRatesResponse(rates []interest.Rate) SuccessResponse {
+	var resp []interestRateResponse
+	for _, rate := range rates {
+		resp = append(resp, interestRateResponse{
+			AccountType:   rate.AccountType,
+			AnnualRateBps: rate.AnnualRateBps,
+		})
+	}
+
+	msg := "Interest rates retrieved"
+	return successResponse(msg, resp)
+}
+
+type interestAccrualResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	AccountID       uuid.UUID  `json:"accountId"`
+	Day             string     `json:"day"`
+	EndOfDayBalance float64    `json:"endOfDayBalance"`
+	AnnualRateBps   uint       `json:"rate"`
+	Amount          float64    `json:"amount"`
+	PostedAt        *time.Time `json:"postedAt"`
+	// the fraction of a Paisa left over by the posting of the month before
+	Carried bool `json:"carried,omitempty"`
+}
+
+func InterestAccrualsResponse(accruals []interest.Accrual) SuccessResponse {
+	var resp []interestAccrualResponse
+	for _, accrual := range accruals {
+		resp = append(resp, interestAccrualResponse{
+			ID:              accrual.ID,
+			AccountID:       accrual.AccountID,
+			Day:             accrual.Day.Format(helpers.DateLayout),
+			EndOfDayBalance: accrual.EndOfDayBalance.ToFloat(),
+			AnnualRateBps:   accrual.AnnualRateBps,
+			// accruals are kept in Paisas
+			Amount:   accrual.Amount / 100,
+			PostedAt: accrual.PostedAt,
+			Carried:  accrual.Carried,
+		})
+	}
+
+	msg := "Interest accruals retrieved"
+	return successResponse(msg, resp)
+}
@@ -0,0 +1,54 @@
+package responses
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/pocket"
+
+	"github.com/gofrs/uuid"
+)
+
+type pocketResponse struct {
+	ID          uuid.UUID  `json:"pocketId"`
+	Name        string     `json:"name"`
+	Balance     float64    `json:"balance"`
+	Target      float64    `json:"target,omitempty"`
+	Progress    float64    `json:"progress,omitempty"`
+	LockedUntil *time.Time `json:"lockedUntil,omitempty"`
+}
+
+func PocketsResponse(message string, pockets ...pocket.Pocket) SuccessResponse {
+	resp := []pocketResponse{}
+	for _, p := range pockets {
+		resp = append(resp, pocketResponse{
+			ID:          p.ID,
+			Name:        p.Name,
+			Balance:     p.Balance.ToFloat(),
+			Target:      p.Target.ToFloat(),
+			Progress:    p.Progress(),
+			LockedUntil: p.LockedUntil,
+		})
+	}
+
+	return successResponse(message, resp)
+}
@@ -0,0 +1,92 @@
+package responses
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/reconciliation"
+
+	"github.com/gofrs/uuid"
+)
+
+type mismatchResponse struct {
+	ID             uuid.UUID             `json:"id"`
+	AccountNumber  string                `json:"accountNumber"`
+	AccountBalance float64               `json:"accountBalance"`
+	LedgerBalance  float64               `json:"ledgerBalance"`
+	Difference     float64               `json:"difference"`
+	Entries        int64                 `json:"entries"`
+	Status         reconciliation.Status `json:"status"`
+	DetectedAt     time.Time             `json:"detectedAt"`
+	ApprovedBy     *uuid.UUID            `json:"approvedBy,omitempty"`
+	ResolvedAt     *time.Time            `json:"resolvedAt,omitempty"`
+}
+
+// signed paisas to rupees
+func toRupees(paisas int64) float64 {
+	return float64(paisas) / 100
+}
+
+func newMismatchResponse(m reconciliation.Mismatch) mismatchResponse {
+	return mismatchResponse{
+		ID:             m.ID,
+		AccountNumber:  m.AccountNumber,
+		AccountBalance: toRupees(m.AccountBalance),
+		LedgerBalance:  toRupees(m.LedgerBalance),
+		Difference:     toRupees(m.Difference()),
+		Entries:        m.Entries,
+		Status:         m.Status,
+		DetectedAt:     m.DetectedAt,
+		ApprovedBy:     m.ApprovedBy,
+		ResolvedAt:     m.ResolvedAt,
+	}
+}
+
+func MismatchesResponse(message string, mismatches ...reconciliation.Mismatch) SuccessResponse {
+	resp := []mismatchResponse{}
+	for _, m := range mismatches {
+		resp = append(resp, newMismatchResponse(m))
+	}
+
+	return successResponse(message, resp)
+}
+
+type reconciliationReportResponse struct {
+	Accounts   int                `json:"accounts"`
+	Resolved   int                `json:"resolved"`
+	Mismatches []mismatchResponse `json:"mismatches"`
+}
+
+func ReconciliationReportResponse(report reconciliation.Report) SuccessResponse {
+	data := reconciliationReportResponse{
+		Accounts:   report.Accounts,
+		Resolved:   report.Resolved,
+		Mismatches: []mismatchResponse{},
+	}
+	for _, m := range report.Mismatches {
+		data.Mismatches = append(data.Mismatches, newMismatchResponse(m))
+	}
+
+	msg := fmt.Sprintf("%d accounts reconciled, %d mismatches found", report.Accounts, len(report.Mismatches))
+	return successResponse(msg, data)
+}
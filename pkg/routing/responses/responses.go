@@ -50,3 +50,25 @@ func RegistrationResponse(userID uuid.UUID, userType models.UserType) interface{
 	}
 	return successResponse("user created", data)
 }
+
+// AgentRegistrationResponse extends the registration response with the agent
+// number that customers quote when withdrawing cash through the agent.
+func AgentRegistrationResponse(agent models.Agent) interface{} {
+	data := map[string]interface{}{
+		"userID":      agent.ID,
+		"userType":    models.UserTypAgent,
+		"agentNumber": agent.AgentNumber,
+	}
+	return successResponse("user created", data)
+}
+
+// MerchantRegistrationResponse extends the registration response with the
+// till number that customers quote when paying the merchant.
+func MerchantRegistrationResponse(merchant models.Merchant) interface{} {
+	data := map[string]interface{}{
+		"userID":     merchant.ID,
+		"userType":   models.UserTypMerchant,
+		"tillNumber": merchant.TillNumber,
+	}
+	return successResponse("user created", data)
+}
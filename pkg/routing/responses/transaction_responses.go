@@ -25,6 +25,8 @@ import (
 	"time"
 
 	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/ports"
+	"github.com/bhojpur/wallet/pkg/receipt"
 	"github.com/bhojpur/wallet/pkg/statement"
 
 	"github.com/gofrs/uuid"
@@ -38,6 +40,8 @@ type transactionStatement struct {
 	DebitedAmount  float64             `json:"debitedAmount"`
 	UserID         uuid.UUID           `json:"userId"`
 	AccountID      uuid.UUID           `json:"accountId"`
+
+	OverdraftBalance float64 `json:"overdraftBalance,omitempty"`
 }
 
 type miniStatementResponse struct {
@@ -58,6 +62,8 @@ func MiniStatementResponse(userID uuid.UUID, statements []statement.Statement) S
 			DebitedAmount:  stmt.DebitAmount,
 			UserID:         stmt.UserID,
 			AccountID:      stmt.AccountID,
+
+			OverdraftBalance: stmt.OverdraftBalance,
 		})
 	}
 
@@ -71,28 +77,147 @@ func MiniStatementResponse(userID uuid.UUID, statements []statement.Statement) S
 	return successResponse(msg, data)
 }
 
+type counterparty struct {
+	UserID   uuid.UUID       `json:"userId"`
+	UserType models.UserType `json:"userType,omitempty"`
+}
+
+type statementEntry struct {
+	transactionStatement
+	// balance of the account after the entry
+	RunningBalance float64 `json:"runningBalance"`
+	// left out for entries with no other party, like moving money into a pocket
+	Counterparty *counterparty `json:"counterparty,omitempty"`
+}
+
+type statementResponse struct {
+	UserID         uuid.UUID `json:"userID"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	OpeningBalance float64   `json:"openingBalance"`
+	ClosingBalance float64   `json:"closingBalance"`
+	NextCursor     string    `json:"nextCursor,omitempty"`
+
+	Statements []statementEntry `json:"transactions"`
+}
+
+func StatementResponse(userID uuid.UUID, filter statement.Filter, page statement.Page) SuccessResponse {
+	stmts := []statementEntry{}
+	for _, stmt := range page.Statements {
+		entry := statementEntry{
+			transactionStatement: transactionStatement{
+				ID:             stmt.ID,
+				Type:           stmt.Operation,
+				CreatedAt:      stmt.CreatedAt,
+				CreditedAmount: stmt.CreditAmount,
+				DebitedAmount:  stmt.DebitAmount,
+				UserID:         stmt.UserID,
+				AccountID:      stmt.AccountID,
+
+				OverdraftBalance: stmt.OverdraftBalance,
+			},
+			RunningBalance: stmt.Balance,
+		}
+		if stmt.CounterpartyID != uuid.Nil || stmt.CounterpartyType != "" {
+			entry.Counterparty = &counterparty{UserID: stmt.CounterpartyID, UserType: stmt.CounterpartyType}
+		}
+		stmts = append(stmts, entry)
+	}
+
+	msg := fmt.Sprintf("statement retrieved with %d transactions", len(stmts))
+	data := statementResponse{
+		UserID:         userID,
+		From:           filter.From,
+		To:             filter.To,
+		OpeningBalance: page.OpeningBalance.ToFloat(),
+		ClosingBalance: page.ClosingBalance.ToFloat(),
+		NextCursor:     page.NextCursor,
+		Statements:     stmts,
+	}
+
+	return successResponse(msg, data)
+}
+
 type transactionResponse struct {
-	Message string `json:"message"`
+	Message string          `json:"message"`
+	Receipt *receipt.Signed `json:"receipt,omitempty"`
+
+	// left out when the receipt was issued, amounts are in paisas as in receipts
+	Transaction  *completedTransaction `json:"transaction,omitempty"`
+	ReceiptError string                `json:"receiptError,omitempty"`
+}
+
+type completedTransaction struct {
+	Operation   models.TxnOperation `json:"operation"`
+	Source      counterparty        `json:"source"`
+	Destination counterparty        `json:"destination"`
+	Amount      models.Paisas       `json:"amount"`
+	Charge      models.Paisas       `json:"charge"`
+	CompletedAt time.Time           `json:"completedAt"`
 }
 
-func TransactionResponse() SuccessResponse {
+func TransactionResponse(completed ports.Completed) SuccessResponse {
+	if completed.Receipt != nil {
+		data := transactionResponse{
+			Message: "Transaction completed. Keep the receipt as proof of the transaction.",
+			Receipt: completed.Receipt,
+		}
+		return successResponse("", data)
+	}
+
 	data := transactionResponse{
-		Message: "Transaction under processing. You will receive a message shortly.",
+		Message: "Transaction completed, but its receipt could not be issued. Do not repeat the transaction.",
+		Transaction: &completedTransaction{
+			Operation:   completed.TxnOperation,
+			Source:      counterparty{UserID: completed.Source.UserID, UserType: completed.Source.UserType},
+			Destination: counterparty{UserID: completed.Destination.UserID, UserType: completed.Destination.UserType},
+			Amount:      completed.Amount.ToPaisas(),
+			Charge:      completed.Charge,
+			CompletedAt: completed.CompletedAt.UTC(),
+		},
+		ReceiptError: completed.ReceiptError.Error(),
 	}
 	return successResponse("", data)
 }
 
 type balanceResponse struct {
-	UserID  uuid.UUID `json:"userID"`
-	Balance float64   `json:"balance"`
+	UserID        uuid.UUID `json:"userID"`
+	AccountNumber string    `json:"accountNumber"`
+	Balance       float64   `json:"balance"`
+
+	// overdraft facility, left out when the customer hasn't opted in
+	OverdraftLimit   float64 `json:"overdraftLimit,omitempty"`
+	OverdraftBalance float64 `json:"overdraftBalance,omitempty"`
 }
 
-func BalanceResponse(userID uuid.UUID, balance float64) SuccessResponse {
+func BalanceResponse(userID uuid.UUID, acc models.Account) SuccessResponse {
+	balance := acc.Balance()
 	msg := fmt.Sprintf("Your current balance is %v", balance)
 
 	data := balanceResponse{
-		UserID:  userID,
-		Balance: balance,
+		UserID:        userID,
+		AccountNumber: acc.AccountNumber,
+		Balance:       balance,
+
+		OverdraftLimit:   acc.OverdraftLimit.ToFloat(),
+		OverdraftBalance: acc.OverdraftBalance(),
 	}
 	return successResponse(msg, data)
 }
+
+type overdraftResponse struct {
+	AccountNumber    string            `json:"accountNumber"`
+	CreditTier       models.CreditTier `json:"creditTier"`
+	OverdraftLimit   float64           `json:"overdraftLimit"`
+	OverdraftBalance float64           `json:"overdraftBalance"`
+}
+
+func OverdraftResponse(message string, acc models.Account) SuccessResponse {
+	data := overdraftResponse{
+		AccountNumber:    acc.AccountNumber,
+		CreditTier:       acc.CreditTier,
+		OverdraftLimit:   acc.OverdraftLimit.ToFloat(),
+		OverdraftBalance: acc.OverdraftBalance(),
+	}
+	return successResponse(message, data)
+}
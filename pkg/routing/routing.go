@@ -21,16 +21,20 @@ package routing
 // THE SOFTWARE.
 
 import (
+	"net/http"
+
 	"github.com/bhojpur/wallet/pkg/config"
 	"github.com/bhojpur/wallet/pkg/registry"
 	"github.com/bhojpur/wallet/pkg/routing/account_handlers"
 	"github.com/bhojpur/wallet/pkg/routing/error_handlers"
+	"github.com/bhojpur/wallet/pkg/routing/health_handlers"
 	"github.com/bhojpur/wallet/pkg/routing/middleware"
 	"github.com/bhojpur/wallet/pkg/routing/transaction_handlers"
 	"github.com/bhojpur/wallet/pkg/routing/user_handlers"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
 )
 
 func Router(domain *registry.Domain, config config.Config) *fiber.App {
@@ -39,6 +43,14 @@ func Router(domain *registry.Domain, config config.Config) *fiber.App {
 		fiber.Config{ErrorHandler: error_handlers.ErrorHandler},
 	)
 
+	srv.Use(middleware.Metrics(domain.Metrics))
+
+	// the probes of Kubernetes and the metrics Prometheus scrapes, outside of
+	// /api so that they aren't logged
+	srv.Get("/healthz", health_handlers.Liveness(domain.Health))
+	srv.Get("/readyz", health_handlers.Readiness(domain.Health))
+	srv.Get("/metrics", adaptor(domain.Metrics.Handler()))
+
 	apiGroup := srv.Group("/api")
 	apiGroup.Use(logger.New())
 
@@ -47,25 +59,59 @@ func Router(domain *registry.Domain, config config.Config) *fiber.App {
 	return srv
 }
 
+// adaptor serves a net/http handler on the fiber server
+func adaptor(handler http.Handler) fiber.Handler {
+	serve := fasthttpadaptor.NewFastHTTPHandler(handler)
+
+	return func(ctx *fiber.Ctx) error {
+		serve(ctx.Context())
+		return nil
+	}
+}
+
 func apiRouteGroup(api fiber.Router, domain *registry.Domain, config config.Config) {
 
+	// logging in doesn't change anything, it works on a read-only server too
 	api.Post("/login/:user_type", user_handlers.Authenticate(domain, config))
-	api.Post("/user/:user_type", user_handlers.Register(domain))
+	api.Post("/user/:user_type", middleware.ReadOnly(config.ReadOnly), user_handlers.Register(domain))
+
+	// receipts can be checked without logging in, also on a read-only server
+	api.Post("/receipts/verify", transaction_handlers.VerifyReceipt(domain.Receipt))
+	api.Get("/receipts/public-key", transaction_handlers.ReceiptPublicKey(domain.Receipt))
 
 	// create group at /api/admin
-	admin := api.Group("/admin", middleware.AuthByBearerToken(config.Secret))
+	admin := api.Group("/admin", middleware.AuthByBearerToken(config.Secret), middleware.AdminOnly(), middleware.ReadOnly(config.ReadOnly))
 	admin.Post("/assign-float", user_handlers.AssignFloat(domain.Admin))
 	admin.Post("/update-charge", user_handlers.UpdateCharge(domain.Tariff))
 	admin.Get("/get-tariff", user_handlers.GetTariff(domain.Tariff))
 	admin.Put("/super-agent-status", user_handlers.UpdateSuperAgentStatus(domain.Agent))
+	admin.Put("/credit-tier", user_handlers.SetCreditTier(domain.Overdraft))
+	admin.Get("/interest-rates", user_handlers.GetInterestRates(domain.Interest))
+	admin.Put("/interest-rate", user_handlers.SetInterestRate(domain.Interest))
+	admin.Get("/interest-accruals", user_handlers.GetInterestAccruals(domain.Interest))
+	admin.Get("/statement/export", user_handlers.ExportStatement(domain.Export))
+	admin.Get("/reconciliation", user_handlers.GetMismatches(domain.Reconciler))
+	admin.Post("/reconciliation", user_handlers.Reconcile(domain.Reconciler))
+	admin.Post("/reconciliation/:mismatch_id/repair", user_handlers.RepairMismatch(domain.Reconciler))
 
 	// create group at /api/account
-	account := api.Group("/account", middleware.AuthByBearerToken(config.Secret))
+	account := api.Group("/account", middleware.AuthByBearerToken(config.Secret), middleware.ReadOnly(config.ReadOnly))
 	account.Get("/balance", account_handlers.BalanceEnquiry(domain.Account))
-	account.Get("/statement", account_handlers.MiniStatement(domain.Statement))
+	account.Get("/statement", account_handlers.Statement(domain.Statement))
+	account.Get("/statement/export", account_handlers.ExportStatement(domain.Export))
+	account.Get("/mini-statement", account_handlers.MiniStatement(domain.Statement))
+	account.Get("/e-statements", account_handlers.ListEStatements(domain.EStatement))
+	account.Get("/e-statements/:month", account_handlers.DownloadEStatement(domain.EStatement))
+	account.Post("/overdraft", account_handlers.OverdraftOptIn(domain.Overdraft))
+	account.Delete("/overdraft", account_handlers.OverdraftOptOut(domain.Overdraft))
+	account.Get("/pockets", account_handlers.ListPockets(domain.Pocket))
+	account.Post("/pockets", account_handlers.CreatePocket(domain.Pocket))
+	account.Post("/pockets/:pocket_id/deposit", account_handlers.PocketDeposit(domain.Pocket))
+	account.Post("/pockets/:pocket_id/withdraw", account_handlers.PocketWithdraw(domain.Pocket))
+	account.Delete("/pockets/:pocket_id", account_handlers.ClosePocket(domain.Pocket))
 
 	// create group at /api/transaction
-	transaction := api.Group("/transaction", middleware.AuthByBearerToken(config.Secret))
+	transaction := api.Group("/transaction", middleware.AuthByBearerToken(config.Secret), middleware.ReadOnly(config.ReadOnly))
 	transaction.Post("/deposit", transaction_handlers.Deposit(domain.Transactor))
 	transaction.Post("/transfer", transaction_handlers.Transfer(domain.Transactor))
 	transaction.Post("/withdraw", transaction_handlers.Withdraw(domain.Transactor))
@@ -0,0 +1,75 @@
+package routing
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bhojpur/wallet/pkg/auth"
+	"github.com/bhojpur/wallet/pkg/config"
+	"github.com/bhojpur/wallet/pkg/metrics"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/registry"
+
+	"github.com/gofrs/uuid"
+)
+
+const secret = "test-secret"
+
+// adminRoutes are the routes only administrators may call
+var adminRoutes = []struct {
+	method string
+	path   string
+}{
+	{http.MethodPut, "/api/admin/credit-tier"},
+	{http.MethodGet, "/api/admin/interest-rates"},
+	{http.MethodPut, "/api/admin/interest-rate"},
+	{http.MethodGet, "/api/admin/interest-accruals"},
+	{http.MethodGet, "/api/admin/statement/export"},
+	{http.MethodGet, "/api/admin/reconciliation"},
+	{http.MethodPost, "/api/admin/reconciliation"},
+	{http.MethodPost, "/api/admin/reconciliation/" + uuid.Nil.String() + "/repair"},
+}
+
+func TestAdminRoutesRefuseOtherUsers(t *testing.T) {
+	srv := Router(&registry.Domain{Metrics: metrics.New()}, config.Config{Secret: secret})
+
+	for _, userType := range []models.UserType{models.UserTypSubscriber, models.UserTypAgent, models.UserTypMerchant} {
+		token, err := auth.GetTokenString(uuid.Must(uuid.NewV4()), userType, secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, route := range adminRoutes {
+			req := httptest.NewRequest(route.method, route.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			res, err := srv.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.StatusCode != http.StatusForbidden {
+				t.Errorf("%s %s as %s = %d, want %d", route.method, route.path, userType, res.StatusCode, http.StatusForbidden)
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package transaction_handlers
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"net/http"
+
+	"github.com/bhojpur/wallet/pkg/receipt"
+	"github.com/bhojpur/wallet/pkg/routing/responses"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// VerifyReceipt checks a signed receipt sent as the body of the request. It doesn't need
+// authentication, anyone holding a receipt, e.g. a merchant in a dispute, can check it.
+func VerifyReceipt(interactor receipt.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		verification, err := interactor.Verify(ctx.Body())
+		if err != nil {
+			return err
+		}
+
+		return ctx.Status(http.StatusOK).JSON(responses.ReceiptVerificationResponse(verification))
+	}
+}
+
+// ReceiptPublicKey returns the key receipts are signed with, so that they can be verified offline.
+func ReceiptPublicKey(interactor receipt.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		return ctx.Status(http.StatusOK).JSON(responses.ReceiptPublicKeyResponse(interactor.PublicKey()))
+	}
+}
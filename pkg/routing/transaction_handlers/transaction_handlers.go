@@ -58,12 +58,12 @@ func Deposit(txnAdapter ports.TransactorPort) fiber.Handler {
 			UserType: userDetails.UserType,
 			UserID:   userDetails.UserID,
 		}
-		err = txnAdapter.Deposit(depositor, p.CustomerNumber, p.CustomerType, p.Amount)
+		completed, err := txnAdapter.Deposit(depositor, p.CustomerNumber, p.CustomerType, p.Amount)
 		if err != nil {
 			return err
 		}
 
-		return ctx.Status(http.StatusOK).JSON(responses.TransactionResponse())
+		return ctx.Status(http.StatusOK).JSON(responses.TransactionResponse(completed))
 	}
 }
 
@@ -92,12 +92,12 @@ func Withdraw(txnAdapter ports.TransactorPort) fiber.Handler {
 			UserID:   userDetails.UserID,
 			UserType: userDetails.UserType,
 		}
-		err = txnAdapter.Withdraw(withdrawer, p.AgentNumber, p.Amount)
+		completed, err := txnAdapter.Withdraw(withdrawer, p.AgentNumber, p.Amount)
 		if err != nil {
 			return err
 		}
 
-		return ctx.Status(http.StatusOK).JSON(responses.TransactionResponse())
+		return ctx.Status(http.StatusOK).JSON(responses.TransactionResponse(completed))
 	}
 }
 
@@ -125,11 +125,11 @@ func Transfer(txnAdapter ports.TransactorPort) fiber.Handler {
 			UserID:   userDetails.UserID,
 			UserType: userDetails.UserType,
 		}
-		err = txnAdapter.Transfer(source, p.DestAccountNo, p.DestUserType, p.Amount)
+		completed, err := txnAdapter.Transfer(source, p.DestAccountNo, p.DestUserType, p.Amount)
 		if err != nil {
 			return err
 		}
 
-		return ctx.Status(http.StatusOK).JSON(responses.TransactionResponse())
+		return ctx.Status(http.StatusOK).JSON(responses.TransactionResponse(completed))
 	}
 }
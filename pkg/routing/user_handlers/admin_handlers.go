@@ -27,11 +27,18 @@ import (
 	"github.com/bhojpur/wallet/pkg/agent"
 	"github.com/bhojpur/wallet/pkg/auth"
 	"github.com/bhojpur/wallet/pkg/config"
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/export"
+	"github.com/bhojpur/wallet/pkg/interest"
 	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/overdraft"
+	"github.com/bhojpur/wallet/pkg/reconciliation"
+	"github.com/bhojpur/wallet/pkg/routing/account_handlers"
 	"github.com/bhojpur/wallet/pkg/routing/responses"
 	"github.com/bhojpur/wallet/pkg/tariff"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofrs/uuid"
 )
 
 func AuthenticateAdmin(adminDomain admin.Interactor, config config.Config) fiber.Handler {
@@ -191,3 +198,170 @@ func UpdateSuperAgentStatus(agentDomain agent.Interactor) fiber.Handler {
 		return nil
 	}
 }
+
+func SetCreditTier(overdraftDomain overdraft.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+
+		var params overdraft.CreditTierParams
+		_ = ctx.BodyParser(&params)
+
+		err := params.Validate()
+		if err != nil {
+			return err
+		}
+
+		acc, err := overdraftDomain.SetCreditTier(params)
+		if err != nil {
+			return err
+		}
+
+		_ = ctx.Status(http.StatusOK).JSON(responses.OverdraftResponse("credit tier updated", acc))
+
+		return nil
+	}
+}
+
+func GetInterestRates(interestDomain interest.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+
+		rates, err := interestDomain.GetRates()
+		if err != nil {
+			return err
+		}
+
+		_ = ctx.Status(http.StatusOK).JSON(responses.InterestRatesResponse(rates))
+
+		return nil
+	}
+}
+
+func SetInterestRate(interestDomain interest.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+
+		var params interest.RateParams
+		_ = ctx.BodyParser(&params)
+
+		err := params.Validate()
+		if err != nil {
+			return err
+		}
+
+		rate, err := interestDomain.SetRate(params)
+		if err != nil {
+			return err
+		}
+
+		_ = ctx.Status(http.StatusOK).JSON(responses.InterestRatesResponse([]interest.Rate{rate}))
+
+		return nil
+	}
+}
+
+func GetInterestAccruals(interestDomain interest.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+
+		var params interest.AccrualsParams
+		_ = ctx.QueryParser(&params)
+
+		err := params.Validate()
+		if err != nil {
+			return err
+		}
+
+		from, to := params.Days()
+		accruals, err := interestDomain.GetAccruals(params.AccountNumber, from, to)
+		if err != nil {
+			return err
+		}
+
+		_ = ctx.Status(http.StatusOK).JSON(responses.InterestAccrualsResponse(accruals))
+
+		return nil
+	}
+}
+
+// ExportStatement downloads the statement of any account, e.g. the settlement account
+// banks reconcile against
+func ExportStatement(exportDomain export.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+
+		var params export.Params
+		_ = ctx.QueryParser(&params)
+
+		err := params.Validate()
+		if err != nil {
+			return err
+		}
+
+		exp, err := exportDomain.ExportAccount(params.AccountNumber, params)
+		if err != nil {
+			return err
+		}
+
+		return account_handlers.StreamExport(ctx, exp)
+	}
+}
+
+// GetMismatches lists the accounts whose balance doesn't match their statement entries
+func GetMismatches(reconciliationDomain reconciliation.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+
+		mismatches, err := reconciliationDomain.GetOpenMismatches()
+		if err != nil {
+			return err
+		}
+
+		_ = ctx.Status(http.StatusOK).JSON(responses.MismatchesResponse("open mismatches retrieved", mismatches...))
+
+		return nil
+	}
+}
+
+// Reconcile checks every balance against the ledger now rather than waiting for the daily job
+func Reconcile(reconciliationDomain reconciliation.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+
+		report, err := reconciliationDomain.Reconcile()
+		if err != nil {
+			return err
+		}
+
+		_ = ctx.Status(http.StatusOK).JSON(responses.ReconciliationReportResponse(report))
+
+		return nil
+	}
+}
+
+// RepairMismatch approves the repair of a mismatch, a correcting entry is posted to the ledger
+func RepairMismatch(reconciliationDomain reconciliation.Interactor) fiber.Handler {
+
+	return func(ctx *fiber.Ctx) error {
+		var userDetails auth.UserAuthDetails
+		if details, ok := ctx.Locals("userDetails").(auth.UserAuthDetails); !ok {
+			return errors.Error{Code: errors.EINTERNAL}
+		} else {
+			userDetails = details
+		}
+
+		id, err := uuid.FromString(ctx.Params("mismatch_id"))
+		if err != nil {
+			return errors.Error{Code: errors.ENOTFOUND, Message: errors.ErrMismatchNotFound}
+		}
+
+		mismatch, err := reconciliationDomain.Repair(id, userDetails.UserID)
+		if err != nil {
+			return err
+		}
+
+		_ = ctx.Status(http.StatusOK).JSON(responses.MismatchesResponse("mismatch repaired", mismatch))
+
+		return nil
+	}
+}
@@ -93,7 +93,7 @@ func RegisterAgent(agentDomain agent.Interactor) fiber.Handler {
 		}
 
 		// we use a presenter to reformat the response of agent.
-		_ = ctx.Status(http.StatusOK).JSON(responses.RegistrationResponse(agt.ID, models.UserTypAgent))
+		_ = ctx.Status(http.StatusOK).JSON(responses.AgentRegistrationResponse(agt))
 
 		return nil
 	}
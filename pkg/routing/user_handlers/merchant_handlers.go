@@ -87,7 +87,7 @@ func RegisterMerchant(merchDomain merchant.Interactor) fiber.Handler {
 		}
 
 		// we use a presenter to reformat the response of merchant.
-		_ = ctx.Status(http.StatusOK).JSON(responses.RegistrationResponse(merch.ID, models.UserTypMerchant))
+		_ = ctx.Status(http.StatusOK).JSON(responses.MerchantRegistrationResponse(merch))
 
 		return nil
 	}
@@ -0,0 +1,94 @@
+package service
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"strings"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+	"github.com/bhojpur/wallet/pkg/auth"
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// adminServicePrefix starts the methods of the WalletService, they start, stop
+// and follow the engines of the domain jobs and only administrators call them
+var adminServicePrefix = "/" + v1.WalletService_ServiceDesc.ServiceName + "/"
+
+// authenticate reads the bearer token from the authorization metadata, as the
+// REST middleware reads it from the Authorization header
+func authenticate(ctx context.Context, secret string) (auth.UserAuthDetails, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return auth.UserAuthDetails{}, domainStatus(errors.Unauthorized{Message: "authorization metadata not set"})
+	}
+
+	userDetails, err := auth.Authenticate(values[0], secret)
+	if err != nil {
+		return auth.UserAuthDetails{}, domainStatus(err)
+	}
+	return userDetails, nil
+}
+
+// authenticateAdmin only lets administrators through
+func authenticateAdmin(ctx context.Context, secret string) error {
+	userDetails, err := authenticate(ctx, secret)
+	if err != nil {
+		return err
+	}
+
+	if userDetails.UserType != models.UserTypAdmin {
+		return status.Error(codes.PermissionDenied, "only administrators can make this call")
+	}
+	return nil
+}
+
+// AdminUnaryInterceptor only lets administrators call the WalletService, the
+// other services check the callers of their methods themselves
+func AdminUnaryInterceptor(secret string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, adminServicePrefix) {
+			if err := authenticateAdmin(ctx, secret); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AdminStreamInterceptor is AdminUnaryInterceptor for streaming calls
+func AdminStreamInterceptor(secret string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, adminServicePrefix) {
+			if err := authenticateAdmin(stream.Context(), secret); err != nil {
+				return err
+			}
+		}
+		return handler(srv, stream)
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bhojpur/wallet/pkg/auth"
+	"github.com/bhojpur/wallet/pkg/models"
+
+	"github.com/gofrs/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const secret = "test-secret"
+
+func tokenContext(t *testing.T, userType models.UserType) context.Context {
+	token, err := auth.GetTokenString(uuid.Must(uuid.NewV4()), userType, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestAdminUnaryInterceptor(t *testing.T) {
+	interceptor := AdminUnaryInterceptor(secret)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "called", nil
+	}
+
+	for _, c := range []struct {
+		name   string
+		ctx    context.Context
+		method string
+		want   codes.Code
+	}{
+		{"no token", context.Background(), "/v1.WalletService/StartEngine", codes.Unauthenticated},
+		{"subscriber", tokenContext(t, models.UserTypSubscriber), "/v1.WalletService/StartEngine", codes.PermissionDenied},
+		{"administrator", tokenContext(t, models.UserTypAdmin), "/v1.WalletService/StartEngine", codes.OK},
+		// the other services check their callers themselves
+		{"login", context.Background(), "/v1.WalletOperations/Login", codes.OK},
+		{"web ui", context.Background(), "/v1.WalletUI/IsReadOnly", codes.OK},
+	} {
+		_, err := interceptor(c.ctx, nil, &grpc.UnaryServerInfo{FullMethod: c.method}, handler)
+		if got := status.Code(err); got != c.want {
+			t.Errorf("%s: %s = %v, want %v", c.name, c.method, got, c.want)
+		}
+	}
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestAdminStreamInterceptor(t *testing.T) {
+	interceptor := AdminStreamInterceptor(secret)
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		return nil
+	}
+	info := &grpc.StreamServerInfo{FullMethod: "/v1.WalletService/Listen"}
+
+	err := interceptor(nil, fakeStream{ctx: tokenContext(t, models.UserTypAgent)}, info, handler)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Listen as an agent = %v, want PermissionDenied", err)
+	}
+	if err := interceptor(nil, fakeStream{ctx: tokenContext(t, models.UserTypAdmin)}, info, handler); err != nil {
+		t.Errorf("Listen as an administrator = %v, want it let through", err)
+	}
+}
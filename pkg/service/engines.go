@@ -0,0 +1,405 @@
+package service
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// finished engines kept for listing, the oldest are forgotten first
+	maxFinishedEngines = 500
+	// updates queued for a subscriber before it is dropped as too slow
+	subscriberBuffer = 64
+)
+
+var (
+	errEngineNotFound    = errors.New("engine not found")
+	errEngineExists      = errors.New("an engine with this name already exists")
+	errEngineRunning     = errors.New("engine is running and can't be stopped")
+	errEngineNotReplay   = errors.New("engine can't be replayed")
+	errSubscriberTooSlow = errors.New("subscriber fell behind the engine updates")
+	errShuttingDown      = errors.New("the server is shutting down")
+)
+
+// engine is a started instance of a Spec
+type engine struct {
+	spec   Spec
+	status *v1.EngineStatus
+	cancel context.CancelFunc
+	log    *Logger
+}
+
+// engineSubscriber receives the status of every engine that changes
+type engineSubscriber struct {
+	updates chan *v1.EngineStatus
+	// closed when the subscriber is dropped for falling behind
+	dropped chan struct{}
+}
+
+// engines tracks the engines started on this server and their phase
+type engines struct {
+	mu          sync.RWMutex
+	specs       map[string]Spec
+	engines     map[string]*engine
+	counter     map[string]int
+	subscribers map[*engineSubscriber]struct{}
+
+	// the engines that have not finished, close waits for them
+	wg      sync.WaitGroup
+	closing bool
+}
+
+func newEngines(specs []Spec) *engines {
+	es := &engines{
+		specs:       make(map[string]Spec, len(specs)),
+		engines:     make(map[string]*engine),
+		counter:     make(map[string]int),
+		subscribers: make(map[*engineSubscriber]struct{}),
+	}
+	for _, spec := range specs {
+		es.specs[spec.Name] = spec
+	}
+	return es
+}
+
+// start starts an engine of the spec, it waits until waitUntil before running
+func (es *engines) start(spec Spec, metadata *v1.EngineMetadata, waitUntil time.Time, nameSuffix string) (*v1.EngineStatus, error) {
+	es.mu.Lock()
+
+	if es.closing {
+		es.mu.Unlock()
+		return nil, errShuttingDown
+	}
+
+	name := nameSuffix
+	if name == "" {
+		es.counter[spec.Name]++
+		name = fmt.Sprint(es.counter[spec.Name])
+	}
+	name = fmt.Sprintf("%s.%s", spec.Name, name)
+	if _, exists := es.engines[name]; exists {
+		es.mu.Unlock()
+		return nil, errEngineExists
+	}
+
+	metadata = proto.Clone(metadata).(*v1.EngineMetadata)
+	metadata.EngineSpecName = spec.Name
+	metadata.Created = timestamppb.Now()
+	metadata.Finished = nil
+
+	ctx, cancel := context.WithCancel(context.Background())
+	eng := &engine{
+		spec: spec,
+		status: &v1.EngineStatus{
+			Name:       name,
+			Metadata:   metadata,
+			Phase:      v1.EnginePhase_PHASE_PREPARING,
+			Conditions: &v1.EngineConditions{},
+		},
+		cancel: cancel,
+		log:    &Logger{buf: newLogBuffer(logBufferSize)},
+	}
+	if !waitUntil.IsZero() {
+		eng.status.Conditions.WaitUntil = timestamppb.New(waitUntil)
+	}
+	eng.log.Info("engine created", argumentFields(metadata))
+	es.engines[name] = eng
+	es.publish(eng.status)
+	status := proto.Clone(eng.status).(*v1.EngineStatus)
+	es.wg.Add(1)
+
+	es.mu.Unlock()
+
+	go func() {
+		defer es.wg.Done()
+		es.run(ctx, eng, waitUntil)
+	}()
+
+	return status, nil
+}
+
+func (es *engines) run(ctx context.Context, eng *engine, waitUntil time.Time) {
+	if wait := time.Until(waitUntil); wait > 0 {
+		es.update(eng, func(status *v1.EngineStatus) {
+			status.Phase = v1.EnginePhase_PHASE_WAITING
+		})
+		eng.log.Info("waiting to run", Fields{"until": waitUntil.Format(time.RFC3339)})
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			es.finish(eng, nil, errors.New("stopped before it ran"), false)
+			return
+		}
+	}
+
+	// stopping is only possible until the engine runs
+	es.mu.Lock()
+	if ctx.Err() != nil {
+		es.mu.Unlock()
+		es.finish(eng, nil, errors.New("stopped before it ran"), false)
+		return
+	}
+	eng.status.Phase = v1.EnginePhase_PHASE_RUNNING
+	es.publish(eng.status)
+	args := annotations(eng.status.Metadata)
+	es.mu.Unlock()
+
+	eng.log.Info("running "+eng.spec.Name, nil)
+	started := time.Now()
+	results, err := eng.spec.Run(withLogger(ctx, eng.log), args)
+	if err != nil {
+		log.Printf("engine %s failed: %v", eng.status.Name, err)
+	}
+	eng.log.Debug("run finished", Fields{"took": time.Since(started).Round(time.Millisecond).String()})
+	es.finish(eng, results, err, true)
+}
+
+func (es *engines) finish(eng *engine, results []*v1.EngineResult, err error, executed bool) {
+	for _, result := range results {
+		eng.log.Info(result.Description, Fields{"type": result.Type, "payload": result.Payload})
+	}
+	if err != nil {
+		eng.log.Error("engine failed", Fields{"error": err.Error()})
+	} else {
+		eng.log.Info("engine done", nil)
+	}
+
+	es.update(eng, func(status *v1.EngineStatus) {
+		status.Phase = v1.EnginePhase_PHASE_DONE
+		status.Metadata.Finished = timestamppb.Now()
+		status.Results = results
+		status.Conditions.DidExecute = executed
+		// the jobs are safe to run again for the same arguments
+		status.Conditions.CanReplay = true
+		status.Conditions.Success = err == nil
+		if err != nil {
+			status.Conditions.FailureCount++
+			status.Details = err.Error()
+		}
+	})
+	eng.cancel()
+
+	es.forgetFinished()
+}
+
+// update changes the status of an engine and tells the subscribers
+func (es *engines) update(eng *engine, change func(*v1.EngineStatus)) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	change(eng.status)
+	es.publish(eng.status)
+}
+
+// publish sends a copy of the status to every subscriber, it is called with the lock held
+func (es *engines) publish(status *v1.EngineStatus) {
+	for sub := range es.subscribers {
+		select {
+		case sub.updates <- proto.Clone(status).(*v1.EngineStatus):
+		default:
+			delete(es.subscribers, sub)
+			close(sub.dropped)
+		}
+	}
+}
+
+// forgetFinished drops the oldest finished engines beyond maxFinishedEngines
+func (es *engines) forgetFinished() {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	var finished []*engine
+	for _, eng := range es.engines {
+		if eng.status.Phase == v1.EnginePhase_PHASE_DONE {
+			finished = append(finished, eng)
+		}
+	}
+	if len(finished) <= maxFinishedEngines {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].status.Metadata.Finished.AsTime().Before(finished[j].status.Metadata.Finished.AsTime())
+	})
+	for _, eng := range finished[:len(finished)-maxFinishedEngines] {
+		delete(es.engines, eng.status.Name)
+	}
+}
+
+// stop stops an engine that has not started running yet
+func (es *engines) stop(name string) error {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	eng, ok := es.engines[name]
+	if !ok {
+		return errEngineNotFound
+	}
+
+	switch eng.status.Phase {
+	case v1.EnginePhase_PHASE_RUNNING:
+		return errEngineRunning
+	case v1.EnginePhase_PHASE_DONE:
+		return nil
+	}
+
+	eng.cancel()
+	return nil
+}
+
+// close refuses new engines and stops those that have not started running, they
+// are not run by this server. It waits for the running engines to finish until
+// ctx ends, then stops them.
+func (es *engines) close(ctx context.Context) error {
+	es.mu.Lock()
+	es.closing = true
+	var running []*engine
+	for _, eng := range es.engines {
+		switch eng.status.Phase {
+		case v1.EnginePhase_PHASE_DONE:
+		case v1.EnginePhase_PHASE_RUNNING:
+			running = append(running, eng)
+		default:
+			log.Printf("engine %s has not run and is stopped by the shutdown", eng.status.Name)
+			eng.cancel()
+		}
+	}
+	es.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		es.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		for _, eng := range running {
+			eng.cancel()
+		}
+		return fmt.Errorf("engines did not finish in time: %w", ctx.Err())
+	}
+}
+
+// get returns a copy of the status of an engine
+func (es *engines) get(name string) (*v1.EngineStatus, error) {
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+
+	eng, ok := es.engines[name]
+	if !ok {
+		return nil, errEngineNotFound
+	}
+
+	return proto.Clone(eng.status).(*v1.EngineStatus), nil
+}
+
+// logs returns a copy of the status of an engine and its log
+func (es *engines) logs(name string) (*v1.EngineStatus, *logBuffer, error) {
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+
+	eng, ok := es.engines[name]
+	if !ok {
+		return nil, nil, errEngineNotFound
+	}
+
+	return proto.Clone(eng.status).(*v1.EngineStatus), eng.log.buf, nil
+}
+
+// list returns a copy of the status of every engine, the most recently created first
+func (es *engines) list() []*v1.EngineStatus {
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+
+	result := make([]*v1.EngineStatus, 0, len(es.engines))
+	for _, eng := range es.engines {
+		result = append(result, proto.Clone(eng.status).(*v1.EngineStatus))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		ci, cj := result[i].Metadata.Created.AsTime(), result[j].Metadata.Created.AsTime()
+		if ci.Equal(cj) {
+			return result[i].Name > result[j].Name
+		}
+		return ci.After(cj)
+	})
+
+	return result
+}
+
+// subscribe registers a subscriber to the updates of all engines, unsubscribe should be called when done
+func (es *engines) subscribe() (sub *engineSubscriber, unsubscribe func()) {
+	sub = &engineSubscriber{
+		updates: make(chan *v1.EngineStatus, subscriberBuffer),
+		dropped: make(chan struct{}),
+	}
+
+	es.mu.Lock()
+	es.subscribers[sub] = struct{}{}
+	es.mu.Unlock()
+
+	return sub, func() {
+		es.mu.Lock()
+		delete(es.subscribers, sub)
+		es.mu.Unlock()
+	}
+}
+
+// argumentFields returns the annotations of the metadata as the fields of a log entry
+func argumentFields(metadata *v1.EngineMetadata) Fields {
+	args := annotations(metadata)
+	if len(args) == 0 {
+		return nil
+	}
+
+	fields := make(Fields, len(args))
+	for key, value := range args {
+		fields[key] = value
+	}
+	return fields
+}
+
+// annotations returns the annotations of the metadata as the arguments of the engine
+func annotations(metadata *v1.EngineMetadata) map[string]string {
+	args := make(map[string]string, len(metadata.GetAnnotations()))
+	for _, annotation := range metadata.GetAnnotations() {
+		args[annotation.Key] = annotation.Value
+	}
+	return args
+}
@@ -0,0 +1,114 @@
+package service
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+)
+
+func TestEnginesClose(t *testing.T) {
+	release := make(chan struct{})
+	running := make(chan struct{})
+	finished := false
+	spec := Spec{
+		Name: "test",
+		Run: func(ctx context.Context, args map[string]string) ([]*v1.EngineResult, error) {
+			close(running)
+			<-release
+			finished = true
+			return nil, nil
+		},
+	}
+	es := newEngines([]Spec{spec})
+
+	if _, err := es.start(spec, &v1.EngineMetadata{}, time.Time{}, "running"); err != nil {
+		t.Fatal(err)
+	}
+	<-running
+	waiting, err := es.start(spec, &v1.EngineMetadata{}, time.Now().Add(time.Hour), "waiting")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- es.close(context.Background())
+	}()
+
+	select {
+	case <-closed:
+		t.Fatal("close returned while an engine was running")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	if _, err := es.start(spec, &v1.EngineMetadata{}, time.Time{}, ""); err != errShuttingDown {
+		t.Errorf("start() error = %v, want %v", err, errShuttingDown)
+	}
+
+	close(release)
+	if err := <-closed; err != nil {
+		t.Fatal(err)
+	}
+	if !finished {
+		t.Error("close returned before the running engine finished")
+	}
+
+	status, err := es.get(waiting.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Phase != v1.EnginePhase_PHASE_DONE || status.Conditions.DidExecute {
+		t.Errorf("waiting engine is %v, executed %v, want it stopped without running", status.Phase, status.Conditions.DidExecute)
+	}
+}
+
+func TestEnginesCloseDeadline(t *testing.T) {
+	spec := Spec{
+		Name: "test",
+		Run: func(ctx context.Context, args map[string]string) ([]*v1.EngineResult, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	es := newEngines([]Spec{spec})
+
+	if _, err := es.start(spec, &v1.EngineMetadata{}, time.Time{}, ""); err != nil {
+		t.Fatal(err)
+	}
+	// the engine runs once it leaves the preparing phase
+	for {
+		status, _ := es.get("test.1")
+		if status.Phase == v1.EnginePhase_PHASE_RUNNING {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := es.close(ctx); err == nil {
+		t.Error("no error, want close to give up on the running engine")
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+	"github.com/bhojpur/wallet/pkg/health"
+
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthCheckInterval is how often the health service checks the server
+const healthCheckInterval = 5 * time.Second
+
+// healthServices are the services the health service reports on, the empty
+// name stands for the whole server
+var healthServices = []string{
+	"",
+	v1.WalletOperations_ServiceDesc.ServiceName,
+	v1.WalletService_ServiceDesc.ServiceName,
+	v1.WalletUI_ServiceDesc.ServiceName,
+}
+
+// HealthService serves the standard gRPC health service. All the services of
+// the server are serving while it is ready.
+type HealthService struct {
+	*grpchealth.Server
+
+	checker   *health.Checker
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewHealthService returns a health service that keeps checking the readiness
+// of the server with the given checker, until it is closed
+func NewHealthService(checker *health.Checker) *HealthService {
+	s := &HealthService{
+		Server:  grpchealth.NewServer(),
+		checker: checker,
+		closing: make(chan struct{}),
+	}
+	s.update()
+	go s.run()
+
+	return s
+}
+
+// Close makes all the services not serving for the rest of the shutdown
+func (s *HealthService) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closing)
+		s.Shutdown()
+	})
+}
+
+func (s *HealthService) run() {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.update()
+		case <-s.closing:
+			return
+		}
+	}
+}
+
+// update sets the status of the services from the readiness of the server,
+// Watch streams get the changes
+func (s *HealthService) update() {
+	status := healthpb.HealthCheckResponse_SERVING
+	if !s.checker.Ready(context.Background()).Up() {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	for _, service := range healthServices {
+		s.SetServingStatus(service, status)
+	}
+}
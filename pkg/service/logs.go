@@ -0,0 +1,202 @@
+package service
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logBufferSize is how many entries of an engine are kept, the oldest are dropped first
+const logBufferSize = 1000
+
+// Level is the severity of a log entry
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// MarshalJSON writes the level by its name
+func (l Level) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.String())
+}
+
+// ParseLevel parses the name of a level, an empty name is the debug level
+func ParseLevel(name string) (Level, error) {
+	if name == "" {
+		return LevelDebug, nil
+	}
+	for level, n := range levelNames {
+		if strings.EqualFold(n, name) {
+			return level, nil
+		}
+	}
+	return LevelDebug, fmt.Errorf("unknown log level %q, valid levels are debug, info, warn or error", name)
+}
+
+// Fields are the structured data of a log entry
+type Fields map[string]interface{}
+
+// LogEntry is a line an engine logged
+type LogEntry struct {
+	Time    time.Time `json:"time"`
+	Level   Level     `json:"level"`
+	Message string    `json:"message"`
+	Fields  Fields    `json:"fields,omitempty"`
+
+	// position of the entry in the log of the engine, it keeps counting when entries are dropped
+	seq uint64
+}
+
+// Text formats the entry as a line of text, the fields sorted by name
+func (e LogEntry) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %-5s %s", e.Time.Format(time.RFC3339), strings.ToUpper(e.Level.String()), e.Message)
+
+	keys := make([]string, 0, len(e.Fields))
+	for key := range e.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%v", key, e.Fields[key])
+	}
+
+	return b.String()
+}
+
+// HTML formats the entry as a line of escaped text in a span classed by its level
+func (e LogEntry) HTML() string {
+	return fmt.Sprintf(`<span class="log-%s">%s</span>`, e.Level, html.EscapeString(e.Text()))
+}
+
+// logBuffer is a ring buffer of the log entries of an engine
+type logBuffer struct {
+	mu      sync.Mutex
+	entries []LogEntry
+	// sequence number the next entry gets
+	next uint64
+	// closed and replaced whenever an entry is added, so that followers can wait for more
+	added chan struct{}
+}
+
+func newLogBuffer(size int) *logBuffer {
+	return &logBuffer{
+		entries: make([]LogEntry, 0, size),
+		added:   make(chan struct{}),
+	}
+}
+
+func (lb *logBuffer) add(entry LogEntry) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	entry.seq = lb.next
+	lb.next++
+	if len(lb.entries) < cap(lb.entries) {
+		lb.entries = append(lb.entries, entry)
+	} else {
+		lb.entries[entry.seq%uint64(cap(lb.entries))] = entry
+	}
+
+	close(lb.added)
+	lb.added = make(chan struct{})
+}
+
+// since returns the entries from the sequence number on, how many of those were dropped
+// already, the sequence number to read from next and a channel closed when more are added
+func (lb *logBuffer) since(seq uint64) (entries []LogEntry, dropped uint64, next uint64, added <-chan struct{}) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	oldest := lb.next - uint64(len(lb.entries))
+	if seq < oldest {
+		dropped = oldest - seq
+		seq = oldest
+	}
+
+	for s := seq; s < lb.next; s++ {
+		entries = append(entries, lb.entries[s%uint64(cap(lb.entries))])
+	}
+
+	return entries, dropped, lb.next, lb.added
+}
+
+// Logger writes structured entries to the log of an engine
+type Logger struct {
+	buf *logBuffer
+}
+
+type loggerKey struct{}
+
+// LoggerFrom returns the logger of the engine a context belongs to. Outside of an
+// engine the logger discards what it is given.
+func LoggerFrom(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*Logger); ok {
+		return logger
+	}
+	return &Logger{}
+}
+
+func withLogger(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Log writes an entry of the level
+func (l *Logger) Log(level Level, msg string, fields Fields) {
+	if l.buf == nil {
+		return
+	}
+	l.buf.add(LogEntry{Time: time.Now(), Level: level, Message: msg, Fields: fields})
+}
+
+// Debug writes an entry of the debug level
+func (l *Logger) Debug(msg string, fields Fields) { l.Log(LevelDebug, msg, fields) }
+
+// Info writes an entry of the info level
+func (l *Logger) Info(msg string, fields Fields) { l.Log(LevelInfo, msg, fields) }
+
+// Warn writes an entry of the warn level
+func (l *Logger) Warn(msg string, fields Fields) { l.Log(LevelWarn, msg, fields) }
+
+// Error writes an entry of the error level
+func (l *Logger) Error(msg string, fields Fields) { l.Log(LevelError, msg, fields) }
@@ -0,0 +1,467 @@
+package service
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/bhojpur/wallet/pkg/admin"
+	"github.com/bhojpur/wallet/pkg/agent"
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+	"github.com/bhojpur/wallet/pkg/auth"
+	"github.com/bhojpur/wallet/pkg/config"
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/merchant"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/ports"
+	"github.com/bhojpur/wallet/pkg/registry"
+	"github.com/bhojpur/wallet/pkg/statement"
+	"github.com/bhojpur/wallet/pkg/subscriber"
+	"github.com/bhojpur/wallet/pkg/transaction"
+
+	"github.com/gofrs/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// WalletOperations is the gRPC service of the wallet operations, it shares the domain with the REST API
+type WalletOperations struct {
+	v1.UnimplementedWalletOperationsServer
+
+	domain *registry.Domain
+	config config.Config
+}
+
+// NewWalletOperations returns the gRPC service of the wallet operations of the domain
+func NewWalletOperations(domain *registry.Domain, config config.Config) *WalletOperations {
+	return &WalletOperations{domain: domain, config: config}
+}
+
+// Login authenticates a user by email and returns a bearer token
+func (s *WalletOperations) Login(ctx context.Context, req *v1.LoginRequest) (*v1.LoginResponse, error) {
+	var (
+		userID   uuid.UUID
+		userType = models.UserType(req.UserType)
+	)
+
+	switch userType {
+	case models.UserTypAdmin:
+		params := admin.LoginParams{Email: req.Email, Password: req.Password}
+		if err := params.Validate(); err != nil {
+			return nil, domainStatus(err)
+		}
+		adm, err := s.domain.Admin.AuthenticateByEmail(params.Email, params.Password)
+		if err != nil {
+			return nil, domainStatus(err)
+		}
+		userID = adm.ID
+	case models.UserTypAgent:
+		params := agent.LoginParams{Email: req.Email, Password: req.Password}
+		if err := params.Validate(); err != nil {
+			return nil, domainStatus(err)
+		}
+		agt, err := s.domain.Agent.AuthenticateByEmail(params.Email, params.Password)
+		if err != nil {
+			return nil, domainStatus(err)
+		}
+		userID = agt.ID
+		if agt.IsSuperAgent() {
+			userType = models.UserTypSuperAgent
+		}
+	case models.UserTypMerchant:
+		params := merchant.LoginParams{Email: req.Email, Password: req.Password}
+		if err := params.Validate(); err != nil {
+			return nil, domainStatus(err)
+		}
+		mct, err := s.domain.Merchant.AuthenticateByEmail(params.Email, params.Password)
+		if err != nil {
+			return nil, domainStatus(err)
+		}
+		userID = mct.ID
+	case models.UserTypSubscriber:
+		params := subscriber.LoginParams{Email: req.Email, Password: req.Password}
+		if err := params.Validate(); err != nil {
+			return nil, domainStatus(err)
+		}
+		sub, err := s.domain.Subscriber.AuthenticateByEmail(params.Email, params.Password)
+		if err != nil {
+			return nil, domainStatus(err)
+		}
+		userID = sub.ID
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "user type should be one of %s, %s, %s or %s",
+			models.UserTypAdmin, models.UserTypAgent, models.UserTypMerchant, models.UserTypSubscriber)
+	}
+
+	token, err := auth.GetTokenString(userID, userType, s.config.Secret)
+	if err != nil {
+		return nil, domainStatus(err)
+	}
+
+	return &v1.LoginResponse{UserId: userID.String(), UserType: string(userType), Token: token}, nil
+}
+
+// Balance returns the balance of the account of the user
+func (s *WalletOperations) Balance(ctx context.Context, req *v1.BalanceRequest) (*v1.BalanceResponse, error) {
+	userDetails, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// administrators don't have an account
+	if userDetails.UserType == models.UserTypAdmin {
+		return nil, domainStatus(errors.Error{Code: errors.EINVALID, Message: errors.UserCantHaveAccount})
+	}
+
+	acc, err := s.domain.Account.GetAccount(userDetails.UserID)
+	if err != nil {
+		return nil, domainStatus(err)
+	}
+
+	return &v1.BalanceResponse{
+		UserId:           userDetails.UserID.String(),
+		AccountNumber:    acc.AccountNumber,
+		Balance:          acc.Balance(),
+		OverdraftLimit:   acc.OverdraftLimit.ToFloat(),
+		OverdraftBalance: acc.OverdraftBalance(),
+	}, nil
+}
+
+// MiniStatement returns the most recent transactions on the account of the user
+func (s *WalletOperations) MiniStatement(ctx context.Context, req *v1.MiniStatementRequest) (*v1.MiniStatementResponse, error) {
+	userDetails, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statements, err := s.domain.Statement.GetStatement(userDetails.UserID)
+	if err != nil {
+		return nil, domainStatus(err)
+	}
+
+	return &v1.MiniStatementResponse{
+		UserId:  userDetails.UserID.String(),
+		Entries: statementEntries(statements),
+	}, nil
+}
+
+// Statement returns a page of the transactions on the account of the user in a period
+func (s *WalletOperations) Statement(ctx context.Context, req *v1.StatementRequest) (*v1.StatementResponse, error) {
+	userDetails, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params := statement.Params{
+		From:       req.From,
+		To:         req.To,
+		Cursor:     req.Cursor,
+		Limit:      uint(req.Limit),
+		Operations: strings.Join(req.Operations, ","),
+	}
+	if err := params.Validate(); err != nil {
+		return nil, domainStatus(err)
+	}
+
+	filter := params.Filter()
+	page, err := s.domain.Statement.GetStatementPage(userDetails.UserID, filter)
+	if err != nil {
+		return nil, domainStatus(err)
+	}
+
+	return &v1.StatementResponse{
+		UserId:         userDetails.UserID.String(),
+		From:           timestamppb.New(filter.From),
+		To:             timestamppb.New(filter.To),
+		OpeningBalance: page.OpeningBalance.ToFloat(),
+		ClosingBalance: page.ClosingBalance.ToFloat(),
+		NextCursor:     page.NextCursor,
+		Entries:        statementEntries(page.Statements),
+	}, nil
+}
+
+// Deposit credits the account of a customer
+func (s *WalletOperations) Deposit(ctx context.Context, req *v1.DepositRequest) (*v1.TransactionResponse, error) {
+	userDetails, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+
+	params := transaction.DepositParams{
+		Amount:         models.Rupees(req.Amount),
+		CustomerNumber: req.CustomerNumber,
+		CustomerType:   models.UserType(req.CustomerType),
+	}
+	if err := params.Validate(); err != nil {
+		return nil, domainStatus(err)
+	}
+
+	depositor := models.TxnCustomer{UserID: userDetails.UserID, UserType: userDetails.UserType}
+	completed, err := s.domain.Transactor.Deposit(depositor, params.CustomerNumber, params.CustomerType, params.Amount)
+	if err != nil {
+		return nil, domainStatus(err)
+	}
+
+	return transactionResponse(completed)
+}
+
+// Transfer moves money from the account of the user to another account
+func (s *WalletOperations) Transfer(ctx context.Context, req *v1.TransferRequest) (*v1.TransactionResponse, error) {
+	userDetails, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+
+	params := transaction.TransferParams{
+		Amount:        models.Rupees(req.Amount),
+		DestAccountNo: req.AccountNumber,
+		DestUserType:  models.UserType(req.CustomerType),
+	}
+	if err := params.Validate(); err != nil {
+		return nil, domainStatus(err)
+	}
+
+	source := models.TxnCustomer{UserID: userDetails.UserID, UserType: userDetails.UserType}
+	completed, err := s.domain.Transactor.Transfer(source, params.DestAccountNo, params.DestUserType, params.Amount)
+	if err != nil {
+		return nil, domainStatus(err)
+	}
+
+	return transactionResponse(completed)
+}
+
+// Withdraw debits the account of the user through an agent
+func (s *WalletOperations) Withdraw(ctx context.Context, req *v1.WithdrawRequest) (*v1.TransactionResponse, error) {
+	userDetails, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+
+	params := transaction.WithdrawParams{
+		Amount:      models.Rupees(req.Amount),
+		AgentNumber: req.AgentNumber,
+	}
+	if err := params.Validate(); err != nil {
+		return nil, domainStatus(err)
+	}
+
+	withdrawer := models.TxnCustomer{UserID: userDetails.UserID, UserType: userDetails.UserType}
+	completed, err := s.domain.Transactor.Withdraw(withdrawer, params.AgentNumber, params.Amount)
+	if err != nil {
+		return nil, domainStatus(err)
+	}
+
+	return transactionResponse(completed)
+}
+
+// AssignFloat credits a super agent with float
+func (s *WalletOperations) AssignFloat(ctx context.Context, req *v1.AssignFloatRequest) (*v1.AssignFloatResponse, error) {
+	if err := s.authenticateAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+
+	params := admin.AssignFloatParams{
+		AgentAccountNumber: req.AccountNumber,
+		Amount:             models.Rupees(req.Amount),
+	}
+	if err := params.Validate(); err != nil {
+		return nil, domainStatus(err)
+	}
+
+	balance, err := s.domain.Admin.AssignFloat(params)
+	if err != nil {
+		return nil, domainStatus(err)
+	}
+
+	return &v1.AssignFloatResponse{Balance: balance}, nil
+}
+
+// GetTariff returns the charges of every transaction
+func (s *WalletOperations) GetTariff(ctx context.Context, req *v1.GetTariffRequest) (*v1.GetTariffResponse, error) {
+	if err := s.authenticateAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	charges, err := s.domain.Tariff.GetTariff()
+	if err != nil {
+		return nil, domainStatus(err)
+	}
+
+	res := &v1.GetTariffResponse{}
+	for _, charge := range charges {
+		res.Charges = append(res.Charges, &v1.Charge{
+			Id:                  charge.ID.String(),
+			Operation:           string(charge.Transaction),
+			SourceUserType:      string(charge.SourceUserType),
+			DestinationUserType: string(charge.DestinationUserType),
+			Fee:                 uint64(charge.Fee),
+		})
+	}
+
+	return res, nil
+}
+
+// UpdateCharge changes the fee of a charge
+func (s *WalletOperations) UpdateCharge(ctx context.Context, req *v1.UpdateChargeRequest) (*v1.UpdateChargeResponse, error) {
+	if err := s.authenticateAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+
+	chargeID, err := uuid.FromString(req.ChargeId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "charge id %q is not valid", req.ChargeId)
+	}
+
+	params := admin.UpdateChargeParams{ChargeID: chargeID, Amount: models.Paisas(req.Fee)}
+	if err := params.Validate(); err != nil {
+		return nil, domainStatus(err)
+	}
+
+	if err := s.domain.Tariff.UpdateCharge(params.ChargeID, params.Amount); err != nil {
+		return nil, domainStatus(err)
+	}
+
+	return &v1.UpdateChargeResponse{}, nil
+}
+
+// UpdateSuperAgentStatus toggles whether an agent is a super agent
+func (s *WalletOperations) UpdateSuperAgentStatus(ctx context.Context, req *v1.UpdateSuperAgentStatusRequest) (*v1.UpdateSuperAgentStatusResponse, error) {
+	if err := s.authenticateAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.writable(); err != nil {
+		return nil, err
+	}
+
+	params := agent.MakeSuperAgentParams{Email: req.Email}
+	if err := params.Validate(); err != nil {
+		return nil, domainStatus(err)
+	}
+
+	if err := s.domain.Agent.UpdateSuperAgentStatus(params.Email); err != nil {
+		return nil, domainStatus(err)
+	}
+
+	return &v1.UpdateSuperAgentStatusResponse{}, nil
+}
+
+// authenticate reads the bearer token from the authorization metadata
+func (s *WalletOperations) authenticate(ctx context.Context) (auth.UserAuthDetails, error) {
+	return authenticate(ctx, s.config.Secret)
+}
+
+// authenticateAdmin only lets administrators through
+func (s *WalletOperations) authenticateAdmin(ctx context.Context) error {
+	return authenticateAdmin(ctx, s.config.Secret)
+}
+
+// writable refuses changes while the server is read-only
+func (s *WalletOperations) writable() error {
+	if s.config.ReadOnly {
+		return domainStatus(errors.ErrReadOnly)
+	}
+	return nil
+}
+
+func statementEntries(statements []statement.Statement) []*v1.StatementEntry {
+	entries := make([]*v1.StatementEntry, 0, len(statements))
+	for _, stmt := range statements {
+		entry := &v1.StatementEntry{
+			Id:               stmt.ID.String(),
+			Operation:        string(stmt.Operation),
+			CreatedAt:        timestamppb.New(stmt.CreatedAt),
+			CreditedAmount:   stmt.CreditAmount,
+			DebitedAmount:    stmt.DebitAmount,
+			AccountId:        stmt.AccountID.String(),
+			RunningBalance:   stmt.Balance,
+			OverdraftBalance: stmt.OverdraftBalance,
+		}
+		if stmt.CounterpartyID != uuid.Nil || stmt.CounterpartyType != "" {
+			entry.Counterparty = &v1.Party{UserId: stmt.CounterpartyID.String(), UserType: string(stmt.CounterpartyType)}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func transactionResponse(completed ports.Completed) (*v1.TransactionResponse, error) {
+	if completed.Receipt == nil {
+		// the money has moved, the client must not repeat the transaction for want of a receipt
+		return &v1.TransactionResponse{
+			Transaction: &v1.CompletedTransaction{
+				Operation:   string(completed.TxnOperation),
+				Source:      &v1.Party{UserId: completed.Source.UserID.String(), UserType: string(completed.Source.UserType)},
+				Destination: &v1.Party{UserId: completed.Destination.UserID.String(), UserType: string(completed.Destination.UserType)},
+				Amount:      uint64(completed.Amount.ToPaisas()),
+				Charge:      uint64(completed.Charge),
+				CompletedAt: timestamppb.New(completed.CompletedAt),
+			},
+			ReceiptError: completed.ReceiptError.Error(),
+		}, nil
+	}
+
+	signed := *completed.Receipt
+	// the document is what the signature is checked against, the fields are for convenience
+	document, err := json.Marshal(signed)
+	if err != nil {
+		return nil, domainStatus(errors.Error{Err: err, Code: errors.EINTERNAL})
+	}
+
+	rct := signed.Receipt
+	return &v1.TransactionResponse{
+		Receipt: &v1.SignedReceipt{
+			Receipt: &v1.Receipt{
+				Id:          rct.ID.String(),
+				Operation:   string(rct.Operation),
+				Source:      &v1.Party{UserId: rct.Source.UserID.String(), UserType: string(rct.Source.UserType)},
+				Destination: &v1.Party{UserId: rct.Destination.UserID.String(), UserType: string(rct.Destination.UserType)},
+				Amount:      uint64(rct.Amount),
+				Charge:      uint64(rct.Charge),
+				Currency:    rct.Currency,
+				CompletedAt: timestamppb.New(rct.CompletedAt),
+			},
+			Algorithm: signed.Algorithm,
+			PublicKey: signed.PublicKey,
+			Signature: signed.Signature,
+			Document:  document,
+		},
+	}, nil
+}
+
+var _ v1.WalletOperationsServer = (*WalletOperations)(nil)
@@ -0,0 +1,398 @@
+package service
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/filter"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// WalletService is the gRPC service that starts engines and reports on them
+type WalletService struct {
+	v1.UnimplementedWalletServiceServer
+
+	engines  *engines
+	readOnly bool
+
+	// closed when the server shuts down, it ends the streams of Subscribe and Listen
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewWalletService returns a service that starts engines of the given specs. A read-only
+// service lists engines and their logs but doesn't start or stop any.
+func NewWalletService(specs []Spec, readOnly bool) *WalletService {
+	return &WalletService{engines: newEngines(specs), readOnly: readOnly, closing: make(chan struct{})}
+}
+
+// Close ends the streams of Subscribe and Listen, which would otherwise keep a
+// graceful stop of the gRPC server waiting. No more engines are started, those
+// waiting to run are stopped and those running are waited for until ctx ends,
+// so that they don't outlive the database.
+func (s *WalletService) Close(ctx context.Context) error {
+	s.closeOnce.Do(func() { close(s.closing) })
+	return s.engines.close(ctx)
+}
+
+// StartLocalEngine is not supported, engines run the specs built into the server
+func (s *WalletService) StartLocalEngine(stream v1.WalletService_StartLocalEngineServer) error {
+	return status.Error(codes.Unimplemented, "engines run the specs built into the server, local engines can't be uploaded")
+}
+
+// StartEngine starts an engine of the spec named by the metadata, or by the engine path
+func (s *WalletService) StartEngine(ctx context.Context, req *v1.StartEngineRequest) (*v1.StartEngineResponse, error) {
+	if s.readOnly {
+		return nil, domainStatus(errors.ErrReadOnly)
+	}
+	if len(req.EngineYaml) > 0 || len(req.Sideload) > 0 {
+		return nil, status.Error(codes.InvalidArgument, "engines run the specs built into the server, engine YAML and sideloads are not supported")
+	}
+
+	specName := req.GetMetadata().GetEngineSpecName()
+	if specName == "" {
+		specName = req.EnginePath
+	}
+
+	spec, ok := s.engines.specs[specName]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no engine spec named %q", specName)
+	}
+
+	metadata := req.Metadata
+	if metadata == nil {
+		metadata = &v1.EngineMetadata{}
+	}
+	if err := checkArguments(spec, metadata); err != nil {
+		return nil, err
+	}
+
+	return s.start(spec, metadata, waitUntil(req.WaitUntil), req.NameSuffix)
+}
+
+// StartFromPreviousEngine starts an engine of the same spec and with the same arguments as a previous one
+func (s *WalletService) StartFromPreviousEngine(ctx context.Context, req *v1.StartFromPreviousEngineRequest) (*v1.StartEngineResponse, error) {
+	if s.readOnly {
+		return nil, domainStatus(errors.ErrReadOnly)
+	}
+	previous, err := s.engines.get(req.PreviousEngine)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if !previous.GetConditions().GetCanReplay() {
+		return nil, toStatus(errEngineNotReplay)
+	}
+
+	spec, ok := s.engines.specs[previous.Metadata.EngineSpecName]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no engine spec named %q", previous.Metadata.EngineSpecName)
+	}
+
+	return s.start(spec, previous.Metadata, waitUntil(req.WaitUntil), "")
+}
+
+func (s *WalletService) start(spec Spec, metadata *v1.EngineMetadata, waitUntil time.Time, nameSuffix string) (*v1.StartEngineResponse, error) {
+	engineStatus, err := s.engines.start(spec, metadata, waitUntil, nameSuffix)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &v1.StartEngineResponse{Status: engineStatus}, nil
+}
+
+// ListEngines lists the engines started on this server that match the filter, the most
+// recently created first unless an order is given
+func (s *WalletService) ListEngines(ctx context.Context, req *v1.ListEnginesRequest) (*v1.ListEnginesResponse, error) {
+	if req.Start < 0 || req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "start and limit should not be negative")
+	}
+	if err := filter.Validate(req.Filter, req.Order); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var result []*v1.EngineStatus
+	for _, engineStatus := range s.engines.list() {
+		if ok, _ := filter.Match(engineStatus, req.Filter); ok {
+			result = append(result, engineStatus)
+		}
+	}
+	_ = filter.Sort(result, req.Order)
+	total := len(result)
+
+	start := int(req.Start)
+	if start > total {
+		start = total
+	}
+	result = result[start:]
+	if req.Limit > 0 && int(req.Limit) < len(result) {
+		result = result[:req.Limit]
+	}
+
+	return &v1.ListEnginesResponse{Total: int32(total), Result: result}, nil
+}
+
+// Subscribe streams the status of every engine that changes and matches the filter, until the client goes away
+func (s *WalletService) Subscribe(req *v1.SubscribeRequest, stream v1.WalletService_SubscribeServer) error {
+	if err := filter.Validate(req.Filter, nil); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	sub, unsubscribe := s.engines.subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case update := <-sub.updates:
+			if ok, _ := filter.Match(update, req.Filter); !ok {
+				continue
+			}
+			if err := stream.Send(&v1.SubscribeResponse{Result: update}); err != nil {
+				return err
+			}
+		case <-sub.dropped:
+			return toStatus(errSubscriberTooSlow)
+		case <-s.closing:
+			return toStatus(errShuttingDown)
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// GetEngine retrieves the status of a single engine
+func (s *WalletService) GetEngine(ctx context.Context, req *v1.GetEngineRequest) (*v1.GetEngineResponse, error) {
+	engineStatus, err := s.engines.get(req.Name)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &v1.GetEngineResponse{Result: engineStatus}, nil
+}
+
+// Listen streams the status and the log of an engine. Without follow it sends what there
+// is so far, with follow it keeps sending until the engine is done.
+func (s *WalletService) Listen(req *v1.ListenRequest, stream v1.WalletService_ListenServer) error {
+	level, err := ParseLevel(req.Level)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// subscribe first so that no update is missed between reading the status and listening
+	sub, unsubscribe := s.engines.subscribe()
+	defer unsubscribe()
+
+	current, logs, err := s.engines.logs(req.Name)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	l := listener{req: req, level: level, stream: stream}
+	if err := l.update(current); err != nil {
+		return err
+	}
+
+	var (
+		next  uint64
+		added <-chan struct{}
+	)
+	sendLogs := func() error {
+		var (
+			entries []LogEntry
+			dropped uint64
+		)
+		entries, dropped, next, added = logs.since(next)
+		return l.logs(entries, dropped)
+	}
+	if err := sendLogs(); err != nil {
+		return err
+	}
+
+	if current.Phase == v1.EnginePhase_PHASE_DONE {
+		return l.done(current)
+	}
+	if !req.Follow {
+		return nil
+	}
+
+	for {
+		select {
+		case <-added:
+			if err := sendLogs(); err != nil {
+				return err
+			}
+		case update := <-sub.updates:
+			if update.Name != req.Name {
+				continue
+			}
+			if update.Phase == v1.EnginePhase_PHASE_DONE {
+				// an engine has logged everything by the time it is done
+				if err := sendLogs(); err != nil {
+					return err
+				}
+				if err := l.update(update); err != nil {
+					return err
+				}
+				return l.done(update)
+			}
+			if err := l.update(update); err != nil {
+				return err
+			}
+		case <-sub.dropped:
+			return toStatus(errSubscriberTooSlow)
+		case <-s.closing:
+			return toStatus(errShuttingDown)
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// listener sends what a Listen request asked for
+type listener struct {
+	req    *v1.ListenRequest
+	level  Level
+	stream v1.WalletService_ListenServer
+}
+
+func (l listener) update(engineStatus *v1.EngineStatus) error {
+	if !l.req.Updates {
+		return nil
+	}
+	return l.stream.Send(&v1.ListenResponse{Content: &v1.ListenResponse_Update{Update: engineStatus}})
+}
+
+func (l listener) logs(entries []LogEntry, dropped uint64) error {
+	if l.req.Logs == v1.ListenRequestLogs_LOGS_DISABLED {
+		return nil
+	}
+
+	if dropped > 0 {
+		payload := fmt.Sprintf("%d log entries were dropped before they could be sent", dropped)
+		if err := l.slice(v1.LogSliceType_SLICE_ABANDONED, payload); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.Level < l.level {
+			continue
+		}
+
+		var payload string
+		switch l.req.Logs {
+		case v1.ListenRequestLogs_LOGS_RAW:
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			payload = string(data)
+		case v1.ListenRequestLogs_LOGS_HTML:
+			payload = entry.HTML()
+		default:
+			payload = entry.Text()
+		}
+
+		if err := l.slice(v1.LogSliceType_SLICE_CONTENT, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// done tells the log has ended, and whether the engine succeeded
+func (l listener) done(engineStatus *v1.EngineStatus) error {
+	if l.req.Logs == v1.ListenRequestLogs_LOGS_DISABLED {
+		return nil
+	}
+
+	if !engineStatus.GetConditions().GetSuccess() {
+		return l.slice(v1.LogSliceType_SLICE_FAIL, engineStatus.Details)
+	}
+	return l.slice(v1.LogSliceType_SLICE_DONE, "")
+}
+
+func (l listener) slice(typ v1.LogSliceType, payload string) error {
+	return l.stream.Send(&v1.ListenResponse{Content: &v1.ListenResponse_Slice{Slice: &v1.LogSliceEvent{
+		Name:    l.req.Name,
+		Type:    typ,
+		Payload: payload,
+	}}})
+}
+
+// StopEngine stops an engine that is waiting to run
+func (s *WalletService) StopEngine(ctx context.Context, req *v1.StopEngineRequest) (*v1.StopEngineResponse, error) {
+	if s.readOnly {
+		return nil, domainStatus(errors.ErrReadOnly)
+	}
+	if err := s.engines.stop(req.Name); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &v1.StopEngineResponse{}, nil
+}
+
+// checkArguments checks that the metadata has the annotations the spec requires
+func checkArguments(spec Spec, metadata *v1.EngineMetadata) error {
+	args := annotations(metadata)
+	for _, arg := range spec.Arguments {
+		if arg.Required && args[arg.Name] == "" {
+			return status.Errorf(codes.InvalidArgument, "engine %s requires the %q annotation", spec.Name, arg.Name)
+		}
+	}
+	return nil
+}
+
+// waitUntil returns the zero time when the engine should run right away
+func waitUntil(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// toStatus maps the errors of the engines to gRPC status errors
+func toStatus(err error) error {
+	switch err {
+	case errEngineNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case errEngineExists:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errEngineRunning, errEngineNotReplay:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errSubscriberTooSlow:
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errShuttingDown:
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+var _ v1.WalletServiceServer = (*WalletService)(nil)
@@ -0,0 +1,213 @@
+package service
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+	"github.com/bhojpur/wallet/pkg/helpers"
+	"github.com/bhojpur/wallet/pkg/registry"
+)
+
+// Spec describes an engine that can be started, a job of the wallet server run on demand
+type Spec struct {
+	Name        string
+	Description string
+	// arguments are passed to the engine as annotations of its metadata
+	Arguments []Argument
+	Run       func(ctx context.Context, args map[string]string) ([]*v1.EngineResult, error)
+}
+
+// Argument is an annotation an engine reads
+type Argument struct {
+	Name        string
+	Required    bool
+	Description string
+}
+
+// DomainSpecs returns the engines that run the batch jobs of the domain. The jobs also run
+// on their own schedule inside the server, they are safe to run again for the same period.
+func DomainSpecs(domain *registry.Domain) []Spec {
+	return []Spec{
+		{
+			Name:        "interest-accrue",
+			Description: "Accrues interest on savings products for a day",
+			Arguments:   []Argument{{Name: "day", Description: "day to accrue interest for as YYYY-MM-DD (defaults to yesterday)"}},
+			Run: func(ctx context.Context, args map[string]string) ([]*v1.EngineResult, error) {
+				day, err := dayArgument(args, "day", time.Now().AddDate(0, 0, -1))
+				if err != nil {
+					return nil, err
+				}
+				LoggerFrom(ctx).Info("accruing interest", Fields{"day": day.Format(helpers.DateLayout)})
+
+				recorded, err := domain.Interest.AccrueDay(day)
+				if err != nil {
+					return nil, err
+				}
+
+				return countResult("accounts", recorded, "interest accrued on %d accounts for %s", recorded, day.Format(helpers.DateLayout)), nil
+			},
+		},
+		{
+			Name:        "interest-post",
+			Description: "Credits the interest accrued in a month",
+			Arguments:   []Argument{{Name: "month", Description: "month to post interest for as YYYY-MM (defaults to last month)"}},
+			Run: func(ctx context.Context, args map[string]string) ([]*v1.EngineResult, error) {
+				month, err := monthArgument(args, "month")
+				if err != nil {
+					return nil, err
+				}
+
+				LoggerFrom(ctx).Info("posting interest", Fields{"month": month.Format(helpers.MonthLayout)})
+
+				credited, err := domain.Interest.PostMonth(month)
+				if err != nil {
+					return nil, err
+				}
+
+				return countResult("accounts", credited, "interest credited to %d accounts for %s", credited, month.Format(helpers.MonthLayout)), nil
+			},
+		},
+		{
+			Name:        "estatement-generate",
+			Description: "Generates and sends the monthly e-statements of a month that is over",
+			Arguments:   []Argument{{Name: "month", Description: "month to generate statements for as YYYY-MM (defaults to last month)"}},
+			Run: func(ctx context.Context, args map[string]string) ([]*v1.EngineResult, error) {
+				month, err := monthArgument(args, "month")
+				if err != nil {
+					return nil, err
+				}
+
+				LoggerFrom(ctx).Info("generating e-statements", Fields{"month": month.Format(helpers.MonthLayout)})
+
+				generated, err := domain.EStatement.GenerateMonth(month)
+				if err != nil {
+					return nil, err
+				}
+
+				return countResult("statements", generated, "%d statements generated for %s", generated, month.Format(helpers.MonthLayout)), nil
+			},
+		},
+		{
+			Name:        "reconcile",
+			Description: "Reconciles account balances against the statements ledger",
+			Run: func(ctx context.Context, args map[string]string) ([]*v1.EngineResult, error) {
+				report, err := domain.Reconciler.Reconcile()
+				if err != nil {
+					return nil, err
+				}
+
+				// the log names mismatches by id, account numbers and balances stay
+				// behind /api/admin/reconciliation
+				logger := LoggerFrom(ctx)
+				for _, mismatch := range report.Mismatches {
+					logger.Warn("balance does not match the ledger", Fields{
+						"mismatch": mismatch.ID,
+						"entries":  mismatch.Entries,
+					})
+				}
+
+				results := countResult("mismatches", len(report.Mismatches), "%d accounts reconciled, %d mismatches found, %d resolved",
+					report.Accounts, len(report.Mismatches), report.Resolved)
+				return results, nil
+			},
+		},
+		{
+			Name:        "ledger-verify",
+			Description: "Walks the hash chain of every account and reports the first broken link",
+			Run: func(ctx context.Context, args map[string]string) ([]*v1.EngineResult, error) {
+				verification, err := domain.Auditor.Verify()
+				if err != nil {
+					return nil, err
+				}
+
+				if broken := verification.Broken; broken != nil {
+					LoggerFrom(ctx).Error("ledger chain is broken", Fields{
+						"account":  broken.AccountID,
+						"sequence": broken.Sequence,
+						"entry":    broken.EntryID,
+						"reason":   broken.Reason,
+					})
+					return nil, fmt.Errorf("ledger broken at account %v entry %d (%v): %s",
+						broken.AccountID, broken.Sequence, broken.EntryID, broken.Reason)
+				}
+
+				return countResult("entries", int(verification.Entries), "%d entries of %d accounts verified",
+					verification.Entries, verification.Accounts), nil
+			},
+		},
+		{
+			Name:        "ledger-checkpoint",
+			Description: "Signs the current head of every chain of the ledger",
+			Run: func(ctx context.Context, args map[string]string) ([]*v1.EngineResult, error) {
+				checkpoint, err := domain.Auditor.Checkpoint()
+				if err != nil {
+					return nil, err
+				}
+
+				return []*v1.EngineResult{{
+					Type:        "checkpoint",
+					Payload:     checkpoint.Root,
+					Description: fmt.Sprintf("checkpoint %v covers %d entries of %d accounts", checkpoint.ID, checkpoint.Entries, checkpoint.Accounts),
+				}}, nil
+			},
+		},
+	}
+}
+
+func dayArgument(args map[string]string, name string, def time.Time) (time.Time, error) {
+	value, ok := args[name]
+	if !ok || value == "" {
+		return def, nil
+	}
+
+	day, err := time.ParseInLocation(helpers.DateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q, expected YYYY-MM-DD", name, value)
+	}
+	return day, nil
+}
+
+// monthArgument defaults to last month
+func monthArgument(args map[string]string, name string) (time.Time, error) {
+	value, ok := args[name]
+	if !ok || value == "" {
+		return helpers.LastMonth(time.Now()), nil
+	}
+
+	month, err := time.ParseInLocation(helpers.MonthLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q, expected YYYY-MM", name, value)
+	}
+	return month, nil
+}
+
+func countResult(kind string, count int, format string, args ...interface{}) []*v1.EngineResult {
+	return []*v1.EngineResult{{
+		Type:        kind,
+		Payload:     strconv.Itoa(count),
+		Description: fmt.Sprintf(format, args...),
+	}}
+}
@@ -0,0 +1,72 @@
+package service
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"log"
+
+	"github.com/bhojpur/wallet/pkg/errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusCodes maps the machine readable codes of the domain errors to gRPC status codes
+var statusCodes = map[errors.ERCode]codes.Code{
+	errors.ECONFLICT: codes.AlreadyExists,
+	errors.EINTERNAL: codes.Internal,
+	errors.EINVALID:  codes.InvalidArgument,
+	errors.ENOTFOUND: codes.NotFound,
+}
+
+// domainStatus turns an error of the domain into a gRPC status error, as the REST
+// error handler turns it into a response. Internal errors only give their message.
+func domainStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	log.Println(err)
+
+	switch e := err.(type) {
+	case errors.Unauthorized:
+		return status.Error(codes.Unauthenticated, e.Error())
+	case errors.Forbidden:
+		return status.Error(codes.PermissionDenied, e.Error())
+	case errors.ValidationErrors:
+		return status.Error(codes.InvalidArgument, e.Error())
+	case errors.Error:
+		if _, ok := e.Err.(errors.Unauthorized); ok {
+			return status.Error(codes.Unauthenticated, e.Error())
+		}
+
+		code, ok := statusCodes[errors.ErrorCode(e)]
+		if !ok {
+			// the REST API treats every other code as a bad request
+			code = codes.InvalidArgument
+		}
+		if code == codes.Internal {
+			return status.Error(code, errors.ErrorMessage(e))
+		}
+		return status.Error(code, e.Error())
+	}
+
+	return status.Error(codes.Internal, "Something has happened. Report Issue.")
+}
@@ -0,0 +1,72 @@
+package service
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+
+	v1 "github.com/bhojpur/wallet/pkg/api/v1"
+)
+
+// WalletUI serves what the web UI needs to know about the server
+type WalletUI struct {
+	v1.UnimplementedWalletUIServer
+
+	specs    []Spec
+	readOnly bool
+}
+
+// NewWalletUI returns a service describing the given specs to the web UI
+func NewWalletUI(specs []Spec, readOnly bool) *WalletUI {
+	return &WalletUI{specs: specs, readOnly: readOnly}
+}
+
+// ListEngineSpecs sends the specs engines can be started from. The path of a spec
+// is what StartEngine takes as engine path, the arguments are the annotations it reads.
+func (s *WalletUI) ListEngineSpecs(req *v1.ListEngineSpecsRequest, stream v1.WalletUI_ListEngineSpecsServer) error {
+	for _, spec := range s.specs {
+		arguments := make([]*v1.DesiredAnnotation, 0, len(spec.Arguments))
+		for _, argument := range spec.Arguments {
+			arguments = append(arguments, &v1.DesiredAnnotation{
+				Name:        argument.Name,
+				Required:    argument.Required,
+				Description: argument.Description,
+			})
+		}
+
+		err := stream.Send(&v1.ListEngineSpecsResponse{
+			Name:        spec.Name,
+			Path:        spec.Name,
+			Description: spec.Description,
+			Arguments:   arguments,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsReadOnly tells the web UI whether to offer starting and stopping engines
+func (s *WalletUI) IsReadOnly(ctx context.Context, req *v1.IsReadOnlyRequest) (*v1.IsReadOnlyResponse, error) {
+	return &v1.IsReadOnlyResponse{Readonly: s.readOnly}, nil
+}
@@ -0,0 +1,216 @@
+package statement
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/engine"
+	"github.com/bhojpur/wallet/pkg/worker"
+
+	"github.com/gofrs/uuid"
+)
+
+// how often the heads of the chains are signed
+const checkpointInterval = time.Hour
+
+// Auditor checks that nobody changed the ledger behind the application's back
+type Auditor interface {
+	Verify() (Verification, error)
+	Checkpoint() (Checkpoint, error)
+}
+
+// Verification is the outcome of walking every chain of the ledger
+type Verification struct {
+	Accounts int
+	Entries  int64
+	// the checkpoint the chains were checked against, nil before the first one
+	Checkpoint *Checkpoint
+	// the first broken link found, nil when the ledger is intact
+	Broken *BrokenLink
+}
+
+// BrokenLink is where a chain stops adding up
+type BrokenLink struct {
+	AccountID uuid.UUID
+	EntryID   uuid.UUID
+	Sequence  uint64
+	Reason    string
+}
+
+func NewAuditor(repository Repository, signer *engine.Wallet, workers *worker.Group) Auditor {
+	auditor := &auditor{repository: repository, signer: signer}
+
+	workers.Go("ledger checkpoint job", auditor.runCheckpointJob)
+
+	return auditor
+}
+
+type auditor struct {
+	repository Repository
+	signer     *engine.Wallet
+}
+
+// errBroken stops walking the ledger at the first broken link
+var errBroken = fmt.Errorf("broken link")
+
+// Verify walks the chain of every account and stops at the first link that doesn't hold:
+// a missing or repeated entry, an entry that doesn't point at the one before it or one
+// whose content no longer matches its hash. Chains are also checked against the heads
+// recorded in the latest checkpoint, which catches entries removed from the end of a chain.
+func (a auditor) Verify() (Verification, error) {
+	var verification Verification
+
+	heads := map[uuid.UUID]Head{}
+	checkpoint, err := a.repository.GetLatestCheckpoint()
+	if err != nil {
+		return verification, err
+	}
+	if checkpoint != nil {
+		verification.Checkpoint = checkpoint
+
+		signed, err := checkpoint.GetHeads(a.signer.PublicKey)
+		if err != nil {
+			verification.Broken = &BrokenLink{Reason: fmt.Sprintf("checkpoint %v: %v", checkpoint.ID, err)}
+			return verification, nil
+		}
+		for _, head := range signed {
+			heads[head.AccountID] = head
+		}
+	}
+
+	seen := map[uuid.UUID]bool{}
+	var prev Statement
+
+	// endOfChain checks that a chain reaches at least as far as the checkpoint
+	endOfChain := func(last Statement) *BrokenLink {
+		if head, ok := heads[last.AccountID]; ok && last.Sequence < head.Sequence {
+			return &BrokenLink{
+				AccountID: last.AccountID,
+				Sequence:  last.Sequence + 1,
+				Reason:    fmt.Sprintf("entries %d to %d recorded at the checkpoint are missing", last.Sequence+1, head.Sequence),
+			}
+		}
+		return nil
+	}
+
+	err = a.repository.Walk(func(stmt Statement) error {
+		if stmt.AccountID != prev.AccountID {
+			if broken := endOfChain(prev); broken != nil {
+				verification.Broken = broken
+				return errBroken
+			}
+			prev = Statement{AccountID: stmt.AccountID}
+			seen[stmt.AccountID] = true
+			verification.Accounts++
+		}
+		verification.Entries++
+
+		var reason string
+		switch {
+		case stmt.Sequence > prev.Sequence+1:
+			reason = fmt.Sprintf("entries %d to %d are missing", prev.Sequence+1, stmt.Sequence-1)
+		case stmt.Sequence <= prev.Sequence:
+			reason = "entry is numbered out of order"
+		case stmt.PrevHash != prev.Hash:
+			reason = "entry does not point at the entry before it"
+		case stmt.ComputeHash() != stmt.Hash:
+			reason = "entry has been changed since it was recorded"
+		}
+		if head, ok := heads[stmt.AccountID]; ok && reason == "" && head.Sequence == stmt.Sequence && head.Hash != stmt.Hash {
+			reason = "entry differs from the one recorded at the checkpoint"
+		}
+
+		if reason != "" {
+			verification.Broken = &BrokenLink{AccountID: stmt.AccountID, EntryID: stmt.ID, Sequence: stmt.Sequence, Reason: reason}
+			return errBroken
+		}
+
+		prev = stmt
+		return nil
+	})
+	if err == errBroken {
+		return verification, nil
+	} else if err != nil {
+		return verification, err
+	}
+
+	if broken := endOfChain(prev); broken != nil {
+		verification.Broken = broken
+		return verification, nil
+	}
+
+	// accounts whose entries are all gone
+	for accountID, head := range heads {
+		if !seen[accountID] {
+			verification.Broken = &BrokenLink{
+				AccountID: accountID,
+				Sequence:  1,
+				Reason:    fmt.Sprintf("entries 1 to %d recorded at the checkpoint are missing", head.Sequence),
+			}
+			break
+		}
+	}
+
+	return verification, nil
+}
+
+// Checkpoint signs the current head of every chain. Nothing is recorded when the
+// ledger hasn't changed since the latest checkpoint, which is returned instead.
+func (a auditor) Checkpoint() (Checkpoint, error) {
+	heads, err := a.repository.GetHeads()
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	checkpoint, err := newCheckpoint(heads, a.signer)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	latest, err := a.repository.GetLatestCheckpoint()
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	if latest != nil && latest.Root == checkpoint.Root {
+		return *latest, nil
+	}
+
+	return a.repository.AddCheckpoint(checkpoint)
+}
+
+func (a auditor) runCheckpointJob(done <-chan struct{}) {
+	ticker := time.NewTicker(checkpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+		if _, err := a.Checkpoint(); err != nil {
+			log.Printf("error happened while checkpointing the ledger %v", err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package statement
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+
+	"github.com/bhojpur/wallet/pkg/engine"
+)
+
+// ComputeHash returns the hex encoded SHA-256 hash of the entry and the hash of the
+// entry before it. Amounts are hashed in paisas and the time in microseconds, which
+// is what the database keeps.
+func (s Statement) ComputeHash() string {
+	payload := fmt.Sprintf("%s|%s|%s|%d|%s|%d|%d|%d|%d|%s|%s|%d|%s",
+		s.ID, s.AccountID, s.UserID, s.Sequence, s.Operation,
+		toPaisas(s.DebitAmount), toPaisas(s.CreditAmount), toPaisas(s.Balance), toPaisas(s.OverdraftBalance),
+		s.CounterpartyID, s.CounterpartyType, s.CreatedAt.UnixMicro(), s.PrevHash,
+	)
+
+	return hex.EncodeToString(engine.Sha256([]byte(payload)))
+}
+
+// link makes the entry the next one in the chain after last, last is a zero
+// Statement for the first entry of an account
+func (s *Statement) link(last Statement) {
+	s.Sequence = last.Sequence + 1
+	s.PrevHash = last.Hash
+	s.Hash = s.ComputeHash()
+}
+
+func toPaisas(rupees float64) int64 {
+	return int64(math.Round(rupees * 100))
+}
@@ -0,0 +1,301 @@
+package statement
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/engine"
+	"github.com/bhojpur/wallet/pkg/models"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestComputeHash(t *testing.T) {
+	stmt := Statement{
+		ID:           uuid.Must(uuid.NewV4()),
+		Operation:    models.TxnOpDeposit,
+		CreditAmount: 100.5,
+		Balance:      100.5,
+		UserID:       uuid.Must(uuid.NewV4()),
+		AccountID:    uuid.Must(uuid.NewV4()),
+		CreatedAt:    time.Date(2022, 3, 14, 9, 26, 53, 123456000, time.UTC),
+	}
+	stmt.link(Statement{})
+	if stmt.Sequence != 1 || stmt.PrevHash != "" || stmt.Hash != stmt.ComputeHash() {
+		t.Fatalf("first entry linked as %d %q %q", stmt.Sequence, stmt.PrevHash, stmt.Hash)
+	}
+
+	// what the database gives back hashes the same
+	stored := stmt
+	stored.CreditAmount = 100.50000000001
+	stored.CreatedAt = stmt.CreatedAt.Add(789 * time.Nanosecond).In(time.FixedZone("IST", 5*3600+1800))
+	if stored.ComputeHash() != stmt.Hash {
+		t.Error("hash depends on more than paisas and microseconds")
+	}
+
+	changes := map[string]func(*Statement){
+		"amount":       func(s *Statement) { s.CreditAmount += 0.01 },
+		"balance":      func(s *Statement) { s.Balance -= 1 },
+		"operation":    func(s *Statement) { s.Operation = models.TxnOpWithdraw },
+		"sequence":     func(s *Statement) { s.Sequence++ },
+		"previous":     func(s *Statement) { s.PrevHash = stmt.Hash },
+		"time":         func(s *Statement) { s.CreatedAt = s.CreatedAt.Add(time.Microsecond) },
+		"counterparty": func(s *Statement) { s.CounterpartyID = uuid.Must(uuid.NewV4()) },
+	}
+	for name, change := range changes {
+		changed := stmt
+		change(&changed)
+		if changed.ComputeHash() == stmt.Hash {
+			t.Errorf("changing the %s of an entry keeps its hash", name)
+		}
+	}
+
+	next := Statement{ID: uuid.Must(uuid.NewV4()), AccountID: stmt.AccountID, DebitAmount: 50, Balance: 50.5}
+	next.link(stmt)
+	if next.Sequence != 2 || next.PrevHash != stmt.Hash {
+		t.Errorf("second entry linked as %d %q, want 2 %q", next.Sequence, next.PrevHash, stmt.Hash)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	signer := engine.NewWallet()
+	accounts := []uuid.UUID{uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())}
+	// the first account is the first one walked
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].String() < accounts[j].String() })
+
+	cases := []struct {
+		name string
+		// tamper changes the ledger after the checkpoint was taken
+		tamper     func(ledger *memoryLedger)
+		checkpoint bool
+		// the broken link in the chain of the first account, by the entry as it was
+		// first recorded and its sequence, no entry when the chain ends too early
+		entry    int
+		sequence uint64
+		reason   string
+	}{
+		{name: "intact", tamper: func(*memoryLedger) {}},
+		{name: "intact at the checkpoint", tamper: func(*memoryLedger) {}, checkpoint: true},
+		{
+			name:   "modified amount",
+			tamper: func(l *memoryLedger) { l.entries[1].CreditAmount += 1 },
+			entry:  2, sequence: 2, reason: "changed since it was recorded",
+		},
+		{
+			name: "reordered entries",
+			tamper: func(l *memoryLedger) {
+				l.entries[1].Sequence, l.entries[2].Sequence = l.entries[2].Sequence, l.entries[1].Sequence
+				l.sort()
+			},
+			// the third entry took the second place, it points at the second entry
+			entry: 3, sequence: 2, reason: "does not point at the entry before it",
+		},
+		{
+			name:   "deleted entry",
+			tamper: func(l *memoryLedger) { l.remove(1) },
+			entry:  3, sequence: 3, reason: "entries 2 to 2 are missing",
+		},
+		{
+			name:       "deleted last entry",
+			tamper:     func(l *memoryLedger) { l.remove(2) },
+			checkpoint: true,
+			sequence:   3, reason: "entries 3 to 3 recorded at the checkpoint are missing",
+		},
+		{
+			name: "rebuilt chain",
+			tamper: func(l *memoryLedger) {
+				l.entries[2].CreditAmount += 1
+				l.entries[2].link(l.entries[1])
+			},
+			checkpoint: true,
+			entry:      3, sequence: 3, reason: "differs from the one recorded at the checkpoint",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ledger := newMemoryLedger(accounts, 3)
+			original := append([]Statement(nil), ledger.entries...)
+			ledgerAuditor := auditor{repository: ledger, signer: signer}
+			if c.checkpoint {
+				if _, err := ledgerAuditor.Checkpoint(); err != nil {
+					t.Fatalf("Checkpoint failed: %v", err)
+				}
+			}
+			c.tamper(ledger)
+
+			verification, err := ledgerAuditor.Verify()
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if c.reason == "" {
+				if verification.Broken != nil {
+					t.Fatalf("Verify found %+v in an intact ledger", verification.Broken)
+				}
+				if verification.Accounts != 2 || verification.Entries != 6 {
+					t.Errorf("Verify walked %d accounts and %d entries, want 2 and 6", verification.Accounts, verification.Entries)
+				}
+				return
+			}
+
+			broken := verification.Broken
+			if broken == nil {
+				t.Fatal("Verify found no broken link")
+			}
+			if broken.AccountID != accounts[0] || !strings.Contains(broken.Reason, c.reason) {
+				t.Errorf("Verify found %+v, want %q in account %v", broken, c.reason, accounts[0])
+			}
+			var entryID uuid.UUID
+			if c.entry != 0 {
+				entryID = original[c.entry-1].ID
+			}
+			if broken.EntryID != entryID || broken.Sequence != c.sequence {
+				t.Errorf("Verify broke at entry %v sequence %d, want %v sequence %d", broken.EntryID, broken.Sequence, entryID, c.sequence)
+			}
+		})
+	}
+}
+
+func TestVerifyCheckpoint(t *testing.T) {
+	signer := engine.NewWallet()
+	ledger := newMemoryLedger([]uuid.UUID{uuid.Must(uuid.NewV4())}, 2)
+	checkpointer := auditor{repository: ledger, signer: signer}
+	if _, err := checkpointer.Checkpoint(); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	if checkpoint, err := checkpointer.Checkpoint(); err != nil || len(ledger.checkpoints) != 1 || checkpoint.Root != ledger.checkpoints[0].Root {
+		t.Fatalf("Checkpoint of an unchanged ledger = %v, %v, want the latest checkpoint", checkpoint.Root, err)
+	}
+
+	other := engine.NewWallet()
+	forged, err := newCheckpoint([]Head{}, other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := map[string]func(*Checkpoint){
+		"heads": func(c *Checkpoint) {
+			c.Heads = bytes.Replace(c.Heads, []byte(`"sequence":2`), []byte(`"sequence":1`), 1)
+		},
+		"root": func(c *Checkpoint) { c.Root = forged.Root },
+		// the key it names is another, or it is another signature
+		"public key": func(c *Checkpoint) { c.PublicKey = forged.PublicKey },
+		"signature":  func(c *Checkpoint) { c.Signature = forged.Signature },
+		"forged":     func(c *Checkpoint) { *c = forged },
+	}
+	for name, change := range changes {
+		checkpoint := ledger.checkpoints[0]
+		change(&checkpoint)
+		tampered := &memoryLedger{entries: ledger.entries, checkpoints: []Checkpoint{checkpoint}}
+
+		verification, err := (auditor{repository: tampered, signer: signer}).Verify()
+		if err != nil {
+			t.Fatalf("Verify with a tampered %s failed: %v", name, err)
+		}
+		if verification.Broken == nil || !strings.Contains(verification.Broken.Reason, "checkpoint") {
+			t.Errorf("Verify with a tampered %s = %+v, want a broken checkpoint", name, verification.Broken)
+		}
+	}
+}
+
+// memoryLedger keeps the entries of the ledger ordered by account and sequence, as
+// Walk goes through them
+type memoryLedger struct {
+	Repository
+	entries     []Statement
+	checkpoints []Checkpoint
+}
+
+// newMemoryLedger chains n entries for each of the accounts
+func newMemoryLedger(accounts []uuid.UUID, n int) *memoryLedger {
+	ledger := &memoryLedger{}
+	created := time.Date(2022, 3, 14, 9, 0, 0, 0, time.UTC)
+	for _, accountID := range accounts {
+		last := Statement{}
+		for i := 0; i < n; i++ {
+			stmt := Statement{
+				ID:           uuid.Must(uuid.NewV4()),
+				Operation:    models.TxnOpDeposit,
+				CreditAmount: 100,
+				Balance:      float64(100 * (i + 1)),
+				UserID:       accountID,
+				AccountID:    accountID,
+				CreatedAt:    created.Add(time.Duration(i) * time.Minute),
+			}
+			stmt.link(last)
+			ledger.entries = append(ledger.entries, stmt)
+			last = stmt
+		}
+	}
+	ledger.sort()
+	return ledger
+}
+
+func (l *memoryLedger) sort() {
+	sort.SliceStable(l.entries, func(i, j int) bool {
+		a, b := l.entries[i], l.entries[j]
+		if a.AccountID != b.AccountID {
+			return a.AccountID.String() < b.AccountID.String()
+		}
+		return a.Sequence < b.Sequence
+	})
+}
+
+func (l *memoryLedger) remove(i int) {
+	l.entries = append(l.entries[:i:i], l.entries[i+1:]...)
+}
+
+func (l *memoryLedger) Walk(fn func(Statement) error) error {
+	for _, stmt := range l.entries {
+		if err := fn(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *memoryLedger) GetHeads() ([]Head, error) {
+	var heads []Head
+	for i, stmt := range l.entries {
+		if i+1 == len(l.entries) || l.entries[i+1].AccountID != stmt.AccountID {
+			heads = append(heads, Head{AccountID: stmt.AccountID, Sequence: stmt.Sequence, Hash: stmt.Hash})
+		}
+	}
+	return heads, nil
+}
+
+func (l *memoryLedger) AddCheckpoint(checkpoint Checkpoint) (Checkpoint, error) {
+	checkpoint.ID = uuid.Must(uuid.NewV4())
+	l.checkpoints = append(l.checkpoints, checkpoint)
+	return checkpoint, nil
+}
+
+func (l *memoryLedger) GetLatestCheckpoint() (*Checkpoint, error) {
+	if len(l.checkpoints) == 0 {
+		return nil, nil
+	}
+	latest := l.checkpoints[len(l.checkpoints)-1]
+	return &latest, nil
+}
@@ -0,0 +1,121 @@
+package statement
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/engine"
+	"github.com/bhojpur/wallet/pkg/errors"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Head is the last entry in the chain of an account
+type Head struct {
+	AccountID uuid.UUID `json:"accountId"`
+	Sequence  uint64    `json:"sequence"`
+	Hash      string    `json:"hash"`
+}
+
+// Checkpoint is a signed record of the head of every chain at a point in time. Entries
+// can't be removed from the end of a chain, or a chain rebuilt, without it showing.
+type Checkpoint struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+
+	Accounts int   `gorm:"column:accounts"`
+	Entries  int64 `gorm:"column:entries"`
+	// the heads as JSON, ordered by account, and their hex encoded SHA-256 hash
+	Heads []byte `gorm:"column:heads"`
+	Root  string `gorm:"column:root;not null"`
+
+	// hex encoded public key of the engine wallet that signed the root,
+	// and the ASN.1 signature
+	PublicKey string `gorm:"column:public_key"`
+	Signature string `gorm:"column:signature"`
+}
+
+func (c *Checkpoint) BeforeCreate(tx *gorm.DB) error {
+	c.ID, _ = uuid.NewV4()
+	return nil
+}
+
+func (Checkpoint) TableName() string {
+	return "ledger_checkpoints"
+}
+
+// newCheckpoint signs the given heads, which should be ordered by account
+func newCheckpoint(heads []Head, signer *engine.Wallet) (Checkpoint, error) {
+	content, err := json.Marshal(heads)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	root := engine.Sha256(content)
+	sig, err := signer.Sign(root)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	var entries int64
+	for _, head := range heads {
+		entries += int64(head.Sequence)
+	}
+
+	return Checkpoint{
+		Accounts:  len(heads),
+		Entries:   entries,
+		Heads:     content,
+		Root:      hex.EncodeToString(root),
+		PublicKey: hex.EncodeToString(signer.PublicKey),
+		Signature: hex.EncodeToString(sig),
+	}, nil
+}
+
+// GetHeads checks the root of the checkpoint and that it was signed with the given public
+// key, the key the checkpoint says it was signed with can't be trusted on its own
+func (c Checkpoint) GetHeads(publicKey []byte) ([]Head, error) {
+	invalid := errors.Error{Code: errors.EINVALID, Message: errors.ErrCheckpointInvalid}
+
+	root := engine.Sha256(c.Heads)
+	if hex.EncodeToString(root) != c.Root || c.PublicKey != hex.EncodeToString(publicKey) {
+		return nil, invalid
+	}
+
+	sig, err := hex.DecodeString(c.Signature)
+	if err != nil {
+		return nil, invalid
+	}
+	if ok, err := engine.Verify(publicKey, root, sig); err != nil || !ok {
+		return nil, invalid
+	}
+
+	var heads []Head
+	if err := json.Unmarshal(c.Heads, &heads); err != nil {
+		return nil, err
+	}
+
+	return heads, nil
+}
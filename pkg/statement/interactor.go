@@ -23,6 +23,8 @@ package statement
 import (
 	"time"
 
+	"github.com/bhojpur/wallet/pkg/models"
+
 	"github.com/gofrs/uuid"
 )
 
@@ -30,6 +32,20 @@ const miniStatementCount = uint(5)
 
 type Interactor interface {
 	GetStatement(userId uuid.UUID) ([]Statement, error)
+	GetStatementPage(userID uuid.UUID, filter Filter) (Page, error)
+}
+
+// Page is a page of the entries recorded on an account in a period, newest first. Every
+// entry carries the balance of the account after it, which is the running balance.
+type Page struct {
+	Statements []Statement
+
+	// balance of the account at the start and at the end of the period
+	OpeningBalance models.Paisas
+	ClosingBalance models.Paisas
+
+	// empty on the last page
+	NextCursor string
 }
 
 type interactor struct {
@@ -49,3 +65,38 @@ func (i interactor) GetStatement(userID uuid.UUID) ([]Statement, error) {
 
 	return transactions, nil
 }
+
+// GetStatementPage returns a page of the statement of a user. The opening and closing
+// balances are for the whole period and don't change from page to page.
+func (i interactor) GetStatementPage(userID uuid.UUID, filter Filter) (Page, error) {
+	// fetch one more entry than asked for to know if there is a next page
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+	filter.Limit = limit + 1
+
+	statements, err := i.repository.GetPage(userID, filter)
+	if err != nil {
+		return Page{}, err
+	}
+
+	page := Page{Statements: statements}
+	if uint(len(statements)) > limit {
+		page.Statements = statements[:limit]
+		last := page.Statements[limit-1]
+		page.NextCursor = Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	page.OpeningBalance, err = i.repository.GetUserBalanceAt(userID, filter.From)
+	if err != nil {
+		return Page{}, err
+	}
+
+	page.ClosingBalance, err = i.repository.GetUserBalanceAt(userID, filter.To)
+	if err != nil {
+		return Page{}, err
+	}
+
+	return page, nil
+}
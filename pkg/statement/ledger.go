@@ -29,7 +29,7 @@ import (
 )
 
 type Ledger interface {
-	Record(userID uuid.UUID, acc models.Account, txnOp models.TxnOperation, amount models.Rupees, stmtType Type) error
+	Record(userID uuid.UUID, acc models.Account, txnOp models.TxnOperation, amount models.Paisas, stmtType Type, counterparty models.TxnCustomer) error
 }
 
 func NewLedger(repository Repository) Ledger {
@@ -40,18 +40,23 @@ type ledger struct {
 	statementRepo Repository
 }
 
-func (l ledger) Record(userID uuid.UUID, acc models.Account, txnOp models.TxnOperation, amount models.Rupees, stmtType Type) error {
+func (l ledger) Record(userID uuid.UUID, acc models.Account, txnOp models.TxnOperation, amount models.Paisas, stmtType Type, counterparty models.TxnCustomer) error {
 	statement := Statement{
 		Operation: txnOp,
 		UserID:    userID,
 		AccountID: acc.ID,
-		CreatedAt: time.Now(),
+		// the database keeps microseconds, the entry is hashed with what is stored
+		CreatedAt:        time.Now().Truncate(time.Microsecond),
+		Balance:          acc.Balance(),
+		OverdraftBalance: acc.OverdraftBalance(),
+		CounterpartyID:   counterparty.UserID,
+		CounterpartyType: counterparty.UserType,
 	}
 
 	if stmtType == TypeCredit {
-		statement.CreditAmount = float64(amount)
+		statement.CreditAmount = amount.ToFloat()
 	} else if stmtType == TypeDebit {
-		statement.DebitAmount = float64(amount)
+		statement.DebitAmount = amount.ToFloat()
 	}
 
 	_, err := l.statementRepo.Add(statement)
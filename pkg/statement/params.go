@@ -0,0 +1,200 @@
+package statement
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/helpers"
+	"github.com/bhojpur/wallet/pkg/models"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	defaultPageLimit = uint(20)
+	maxPageLimit     = uint(100)
+	// a statement covers the last 30 days unless told otherwise
+	defaultPeriod = 30 * 24 * time.Hour
+)
+
+// operations lists every operation an entry on a statement can have
+var operations = []models.TxnOperation{
+	models.TxnOpDeposit,
+	models.TxnOpWithdraw,
+	models.TxnOpTransfer,
+	models.TxnFloatAssignment,
+	models.TxnOpOverdraftCharge,
+	models.TxnOpInterest,
+	models.TxnOpPocketDeposit,
+	models.TxnOpPocketWithdraw,
+	models.TxnOpPocketPenalty,
+	models.TxnOpReconciliation,
+}
+
+// Params are the query parameters of a statement request. The days are inclusive,
+// operations are comma separated and a page continues from the cursor of the previous one.
+type Params struct {
+	From       string `query:"from"`
+	To         string `query:"to"`
+	Cursor     string `query:"cursor"`
+	Limit      uint   `query:"limit"`
+	Operations string `query:"operation"`
+}
+
+func (req Params) Validate() error {
+	err := validation.ValidateStruct(&req,
+		validation.Field(&req.From, validation.Date(helpers.DateLayout).Error(string(errors.ErrorInvalidDate))),
+		validation.Field(&req.To,
+			validation.Date(helpers.DateLayout).Error(string(errors.ErrorInvalidDate)),
+			validation.By(func(interface{}) error {
+				if req.From != "" && req.To != "" && req.From > req.To {
+					return errors.ErrorInvalidPeriod
+				}
+				return nil
+			}),
+		),
+		validation.Field(&req.Cursor, validation.By(func(interface{}) error {
+			if _, err := decodeCursor(req.Cursor); err != nil {
+				return errors.ErrorInvalidCursor
+			}
+			return nil
+		})),
+		validation.Field(&req.Limit, validation.Max(maxPageLimit).Error(string(errors.ErrorInvalidPageLimit))),
+		validation.Field(&req.Operations, validation.By(func(interface{}) error {
+			for _, op := range splitOperations(req.Operations) {
+				if !isStatementOperation(op) {
+					return errors.ErrorInvalidOperation
+				}
+			}
+			return nil
+		})),
+	)
+
+	return errors.ParseValidationErrorMap(err)
+}
+
+// Filter returns the filter the parameters describe, they should be validated first
+func (req Params) Filter() Filter {
+	now := time.Now()
+	filter := Filter{
+		From:       startOfDay(now.Add(-defaultPeriod)),
+		To:         now,
+		Limit:      defaultPageLimit,
+		Operations: splitOperations(req.Operations),
+	}
+
+	if req.From != "" {
+		filter.From, _ = time.ParseInLocation(helpers.DateLayout, req.From, now.Location())
+	}
+	if req.To != "" {
+		// the last day is included in full
+		to, _ := time.ParseInLocation(helpers.DateLayout, req.To, now.Location())
+		filter.To = to.AddDate(0, 0, 1)
+	}
+	if req.Limit > 0 {
+		filter.Limit = req.Limit
+	}
+	filter.Cursor, _ = decodeCursor(req.Cursor)
+
+	return filter
+}
+
+// Filter selects a page of the entries recorded in a period, newest first
+type Filter struct {
+	From time.Time // inclusive
+	To   time.Time // exclusive
+
+	// entries recorded before the cursor, nil for the first page
+	Cursor *Cursor
+	Limit  uint
+	// when empty every operation is included
+	Operations []models.TxnOperation
+}
+
+// Cursor points at the last entry of a page. Entries are ordered by the time they
+// were recorded and then by id, so that pages never skip or repeat an entry.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Encode returns the cursor as an opaque string clients pass back to get the next page
+func (c Cursor) Encode() string {
+	raw := fmt.Sprintf("%s|%s", c.CreatedAt.UTC().Format(time.RFC3339Nano), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (*Cursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed cursor %q", raw)
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.FromString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+func splitOperations(ops string) []models.TxnOperation {
+	var operations []models.TxnOperation
+	for _, op := range strings.Split(ops, ",") {
+		op = strings.ToUpper(strings.TrimSpace(op))
+		if op != "" {
+			operations = append(operations, models.TxnOperation(op))
+		}
+	}
+	return operations
+}
+
+func isStatementOperation(op models.TxnOperation) bool {
+	for _, o := range operations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
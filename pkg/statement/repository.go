@@ -21,19 +21,41 @@ package statement
 // THE SOFTWARE.
 
 import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
 	"github.com/bhojpur/wallet/pkg/storage"
 
 	"github.com/gofrs/uuid"
+	"github.com/jackc/pgconn"
+	"gorm.io/gorm"
 )
 
 type Repository interface {
 	Add(Statement) (Statement, error)
 	GetStatements(userID uuid.UUID, from time.Time, limit uint) ([]Statement, error)
+	GetPage(userID uuid.UUID, filter Filter) ([]Statement, error)
+	GetBalanceAt(accountID uuid.UUID, at time.Time) (models.Paisas, error)
+	GetUserBalanceAt(userID uuid.UUID, at time.Time) (models.Paisas, error)
+	GetLastBefore(userID uuid.UUID, at time.Time) (Statement, error)
+	Stream(userID uuid.UUID, from, to time.Time, fn func(Statement) error) error
+
+	Walk(fn func(Statement) error) error
+	GetHeads() ([]Head, error)
+	AddCheckpoint(Checkpoint) (Checkpoint, error)
+	GetLatestCheckpoint() (*Checkpoint, error)
+	ChainLegacyEntries() (int, error)
 }
 
+// chainAttempts is how many times adding an entry is tried when another
+// entry of the same account took the next place in the chain
+const chainAttempts = 5
+
 func NewRepository(database *storage.Database) Repository {
 	return &repository{db: database}
 }
@@ -42,13 +64,36 @@ type repository struct {
 	db *storage.Database
 }
 
+// Add appends an entry to the chain of its account. The unique sequence of the
+// chain stops two entries from being linked to the same entry before them.
 func (r repository) Add(stmt Statement) (Statement, error) {
-	result := r.db.Create(&stmt)
-	if err := result.Error; err != nil {
-		return Statement{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	if stmt.ID == uuid.Nil {
+		stmt.ID, _ = uuid.NewV4()
 	}
 
-	return stmt, nil
+	for attempt := 0; attempt < chainAttempts; attempt++ {
+		var last Statement
+		result := r.db.Where(
+			Statement{AccountID: stmt.AccountID},
+		).Order("sequence desc").Limit(1).Find(&last)
+		if err := result.Error; err != nil {
+			return Statement{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+		}
+
+		stmt.link(last)
+
+		result = r.db.Create(&stmt)
+		if err := result.Error; err != nil {
+			if pgerr, ok := err.(*pgconn.PgError); ok && pgerr.Code == "23505" && strings.Contains(pgerr.ConstraintName, "sequence") {
+				continue
+			}
+			return Statement{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+		}
+
+		return stmt, nil
+	}
+
+	return Statement{}, errors.Error{Code: errors.EINTERNAL, Err: fmt.Errorf("could not append entry to the chain of account %v", stmt.AccountID)}
 }
 
 func (r repository) GetStatements(userID uuid.UUID, from time.Time, limit uint) ([]Statement, error) {
@@ -66,3 +111,226 @@ func (r repository) GetStatements(userID uuid.UUID, from time.Time, limit uint)
 
 	return statements, nil
 }
+
+// GetPage returns the entries of a user matching the filter, newest first. It fetches
+// up to filter.Limit entries, callers ask for one more to know whether a next page exists.
+func (r repository) GetPage(userID uuid.UUID, filter Filter) ([]Statement, error) {
+	var statements []Statement
+
+	query := r.db.Where(
+		Statement{UserID: userID},
+	).Where(
+		"created_at >= ? AND created_at < ?", filter.From, filter.To,
+	)
+
+	if len(filter.Operations) > 0 {
+		query = query.Where("operation IN ?", filter.Operations)
+	}
+	if filter.Cursor != nil {
+		query = query.Where("(created_at, id) < (?, ?)", filter.Cursor.CreatedAt, filter.Cursor.ID)
+	}
+
+	result := query.Order("created_at desc, id desc").Limit(int(filter.Limit)).Find(&statements)
+	if err := result.Error; err != nil {
+		return nil, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return statements, nil
+}
+
+// GetBalanceAt returns the balance of an account at a point in time, which is the
+// balance after the last entry recorded before it. An account with no entries
+// before then had no balance.
+func (r repository) GetBalanceAt(accountID uuid.UUID, at time.Time) (models.Paisas, error) {
+	return r.balanceAt(Statement{AccountID: accountID}, at)
+}
+
+// GetUserBalanceAt returns the balance of the account of a user at a point in time
+func (r repository) GetUserBalanceAt(userID uuid.UUID, at time.Time) (models.Paisas, error) {
+	return r.balanceAt(Statement{UserID: userID}, at)
+}
+
+func (r repository) balanceAt(where Statement, at time.Time) (models.Paisas, error) {
+	statement, err := r.lastBefore(where, at)
+	if err != nil {
+		return 0, err
+	}
+
+	return models.Paisas(math.Round(statement.Balance * 100)), nil
+}
+
+// GetLastBefore returns the last entry of a user recorded before a point in time, it is
+// a zero Statement when there is none. Unlike the balance it carries the overdraft too.
+func (r repository) GetLastBefore(userID uuid.UUID, at time.Time) (Statement, error) {
+	return r.lastBefore(Statement{UserID: userID}, at)
+}
+
+func (r repository) lastBefore(where Statement, at time.Time) (Statement, error) {
+	var statement Statement
+
+	result := r.db.Where(
+		where,
+	).Where(
+		"created_at < ?", at,
+	).Order("created_at desc, id desc").Limit(1).Find(&statement)
+
+	if err := result.Error; err != nil {
+		return Statement{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return statement, nil
+}
+
+// Stream calls fn with every entry of a user recorded in [from, to), oldest first. Entries
+// are read one row at a time so that long statements aren't loaded into memory, an error
+// from fn stops the stream and is returned.
+func (r repository) Stream(userID uuid.UUID, from, to time.Time, fn func(Statement) error) error {
+	rows, err := r.db.Model(&Statement{}).Where(
+		Statement{UserID: userID},
+	).Where(
+		"created_at >= ? AND created_at < ?", from, to,
+	).Order("created_at asc, id asc").Rows()
+	if err != nil {
+		return errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var statement Statement
+		if err := r.db.ScanRows(rows, &statement); err != nil {
+			return errors.Error{Err: err, Code: errors.EINTERNAL}
+		}
+
+		if err := fn(statement); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return nil
+}
+
+// Walk calls fn with every entry of the ledger, account by account in chain order
+func (r repository) Walk(fn func(Statement) error) error {
+	rows, err := r.db.Model(&Statement{}).Order("account_id, sequence").Rows()
+	if err != nil {
+		return errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var statement Statement
+		if err := r.db.ScanRows(rows, &statement); err != nil {
+			return errors.Error{Err: err, Code: errors.EINTERNAL}
+		}
+
+		if err := fn(statement); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return nil
+}
+
+// GetHeads fetches the last entry in the chain of every account, ordered by account
+func (r repository) GetHeads() ([]Head, error) {
+	var heads []Head
+
+	result := r.db.Raw(
+		"SELECT DISTINCT ON (account_id) account_id, sequence, hash FROM statements ORDER BY account_id, sequence DESC",
+	).Scan(&heads)
+	if err := result.Error; err != nil {
+		return nil, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return heads, nil
+}
+
+func (r repository) AddCheckpoint(checkpoint Checkpoint) (Checkpoint, error) {
+	result := r.db.Create(&checkpoint)
+	if err := result.Error; err != nil {
+		return Checkpoint{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return checkpoint, nil
+}
+
+// GetLatestCheckpoint fetches the last checkpoint taken, nil if there is none
+func (r repository) GetLatestCheckpoint() (*Checkpoint, error) {
+	var checkpoint Checkpoint
+
+	result := r.db.Order("created_at desc").Limit(1).Find(&checkpoint)
+	if err := result.Error; err != nil {
+		return nil, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return &checkpoint, nil
+}
+
+// ChainLegacyEntries links the entries recorded before the ledger was chained, in the
+// order they were recorded. It returns the number of accounts whose entries were linked.
+func (r repository) ChainLegacyEntries() (int, error) {
+	var accountIDs []uuid.UUID
+
+	result := r.db.Model(&Statement{}).Where("hash IS NULL OR hash = ''").Distinct().Pluck("account_id", &accountIDs)
+	if err := result.Error; err != nil {
+		return 0, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	var chained int
+	for _, accountID := range accountIDs {
+		var entries []Statement
+		result := r.db.Where(Statement{AccountID: accountID}).Order("created_at, id").Find(&entries)
+		if err := result.Error; err != nil {
+			return chained, errors.Error{Err: err, Code: errors.EINTERNAL}
+		}
+
+		// an account whose chain has started needs looking into, not relinking
+		if chainStarted(entries) {
+			log.Printf("account %v has entries recorded out of its chain", accountID)
+			continue
+		}
+
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			var last Statement
+			for _, entry := range entries {
+				entry.link(last)
+				err := tx.Model(&Statement{}).Where("id = ?", entry.ID).Updates(map[string]interface{}{
+					"sequence":  entry.Sequence,
+					"prev_hash": entry.PrevHash,
+					"hash":      entry.Hash,
+				}).Error
+				if err != nil {
+					return err
+				}
+				last = entry
+			}
+			return nil
+		})
+		if err != nil {
+			return chained, errors.Error{Err: err, Code: errors.EINTERNAL}
+		}
+		chained++
+	}
+
+	return chained, nil
+}
+
+func chainStarted(entries []Statement) bool {
+	for _, entry := range entries {
+		if entry.Hash != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -41,13 +41,31 @@ type Statement struct {
 	Operation    models.TxnOperation
 	DebitAmount  float64
 	CreditAmount float64
-	UserID       uuid.UUID
-	AccountID    uuid.UUID
-	CreatedAt    time.Time
+	// statements are paged through per user, newest first
+	UserID    uuid.UUID `gorm:"index:idx_statements_user_created,priority:1"`
+	AccountID uuid.UUID
+	CreatedAt time.Time `gorm:"index:idx_statements_user_created,priority:2"`
+
+	// balance and outstanding overdraft on the account after the entry
+	Balance          float64
+	OverdraftBalance float64
+
+	// who the money came from or went to, nil for entries with no other party
+	CounterpartyID   uuid.UUID `gorm:"type:uuid"`
+	CounterpartyType models.UserType
+
+	// entries of an account form a hash chain, each entry is numbered and carries the
+	// hash of the one before it, so that editing or removing an entry breaks the chain
+	Sequence uint64 `gorm:"column:sequence;not null;default:0"`
+	PrevHash string `gorm:"column:prev_hash"`
+	Hash     string `gorm:"column:hash"`
 }
 
+// BeforeCreate gives the entry an id, unless it was given one to be hashed with
 func (s *Statement) BeforeCreate(tx *gorm.DB) error {
-	s.ID, _ = uuid.NewV4()
+	if s.ID == uuid.Nil {
+		s.ID, _ = uuid.NewV4()
+	}
 	return nil
 }
 
@@ -21,6 +21,7 @@ package storage
 // THE SOFTWARE.
 
 import (
+	"context"
 	"fmt"
 
 	"gorm.io/gorm"
@@ -31,11 +32,24 @@ type Database struct {
 	*gorm.DB
 }
 
-func (db *Database) Close() {
-	// w
+// Close closes the connections of the pool
+func (db *Database) Close() error {
 	d, err := db.DB.DB()
-	err = d.Close()
 	if err != nil {
-		fmt.Printf("Error closing db: %v\n", err)
+		return fmt.Errorf("error closing db: %w", err)
 	}
+
+	if err := d.Close(); err != nil {
+		return fmt.Errorf("error closing db: %w", err)
+	}
+	return nil
+}
+
+// Ping checks that the database can be reached
+func (db *Database) Ping(ctx context.Context) error {
+	d, err := db.DB.DB()
+	if err != nil {
+		return err
+	}
+	return d.PingContext(ctx)
 }
@@ -21,28 +21,343 @@ package postgres
 // THE SOFTWARE.
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"math"
+	"time"
 
+	"github.com/bhojpur/wallet/pkg/estatement"
+	"github.com/bhojpur/wallet/pkg/helpers"
+	"github.com/bhojpur/wallet/pkg/interest"
 	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/pocket"
+	"github.com/bhojpur/wallet/pkg/receipt"
+	"github.com/bhojpur/wallet/pkg/reconciliation"
 	"github.com/bhojpur/wallet/pkg/statement"
 	"github.com/bhojpur/wallet/pkg/storage"
 	"github.com/bhojpur/wallet/pkg/tariff"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// migratedModels are the models that have tables in the db
+var migratedModels = []interface{}{
+	models.User{},
+	models.Admin{},
+	models.Agent{},
+	models.Merchant{},
+	models.Subscriber{},
+	models.Account{},
+	models.Transaction{},
+	statement.Statement{},
+	statement.Checkpoint{},
+	tariff.Charge{},
+	interest.Rate{},
+	interest.Accrual{},
+	pocket.Pocket{},
+	estatement.Document{},
+	reconciliation.Mismatch{},
+	receipt.Record{},
+}
+
 // Migrate updates the db with new columns, and tables
 func Migrate(database *storage.Database) {
-	err := database.DB.AutoMigrate(
-		models.Admin{},
-		models.Agent{},
-		models.Merchant{},
-		models.Subscriber{},
-		models.Account{},
-		models.Transaction{},
-		statement.Statement{},
-		tariff.Charge{},
-	)
+	err := database.DB.AutoMigrate(migratedModels...)
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	// the unique index on the accruals of a day was replaced by one that leaves out carried accruals
+	if migrator := database.DB.Migrator(); migrator.HasIndex(&interest.Accrual{}, "idx_accrual_account_day") {
+		if err := migrator.DropIndex(&interest.Accrual{}, "idx_accrual_account_day"); err != nil {
+			log.Println(err)
+		}
+	}
+
+	backfillNumbers(database)
+	backfillUsers(database)
+	// balances are hashed with the entries, they are filled in before the chain is linked
+	backfillBalances(database)
+	chainLedger(database)
+}
+
+// CheckMigrations checks that the db has the tables of the models, with all
+// their columns
+func CheckMigrations(ctx context.Context, database *storage.Database) error {
+	db := database.DB.WithContext(ctx)
+	for _, model := range migratedModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+
+		columnTypes, err := db.Migrator().ColumnTypes(model)
+		if err != nil {
+			return err
+		}
+		if len(columnTypes) == 0 {
+			return fmt.Errorf("table %s is missing", stmt.Schema.Table)
+		}
+
+		columns := make(map[string]bool, len(columnTypes))
+		for _, columnType := range columnTypes {
+			columns[columnType.Name()] = true
+		}
+		for _, name := range stmt.Schema.DBNames {
+			if !columns[name] {
+				return fmt.Errorf("column %s of table %s is missing", name, stmt.Schema.Table)
+			}
+		}
+	}
+
+	return nil
+}
+
+// backfillNumbers assigns generated numbers to rows created before account,
+// till and agent numbers were introduced.
+func backfillNumbers(database *storage.Database) {
+	columns := []struct {
+		model    interface{}
+		column   string
+		generate func() string
+	}{
+		{models.Account{}, "account_number", models.NewAccountNumber},
+		{models.Merchant{}, "till_number", models.NewTillNumber},
+		{models.Agent{}, "agent_number", models.NewAgentNumber},
+	}
+
+	for _, c := range columns {
+		var ids []uuid.UUID
+		err := database.DB.Model(c.model).
+			Where(c.column+" IS NULL OR "+c.column+" = ''").
+			Pluck("id", &ids).Error
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+
+		for _, id := range ids {
+			for attempt := 0; attempt < models.NumberGenerationAttempts; attempt++ {
+				err = database.DB.Model(c.model).Where("id = ?", id).Update(c.column, c.generate()).Error
+				if err == nil {
+					break
+				}
+			}
+			if err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
+
+// legacyUser is a user registered before the users directory was introduced
+type legacyUser struct {
+	ID          uuid.UUID
+	Email       string
+	PhoneNumber string
+	// till number of a merchant or agent number of an agent
+	Number    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// backfillUsers adds users registered before the users directory was introduced
+// to the directory. A user whose email or phone number is already claimed by a
+// user of another type is skipped and logged, it needs to be resolved by hand.
+func backfillUsers(database *storage.Database) {
+	queries := []struct {
+		userType models.UserType
+		query    string
+	}{
+		{models.UserTypAdmin, `SELECT id, email, '' AS phone_number, '' AS number, created_at, updated_at
+			FROM administrators WHERE deleted_at IS NULL AND id NOT IN (SELECT user_id FROM users)`},
+		{models.UserTypAgent, `SELECT id, email, COALESCE(phone_number, '') AS phone_number, COALESCE(agent_number, '') AS number, created_at, updated_at
+			FROM agents WHERE deleted_at IS NULL AND id NOT IN (SELECT user_id FROM users)`},
+		{models.UserTypMerchant, `SELECT id, email, COALESCE(phone_number, '') AS phone_number, COALESCE(till_number, '') AS number, created_at, updated_at
+			FROM merchants WHERE deleted_at IS NULL AND id NOT IN (SELECT user_id FROM users)`},
+		{models.UserTypSubscriber, `SELECT id, email, COALESCE(phone_number, '') AS phone_number, '' AS number, created_at, updated_at
+			FROM subscribers WHERE deleted_at IS NULL AND id NOT IN (SELECT user_id FROM users)`},
+	}
+
+	for _, q := range queries {
+		var legacy []legacyUser
+		if err := database.DB.Raw(q.query).Scan(&legacy).Error; err != nil {
+			log.Println(err)
+			continue
+		}
+
+		for _, l := range legacy {
+			user, err := directoryEntry(l, q.userType)
+			if err != nil {
+				log.Printf("%s %v has phone number %q, it is left out of the users directory: %v", q.userType, l.ID, l.PhoneNumber, err)
+			}
+			if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&user).Error; err != nil {
+				log.Println(err)
+			}
+		}
+	}
+
+	normalizePhoneNumbers(database)
+
+	for _, table := range []string{"administrators", "agents", "merchants", "subscribers"} {
+		var ids []uuid.UUID
+		err := database.DB.Table(table).
+			Where("deleted_at IS NULL AND id NOT IN (SELECT user_id FROM users)").
+			Pluck("id", &ids).Error
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		for _, id := range ids {
+			log.Printf("%s %v clashes with another user's email or phone number and is not in the users directory", table, id)
+		}
+	}
+}
+
+// directoryEntry is the entry of a legacy user in the users directory. Customers are looked
+// up by their phone number in E.164 format, a phone number that can't be put in that format
+// is left out and returned as an error.
+func directoryEntry(legacy legacyUser, userType models.UserType) (models.User, error) {
+	phone, err := helpers.NormalizeOptionalPhoneNumber(legacy.PhoneNumber)
+
+	user := models.NewUser(legacy.ID, userType, legacy.Email, phone, legacy.Number)
+	user.CreatedAt = legacy.CreatedAt
+	user.UpdatedAt = legacy.UpdatedAt
+	return user, err
+}
+
+// normalizePhoneNumbers puts the phone numbers the users directory was backfilled with as they
+// were typed in E.164 format, those that can't be are logged and cleared
+func normalizePhoneNumbers(database *storage.Database) {
+	var users []models.User
+	err := database.DB.Where("phone_number IS NOT NULL AND phone_number NOT LIKE '+%'").Find(&users).Error
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	for _, user := range users {
+		phone, err := helpers.NormalizePhoneNumber(*user.PhoneNumber)
+		if err != nil {
+			log.Printf("%s %v has phone number %q, it is left out of the users directory: %v", user.UserType, user.UserID, *user.PhoneNumber, err)
+		}
+
+		var value interface{}
+		if phone != "" {
+			value = phone
+		}
+		if err := database.DB.Model(&models.User{}).Where("user_id = ?", user.UserID).Update("phone_number", value).Error; err != nil {
+			log.Printf("error normalizing the phone number of %s %v: %v", user.UserType, user.UserID, err)
+		}
+	}
+}
+
+// legacyEntry is a statement entry that may have been recorded before the balance, or the
+// outstanding overdraft, was kept on the entries
+type legacyEntry struct {
+	ID               uuid.UUID
+	DebitAmount      float64
+	CreditAmount     float64
+	Balance          *float64
+	OverdraftBalance *float64
+}
 
+// backfillBalances fills in the balance and outstanding overdraft of the entries recorded
+// before they were kept on the entries, by replaying the entries of the account from its
+// first one. A replay that doesn't end on the balance of the account is logged.
+func backfillBalances(database *storage.Database) {
+	var accountIDs []uuid.UUID
+	err := database.DB.Model(&statement.Statement{}).
+		Where("balance IS NULL OR overdraft_balance IS NULL").
+		Distinct().Pluck("account_id", &accountIDs).Error
 	if err != nil {
 		log.Println(err)
+		return
+	}
+
+	for _, accountID := range accountIDs {
+		var acc models.Account
+		if err := database.DB.Where(models.Account{ID: accountID}).First(&acc).Error; err != nil {
+			log.Printf("error happened while backfilling balances of account %v: %v", accountID, err)
+			continue
+		}
+
+		var entries []legacyEntry
+		err := database.DB.Table(statement.Statement{}.TableName()).
+			Where("account_id = ?", accountID).Order("created_at, id").Find(&entries).Error
+		if err != nil {
+			log.Printf("error happened while backfilling balances of account %v: %v", accountID, err)
+			continue
+		}
+
+		// the replay starts from an empty account of the same type
+		replay := models.Account{AccountType: acc.AccountType}
+		err = database.DB.Transaction(func(tx *gorm.DB) error {
+			for _, entry := range entries {
+				if entry.CreditAmount > 0 {
+					replay.AvailableBalance, replay.OverdraftUsed = replay.Credit(toPaisas(entry.CreditAmount))
+				}
+				if entry.DebitAmount > 0 {
+					replay.AvailableBalance, replay.OverdraftUsed = replay.Debit(toPaisas(entry.DebitAmount))
+				}
+
+				columns := map[string]interface{}{}
+				if entry.Balance == nil {
+					columns["balance"] = replay.Balance()
+				} else {
+					// an entry that kept its balance is right, the replay goes on from it
+					replay.AvailableBalance = toPaisas(*entry.Balance)
+				}
+				if entry.OverdraftBalance == nil {
+					columns["overdraft_balance"] = replay.OverdraftBalance()
+				} else {
+					replay.OverdraftUsed = toPaisas(*entry.OverdraftBalance)
+				}
+				if len(columns) == 0 {
+					continue
+				}
+
+				err := tx.Table(statement.Statement{}.TableName()).Where("id = ?", entry.ID).Updates(columns).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("error happened while backfilling balances of account %v: %v", accountID, err)
+			continue
+		}
+
+		if replay.AvailableBalance != acc.AvailableBalance || replay.OverdraftUsed != acc.OverdraftUsed {
+			log.Printf("account %v has a balance of %v that its entries don't add up to, they make %v", accountID, acc.Balance(), replay.Balance())
+		}
+	}
+}
+
+// toPaisas converts an amount of a statement entry to Paisas
+func toPaisas(amount float64) models.Paisas {
+	return models.Paisas(math.Round(amount * 100))
+}
+
+// chainLedger links the statement entries recorded before the ledger was hash chained,
+// the sequence of a chain is only made unique once every entry has its place in it.
+func chainLedger(database *storage.Database) {
+	chained, err := statement.NewRepository(database).ChainLegacyEntries()
+	if err != nil {
+		log.Printf("error happened while chaining ledger entries %v", err)
+		return
+	}
+	if chained > 0 {
+		log.Printf("chained the ledger entries of %d accounts", chained)
+	}
+
+	err = database.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_statements_account_sequence ON statements (account_id, sequence)").Error
+	if err != nil {
+		log.Printf("error happened while indexing the ledger chain %v", err)
 	}
 }
@@ -0,0 +1,78 @@
+package postgres
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/models"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestDirectoryEntry(t *testing.T) {
+	registered := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		phone    string
+		number   string
+		want     string
+		unparsed bool
+	}{
+		{name: "typed with spaces", phone: "+1 (628) 200-4199", want: "+16282004199"},
+		{name: "international prefix", phone: "0016282004199", number: "482915", want: "+16282004199"},
+		{name: "without a prefix", phone: "16282004199", want: "+16282004199"},
+		{name: "local format", phone: "0712345678", unparsed: true},
+		{name: "not a number", phone: "call me", unparsed: true},
+		{name: "no phone number"},
+	}
+	for _, c := range cases {
+		legacy := legacyUser{
+			ID:          uuid.Must(uuid.NewV4()),
+			Email:       "legacy@bhojpur.net",
+			PhoneNumber: c.phone,
+			Number:      c.number,
+			CreatedAt:   registered,
+			UpdatedAt:   registered,
+		}
+
+		user, err := directoryEntry(legacy, models.UserTypAgent)
+		if (err != nil) != c.unparsed {
+			t.Errorf("%s: directoryEntry error = %v, want an error %v", c.name, err, c.unparsed)
+		}
+
+		var phone string
+		if user.PhoneNumber != nil {
+			phone = *user.PhoneNumber
+		}
+		if phone != c.want || (c.want == "") != (user.PhoneNumber == nil) {
+			t.Errorf("%s: phone number %q becomes %v, want %q", c.name, c.phone, user.PhoneNumber, c.want)
+		}
+
+		if user.UserID != legacy.ID || user.UserType != models.UserTypAgent || user.Email != legacy.Email || !user.CreatedAt.Equal(registered) {
+			t.Errorf("%s: directoryEntry = %+v, want the legacy user", c.name, user)
+		}
+		if (c.number == "") != (user.CustomerNumber == nil) || (user.CustomerNumber != nil && *user.CustomerNumber != c.number) {
+			t.Errorf("%s: customer number %q becomes %v", c.name, c.number, user.CustomerNumber)
+		}
+	}
+}
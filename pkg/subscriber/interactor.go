@@ -68,11 +68,18 @@ func (ui interactor) AuthenticateByEmail(email, password string) (models.Subscri
 // Register takes in a subscriber registration parameters and creates a new subscriber
 // then adds the subscriber to db.
 func (ui interactor) Register(params RegistrationParams) (models.Subscriber, error) {
+	// phone numbers are kept in E.164 format so that customers
+	// can be found by them regardless of how they were typed
+	phoneNumber, err := helpers.NormalizeOptionalPhoneNumber(params.PhoneNumber)
+	if err != nil {
+		return models.Subscriber{}, err
+	}
+
 	subscriber := models.Subscriber{
 		FirstName:   params.FirstName,
 		LastName:    params.LastName,
 		Email:       params.Email,
-		PhoneNumber: params.PhoneNumber,
+		PhoneNumber: phoneNumber,
 		Password:    params.Password,
 		PassportNo:  params.PassportNo,
 	}
@@ -100,5 +107,5 @@ func (ui interactor) Register(params RegistrationParams) (models.Subscriber, err
 // like creating an account for them automatically.
 func (ui interactor) postNewSubscriberToChannel(subscriber *models.Subscriber) {
 	newSubscriber := parseToNewSubscriber(*subscriber)
-	go func() { ui.customersChannel.Writer <- newSubscriber }()
+	ui.customersChannel.Send(newSubscriber)
 }
@@ -24,9 +24,9 @@ import (
 	"github.com/bhojpur/wallet/pkg/errors"
 	"github.com/bhojpur/wallet/pkg/models"
 	"github.com/bhojpur/wallet/pkg/storage"
+	"github.com/bhojpur/wallet/pkg/user"
 
 	"github.com/gofrs/uuid"
-	"github.com/jackc/pgconn"
 	"gorm.io/gorm"
 )
 
@@ -36,6 +36,7 @@ type Repository interface {
 	FetchAll() ([]models.Subscriber, error)
 	FindByID(uuid.UUID) (models.Subscriber, error)
 	FindByEmail(string) (models.Subscriber, error)
+	FindByPhoneNumber(string) (models.Subscriber, error)
 	Update(models.Subscriber) error
 }
 
@@ -61,27 +62,31 @@ func (r repository) searchBy(row models.Subscriber) (models.Subscriber, error) {
 	return subscriber, nil
 }
 
-// Add a subscriber if already not in db.
+// Add a subscriber if not already in db.
 func (r repository) Add(subscriber models.Subscriber) (models.Subscriber, error) {
-	// add new subscriber to subscribers table, if query return violation of unique key column,
-	// we know that the subscriber with given record exists and return that subscriber instead
-	result := r.db.Model(models.Subscriber{}).Create(&subscriber)
-	if err := result.Error; err != nil {
-		// we check if the error is a postgres unique constraint violation
-		if pgerr, ok := err.(*pgconn.PgError); ok && pgerr.Code == "23505" {
-			return subscriber, errors.Error{Code: errors.ECONFLICT, Message: errors.ErrUserExists}
+	err := user.Register(r.db, &subscriber, func() models.User {
+		return models.NewUser(subscriber.ID, models.UserTypSubscriber, subscriber.Email, subscriber.PhoneNumber, "")
+	}, "")
+	if err != nil {
+		if errors.ErrorCode(err) == errors.ECONFLICT {
+			return subscriber, err
 		}
-		return models.Subscriber{}, errors.Error{Err: result.Error, Code: errors.EINTERNAL}
+		return models.Subscriber{}, err
 	}
 
 	return subscriber, nil
 }
 
-// Delete a subscriber
+// Delete a subscriber and their entry in the users directory
 func (r repository) Delete(subscriber models.Subscriber) error {
-	result := r.db.Delete(&subscriber)
-	if result.Error != nil {
-		return errors.Error{Err: result.Error, Code: errors.EINTERNAL}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&subscriber).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", subscriber.ID).Delete(&models.User{}).Error
+	})
+	if err != nil {
+		return errors.Error{Err: err, Code: errors.EINTERNAL}
 	}
 	return nil
 }
@@ -114,6 +119,12 @@ func (r repository) FindByEmail(email string) (models.Subscriber, error) {
 	return subscriber, err
 }
 
+// FindByPhoneNumber searches subscriber by phone number
+func (r repository) FindByPhoneNumber(phone string) (models.Subscriber, error) {
+	subscriber, err := r.searchBy(models.Subscriber{PhoneNumber: phone})
+	return subscriber, err
+}
+
 // Update
 func (r repository) Update(subscriber models.Subscriber) error {
 	var sub models.Subscriber
@@ -21,8 +21,15 @@ package tariff
 // THE SOFTWARE.
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
 	"github.com/bhojpur/wallet/pkg/errors"
 	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/worker"
 
 	"github.com/gofrs/uuid"
 )
@@ -31,18 +38,67 @@ type Manager interface {
 	GetCharge(operation models.TxnOperation, src models.UserType, dest models.UserType) (models.Paisas, error)
 	GetTariff() ([]Charge, error)
 	UpdateCharge(chargeID uuid.UUID, fee models.Paisas) error
+	// Ready tells whether the charges of all valid transactions are set up
+	Ready(ctx context.Context) error
 }
 
-func NewManager(repository Repository) Manager {
-	mgr := &manager{repository}
+// setupRetryInterval is how long the tariff setup waits before trying again
+const setupRetryInterval = 10 * time.Second
+
+func NewManager(repository Repository, workers *worker.Group) Manager {
+	mgr := &manager{repository: repository, setup: &setupState{}}
 
-	go mgr.initTariffSetup()
+	// the setup is quick, stopping the server waits for it
+	workers.GoTask("tariff setup", mgr.runTariffSetup)
 
 	return mgr
 }
 
 type manager struct {
 	repository Repository
+	setup      *setupState
+}
+
+// setupState is the outcome of the tariff setup so far
+type setupState struct {
+	mu   sync.Mutex
+	done bool
+	err  error
+}
+
+func (mg manager) Ready(ctx context.Context) error {
+	mg.setup.mu.Lock()
+	defer mg.setup.mu.Unlock()
+
+	if mg.setup.err != nil {
+		return fmt.Errorf("tariff setup failed: %w", mg.setup.err)
+	}
+	if !mg.setup.done {
+		return fmt.Errorf("tariff setup in progress")
+	}
+	return nil
+}
+
+// runTariffSetup sets up the tariff, and tries again until it succeeds or the
+// server stops, a database not yet reachable at start doesn't leave it unset
+func (mg manager) runTariffSetup(done <-chan struct{}) {
+	for {
+		err := mg.initTariffSetup()
+
+		mg.setup.mu.Lock()
+		mg.setup.done, mg.setup.err = err == nil, err
+		mg.setup.mu.Unlock()
+		if err == nil {
+			return
+		}
+		log.Printf("error happened while setting up the tariff %v", err)
+
+		select {
+		case <-time.After(setupRetryInterval):
+		case <-done:
+			return
+		}
+	}
 }
 
 func (mg manager) GetCharge(operation models.TxnOperation, src models.UserType, dest models.UserType) (models.Paisas, error) {
@@ -129,10 +185,11 @@ func (mg manager) validTransferTx() []ValidTransaction {
 }
 
 func (mg manager) initTariffSetup() error {
-	// add valid withdraw transactions between customers
+	// add valid withdraw transactions between customers, the charges set up
+	// by an earlier start are kept
 	for _, validTx := range mg.validWithdrawTx() {
 		err := mg.addCharge(models.TxnOpWithdraw, validTx[0], validTx[1])
-		if err != nil {
+		if err != nil && errors.ErrorCode(err) != errors.ECONFLICT {
 			return err
 		}
 	}
@@ -140,7 +197,7 @@ func (mg manager) initTariffSetup() error {
 	// add valid transfer transactions between customers
 	for _, validTx := range mg.validTransferTx() {
 		err := mg.addCharge(models.TxnOpTransfer, validTx[0], validTx[1])
-		if err != nil {
+		if err != nil && errors.ErrorCode(err) != errors.ECONFLICT {
 			return err
 		}
 	}
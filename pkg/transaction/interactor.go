@@ -26,6 +26,7 @@ import (
 	"github.com/bhojpur/wallet/pkg/data"
 	"github.com/bhojpur/wallet/pkg/errors"
 	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/worker"
 )
 
 const (
@@ -44,13 +45,13 @@ type interactor struct {
 	// txnEventsChannel data.ChanNewTxnEvents
 }
 
-func NewInteractor(repository Repository, transChan data.ChanNewTransactions) Interactor {
+func NewInteractor(repository Repository, transChan data.ChanNewTransactions, workers *worker.Group) Interactor {
 	intr := &interactor{
 		repository:   repository,
 		transChannel: transChan,
 	}
 
-	go intr.listenOnCreatedTransactions()
+	workers.Go("created transactions listener", intr.listenOnCreatedTransactions)
 
 	return intr
 }
@@ -80,18 +81,29 @@ func (i interactor) AddTransaction(tx models.Transaction) error {
 // 	}
 // }
 
-func (i interactor) listenOnCreatedTransactions() {
+func (i interactor) listenOnCreatedTransactions(done <-chan struct{}) {
 	for {
 		select {
 		case tx := <-i.transChannel.Reader:
-			transaction := parseToTransaction(tx)
-
-			err := i.AddTransaction(*transaction)
-			if err != nil { // if we get an error, it is unexpected, we log it
-				log.Printf("error happened when adding transaction to db %v", err.(errors.Error).Err)
-				return
-			}
-			log.Printf("Transaction %v has been successfully added.", transaction.ID)
+			i.recordTransaction(tx)
+		case <-done:
+			// transactions made before the server stopped are still recorded
+			i.transChannel.Drain(i.recordTransaction)
+			return
 		}
 	}
 }
+
+func (i interactor) recordTransaction(tx data.TransactionContract) {
+	transaction := parseToTransaction(tx)
+
+	err := i.AddTransaction(*transaction)
+	if err != nil { // if we get an error, it is unexpected, we log it
+		if e, ok := err.(errors.Error); ok && e.Err != nil {
+			err = e.Err
+		}
+		log.Printf("error happened when adding transaction to db %v", err)
+		return
+	}
+	log.Printf("Transaction %v has been successfully added.", transaction.ID)
+}
@@ -20,7 +20,11 @@ package transaction
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
 // THE SOFTWARE.
 
-import "github.com/bhojpur/wallet/pkg/models"
+import (
+	"time"
+
+	"github.com/bhojpur/wallet/pkg/models"
+)
 
 type Transaction struct {
 	Source      models.TxnCustomer // where money is coming from
@@ -30,3 +34,11 @@ type Transaction struct {
 	// amount of money if Rupees being transacted
 	Amount models.Rupees
 }
+
+// Completed is a transaction that has been posted to the accounts of both customers
+type Completed struct {
+	Transaction
+	// fee the source was charged on top of the amount
+	Charge      models.Paisas
+	CompletedAt time.Time
+}
@@ -29,9 +29,9 @@ import (
 
 type DepositParams struct {
 	Amount models.Rupees `json:"amount" schema:"amount" form:"amount"`
-	// In a production system, the customer number is usually a generated number for the case
-	// of a merchant of agent and a mobile number for a subscriber , but we are going to use the
-	// customer's email as a replacement
+	// the customer number is any identifier the customer is known by: an account number,
+	// a till number for merchants, an agent number for agents, an E.164 phone number or
+	// an email
 	CustomerNumber string          `json:"accountNo" schema:"accountNo" form:"accountNo"`
 	CustomerType   models.UserType `json:"customerType" schema:"customerType" form:"customerType"`
 }
@@ -50,11 +50,11 @@ func (req DepositParams) Validate() error {
 type TransferParams struct {
 	Amount models.Rupees `json:"amount" schema:"amount" form:"amount"`
 
-	// In a production system, the account number is usually
-	// a generated number, but we are going to use the customer's
-	// email as a replacement
-	DestAccountNo string          `json:"accountNo" schema:"accountNo" form:"accountNo"`
-	DestUserType  models.UserType `json:"customerType" schema:"customerType" form:"customerType"`
+	// the destination account number may be an account number, a till number,
+	// an agent number, an E.164 phone number or an email
+	DestAccountNo string `json:"accountNo" schema:"accountNo" form:"accountNo"`
+	// optional, the customer type is resolved from the account number when left out
+	DestUserType models.UserType `json:"customerType" schema:"customerType" form:"customerType"`
 }
 
 func (req TransferParams) Validate() error {
@@ -62,7 +62,6 @@ func (req TransferParams) Validate() error {
 	err := validation.ValidateStruct(&req,
 		validation.Field(&req.Amount, validation.Required.Error(string(errors.ErrorAmountRequired))),
 		validation.Field(&req.DestAccountNo, validation.Required.Error(string(errors.ErrorAccountNumberRequired))),
-		validation.Field(&req.DestUserType, validation.Required.Error(string(errors.ErrorCustomerTypeRequired))),
 	)
 
 	return errors.ParseValidationErrorMap(err)
@@ -70,9 +69,8 @@ func (req TransferParams) Validate() error {
 
 type WithdrawParams struct {
 	Amount models.Rupees `json:"amount" schema:"amount" form:"amount"`
-	// In a production system, the agent number is usually
-	// a generated number, but we are going to use the agent's
-	// email as a replacement
+	// the agent number assigned when the agent registered, the agent's
+	// account number, phone number or email are accepted as well
 	AgentNumber string `json:"agentNumber" schema:"agentNumber" form:"agentNumber"`
 }
 
@@ -22,6 +22,7 @@ package transaction
 
 import (
 	"log"
+	"time"
 
 	"github.com/bhojpur/wallet/pkg/account"
 	"github.com/bhojpur/wallet/pkg/errors"
@@ -30,7 +31,7 @@ import (
 )
 
 type Transactor interface {
-	Transact(Transaction) error
+	Transact(Transaction) (Completed, error)
 }
 
 func NewTransactor(accountant account.Accountant, manager tariff.Manager) Transactor {
@@ -44,62 +45,62 @@ type transactor struct {
 
 // in mobile money a deposit will happen from the account of an agent to the other customer. The source is the agent's
 // account and destination is the account of the other customer.
-func (tr transactor) deposit(source, destination models.TxnCustomer, amount models.Rupees) error {
+func (tr transactor) deposit(source, destination models.TxnCustomer, amount models.Rupees) (models.Paisas, error) {
 	if amount < minimumDepositAmount {
 		e := errors.ErrAmountBelowMinimum(minimumDepositAmount, errors.DepositAmountBelowMinimum)
-		return errors.Error{Err: e}
+		return 0, errors.Error{Err: e}
 	}
 
 	// the source should always be an agent
 	// a super agent too is allowed to do deposits to other agents
 	if !source.UserType.IsAgent() {
-		return errors.Error{Code: errors.EINVALID, Message: errors.DepositOnlyAtAgent}
+		return 0, errors.Error{Code: errors.EINVALID, Message: errors.DepositOnlyAtAgent}
 	}
 
 	// a super agent is only allowed to deposit to another agent's account
 	if source.UserType == models.UserTypSuperAgent && destination.UserType != models.UserTypAgent {
-		return errors.Error{Code: errors.EINVALID, Message: errors.SuperAgentCantDeposit}
+		return 0, errors.Error{Code: errors.EINVALID, Message: errors.SuperAgentCantDeposit}
 	}
 
 	// a merchant is not allowed to deposit
 	if destination.UserType == models.UserTypMerchant {
-		return errors.Error{Code: errors.EINVALID, Message: errors.CustomerCantDeposit}
+		return 0, errors.Error{Code: errors.EINVALID, Message: errors.CustomerCantDeposit}
 	}
 
 	// get the charge applicable to this transaction
 	// usually depositing has no transaction cost
 
-	srcNewBal, err := tr.accountant.DebitAccount(source.UserID, amount.ToPaisas(), models.TxnOpDeposit)
+	srcNewBal, err := tr.accountant.DebitAccount(source.UserID, amount.ToPaisas(), models.TxnOpDeposit, destination)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	destNewBal, err := tr.accountant.CreditAccount(destination.UserID, amount.ToPaisas(), models.TxnOpDeposit)
+	destNewBal, err := tr.accountant.CreditAccount(destination.UserID, amount.ToPaisas(), models.TxnOpDeposit, source)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	log.Printf("Source balance: %v || Dest balance: %v", srcNewBal, destNewBal)
 
-	return nil
+	return 0, nil
 }
 
 // in mobile money a withdrawal will happen from the account of the customer withdrawing to the agent. The source is the
 // customer's account and the destination is the account of the agent
-func (tr transactor) withdraw(source, destination models.TxnCustomer, amount models.Rupees) error {
+func (tr transactor) withdraw(source, destination models.TxnCustomer, amount models.Rupees) (models.Paisas, error) {
 	if amount < minimumWithdrawalAmount {
 		e := errors.ErrAmountBelowMinimum(minimumWithdrawalAmount, errors.WithdrawAmountBelowMinimum)
-		return errors.Error{Err: e}
+		return 0, errors.Error{Err: e}
 	}
 
 	// a super agent cannot perform withdrawals for customers or withdraw
 	if destination.UserType == models.UserTypSuperAgent || source.UserType == models.UserTypSuperAgent {
-		return errors.Error{Code: errors.EINVALID, Message: errors.SuperAgentCantWithdraw}
+		return 0, errors.Error{Code: errors.EINVALID, Message: errors.SuperAgentCantWithdraw}
 	}
 
 	// the destination should always be an agent
 	if destination.UserType != models.UserTypAgent {
-		return errors.Error{Code: errors.EINVALID, Message: errors.WithdrawalOnlyAtAgent}
+		return 0, errors.Error{Code: errors.EINVALID, Message: errors.WithdrawalOnlyAtAgent}
 	}
 
 	// we can implement a double withdrawal check here. That will prevent a user from
@@ -108,78 +109,87 @@ func (tr transactor) withdraw(source, destination models.TxnCustomer, amount mod
 	// get the charge applicable to this transaction
 	charge, err := tr.tariff.GetCharge(models.TxnOpWithdraw, source.UserType, destination.UserType)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// we apply a transaction fee to the transaction
 	// when withdrawing the source is charged the fee (customer)
 	amt := amount.ToPaisas() + charge
 
-	srcNewBal, err := tr.accountant.DebitAccount(source.UserID, amt, models.TxnOpWithdraw)
+	srcNewBal, err := tr.accountant.DebitAccount(source.UserID, amt, models.TxnOpWithdraw, destination)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	destNewBal, err := tr.accountant.CreditAccount(destination.UserID, amount.ToPaisas(), models.TxnOpWithdraw)
+	destNewBal, err := tr.accountant.CreditAccount(destination.UserID, amount.ToPaisas(), models.TxnOpWithdraw, source)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	log.Printf("Source balance: %v || Dest balance: %v", srcNewBal, destNewBal)
 
-	return nil
+	return charge, nil
 }
 
-func (tr transactor) transfer(source, destination models.TxnCustomer, amount models.Rupees) error {
+func (tr transactor) transfer(source, destination models.TxnCustomer, amount models.Rupees) (models.Paisas, error) {
 	if amount < minimumTransferAmount {
 		e := errors.ErrAmountBelowMinimum(minimumTransferAmount, errors.TransferAmountBelowMinimum)
-		return errors.Error{Err: e}
+		return 0, errors.Error{Err: e}
 	}
 
 	// a super agent is not allowed to make a transfer
 	// can only do a deposit
 	if source.UserType == models.UserTypSuperAgent || destination.UserType == models.UserTypSuperAgent {
-		return errors.Error{Code: errors.EINVALID, Message: errors.SuperAgentCantTransfer}
+		return 0, errors.Error{Code: errors.EINVALID, Message: errors.SuperAgentCantTransfer}
 	}
 
 	// get the charge applicable to this transaction
 	charge, err := tr.tariff.GetCharge(models.TxnOpTransfer, source.UserType, destination.UserType)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// we apply the transaction fee to the transaction
 	// when making a transfer, it is the source that is charged
 	amt := amount.ToPaisas() + charge
 
-	srcNewBal, err := tr.accountant.DebitAccount(source.UserID, amt, models.TxnOpTransfer)
+	srcNewBal, err := tr.accountant.DebitAccount(source.UserID, amt, models.TxnOpTransfer, destination)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	destNewBal, err := tr.accountant.CreditAccount(destination.UserID, amount.ToPaisas(), models.TxnOpTransfer)
+	destNewBal, err := tr.accountant.CreditAccount(destination.UserID, amount.ToPaisas(), models.TxnOpTransfer, source)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	log.Printf("Source balance: %v || Dest balance: %v", srcNewBal, destNewBal)
 
-	return nil
+	return charge, nil
 }
 
-func (tr transactor) Transact(transaction Transaction) error {
+func (tr transactor) Transact(transaction Transaction) (Completed, error) {
 	// cannot transact within the same account
 	if transaction.Source.UserID == transaction.Destination.UserID {
-		return errors.Error{Code: errors.EINVALID, Message: errors.TransactionWithSameAccount}
+		return Completed{}, errors.Error{Code: errors.EINVALID, Message: errors.TransactionWithSameAccount}
 	}
 
+	var charge models.Paisas
+	var err error
+
 	switch transaction.TxnOperation {
 	case models.TxnOpDeposit:
-		return tr.deposit(transaction.Source, transaction.Destination, transaction.Amount)
+		charge, err = tr.deposit(transaction.Source, transaction.Destination, transaction.Amount)
 	case models.TxnOpWithdraw:
-		return tr.withdraw(transaction.Source, transaction.Destination, transaction.Amount)
+		charge, err = tr.withdraw(transaction.Source, transaction.Destination, transaction.Amount)
 	case models.TxnOpTransfer:
-		return tr.transfer(transaction.Source, transaction.Destination, transaction.Amount)
+		charge, err = tr.transfer(transaction.Source, transaction.Destination, transaction.Amount)
+	default:
+		return Completed{}, errors.Error{Code: errors.EINVALID}
+	}
+	if err != nil {
+		return Completed{}, err
 	}
-	return errors.Error{Code: errors.EINVALID}
+
+	return Completed{Transaction: transaction, Charge: charge, CompletedAt: time.Now()}, nil
 }
@@ -0,0 +1,61 @@
+package user
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"strings"
+
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/storage"
+
+	"github.com/jackc/pgconn"
+	"gorm.io/gorm"
+)
+
+// Register adds a customer to their own table and to the users directory in one transaction.
+// newUser gives the entry in the directory once the customer has been given an id. The users
+// directory holds the email, phone number and customer number of every user whatever their
+// type, so a violation of a unique key column in either table means that one of them is taken.
+// A clash on numberColumn, the number the wallet generated for the customer, is not the
+// customer's fault and is reported as ErrNumberTaken so that it can be retried.
+func Register(database *storage.Database, customer interface{}, newUser func() models.User, numberColumn string) error {
+	err := database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(customer).Error; err != nil {
+			return err
+		}
+
+		user := newUser()
+		return tx.Create(&user).Error
+	})
+	if err == nil {
+		return nil
+	}
+
+	// we check if the error is a postgres unique constraint violation
+	if pgerr, ok := err.(*pgconn.PgError); ok && pgerr.Code == "23505" {
+		if numberColumn != "" && (strings.Contains(pgerr.ConstraintName, numberColumn) || strings.Contains(pgerr.ConstraintName, "customer_number")) {
+			return errors.Error{Code: errors.ECONFLICT, Message: errors.ErrNumberTaken}
+		}
+		return errors.Error{Code: errors.ECONFLICT, Message: errors.ErrUserExists}
+	}
+	return errors.Error{Err: err, Code: errors.EINTERNAL}
+}
@@ -0,0 +1,82 @@
+package user
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"github.com/bhojpur/wallet/pkg/errors"
+	"github.com/bhojpur/wallet/pkg/models"
+	"github.com/bhojpur/wallet/pkg/storage"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Repository is the users directory, it resolves any identifier
+// to the user id and user type that own it.
+type Repository interface {
+	FindByID(uuid.UUID) (models.User, error)
+	FindByEmail(string) (models.User, error)
+	FindByPhoneNumber(string) (models.User, error)
+	FindByCustomerNumber(string) (models.User, error)
+}
+
+func NewRepository(database *storage.Database) Repository {
+	return &repository{db: database}
+}
+
+type repository struct {
+	db *storage.Database
+}
+
+func (r repository) searchBy(query interface{}, args ...interface{}) (models.User, error) {
+	var user models.User
+	result := r.db.Where(query, args...).First(&user)
+	// check if no record found.
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return models.User{}, errors.Error{Code: errors.ENOTFOUND}
+	}
+	if err := result.Error; err != nil {
+		return models.User{}, errors.Error{Err: err, Code: errors.EINTERNAL}
+	}
+
+	return user, nil
+}
+
+// FindByID searches user by primary id
+func (r repository) FindByID(id uuid.UUID) (models.User, error) {
+	return r.searchBy("user_id = ?", id)
+}
+
+// FindByEmail searches user by email
+func (r repository) FindByEmail(email string) (models.User, error) {
+	return r.searchBy("email = ?", email)
+}
+
+// FindByPhoneNumber searches user by phone number
+func (r repository) FindByPhoneNumber(phone string) (models.User, error) {
+	return r.searchBy("phone_number = ?", phone)
+}
+
+// FindByCustomerNumber searches user by the till number of a merchant
+// or the agent number of an agent
+func (r repository) FindByCustomerNumber(number string) (models.User, error) {
+	return r.searchBy("customer_number = ?", number)
+}
@@ -1,3 +0,0 @@
-// go:generate sh -c "[ -f ../../_deps/pkg-webui--rice/rice-box.go ] && cp ../../_deps/pkg-webui--rice/rice-box.go ."
-
-package webui
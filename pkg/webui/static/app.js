@@ -0,0 +1,419 @@
+"use strict";
+
+// The web UI talks to the WalletUI and WalletService of the server over gRPC-Web.
+// It makes few calls, so their messages are encoded and decoded by hand instead
+// of with generated code. Field numbers are those of pkg/api/v1/*.proto.
+
+const encoder = new TextEncoder();
+const decoder = new TextDecoder();
+
+const phases = ["unknown", "preparing", "starting", "running", "done", "cleanup", "waiting"];
+const TRIGGER_MANUAL = 1;
+const LOGS_UNSLICED = 1;
+const SLICE_ABANDONED = 0;
+const SLICE_CONTENT = 3;
+const SLICE_FAIL = 5;
+const USER_TYPE_ADMIN = "administrator";
+const CODE_PERMISSION_DENIED = 7;
+const CODE_UNAUTHENTICATED = 16;
+
+let readOnly = true;
+let listening = "";
+// the WalletService only answers administrators, the token of the login is
+// kept for the tab
+let token = sessionStorage.getItem("token") || "";
+let refreshing = null;
+
+// encoding
+
+function varint(n) {
+    const bytes = [];
+    while (n > 127) {
+        bytes.push((n % 128) | 128);
+        n = Math.floor(n / 128);
+    }
+    bytes.push(n);
+    return bytes;
+}
+
+function varintField(number, n) {
+    return n ? [...varint(number * 8), ...varint(n)] : [];
+}
+
+function bytesField(number, bytes) {
+    return [...varint(number * 8 + 2), ...varint(bytes.length), ...bytes];
+}
+
+function stringField(number, s) {
+    return s ? bytesField(number, encoder.encode(s)) : [];
+}
+
+// decoding, a message decodes to the values of its fields by field number
+
+function decode(bytes) {
+    const fields = {};
+    let pos = 0;
+    const readVarint = () => {
+        let n = 0;
+        let shift = 1;
+        let b;
+        do {
+            b = bytes[pos++];
+            n += (b & 127) * shift;
+            shift *= 128;
+        } while (b & 128);
+        return n;
+    };
+
+    while (pos < bytes.length) {
+        const key = readVarint();
+        const number = Math.floor(key / 8);
+        let value;
+        switch (key % 8) {
+        case 0:
+            value = readVarint();
+            break;
+        case 1:
+            value = bytes.subarray(pos, pos + 8);
+            pos += 8;
+            break;
+        case 2: {
+            const length = readVarint();
+            value = bytes.subarray(pos, pos + length);
+            pos += length;
+            break;
+        }
+        case 5:
+            value = bytes.subarray(pos, pos + 4);
+            pos += 4;
+            break;
+        default:
+            throw new Error(`unsupported wire type ${key % 8}`);
+        }
+        (fields[number] = fields[number] || []).push(value);
+    }
+    return fields;
+}
+
+function last(fields, number) {
+    const values = fields[number];
+    return values ? values[values.length - 1] : undefined;
+}
+
+function str(fields, number) {
+    const value = last(fields, number);
+    return value ? decoder.decode(value) : "";
+}
+
+function num(fields, number) {
+    return last(fields, number) || 0;
+}
+
+function msg(fields, number) {
+    return decode(last(fields, number) || new Uint8Array());
+}
+
+function list(fields, number) {
+    return (fields[number] || []).map(decode);
+}
+
+function timestamp(fields, number) {
+    if (!fields[number]) {
+        return null;
+    }
+    const ts = msg(fields, number);
+    return new Date(num(ts, 1) * 1000 + num(ts, 2) / 1e6);
+}
+
+// calls
+
+// call makes a gRPC-Web call and returns the decoded messages of the response,
+// one for unary calls and any number for server streams. A failed call throws
+// an error with the gRPC status code.
+async function call(method, request) {
+    const body = new Uint8Array(5 + request.length);
+    new DataView(body.buffer).setUint32(1, request.length);
+    body.set(request, 5);
+
+    const headers = { "content-type": "application/grpc-web+proto", "x-grpc-web": "1" };
+    if (token) {
+        headers.authorization = `Bearer ${token}`;
+    }
+    const res = await fetch(`/${method}`, { method: "POST", headers, body });
+    if (!res.ok) {
+        throw new Error(`${method}: HTTP ${res.status}`);
+    }
+
+    // calls failing right away send their status in the headers, others in a trailer frame
+    const trailers = {
+        "grpc-status": res.headers.get("grpc-status"),
+        "grpc-message": res.headers.get("grpc-message"),
+    };
+    const messages = [];
+    const data = new Uint8Array(await res.arrayBuffer());
+    for (let pos = 0; pos + 5 <= data.length;) {
+        const flags = data[pos];
+        const length = new DataView(data.buffer, data.byteOffset + pos + 1, 4).getUint32(0);
+        const frame = data.subarray(pos + 5, pos + 5 + length);
+        pos += 5 + length;
+
+        if (flags & 0x80) {
+            for (const line of decoder.decode(frame).split("\r\n")) {
+                const i = line.indexOf(":");
+                if (i > 0) {
+                    trailers[line.slice(0, i).trim().toLowerCase()] = line.slice(i + 1).trim();
+                }
+            }
+        } else {
+            messages.push(decode(frame));
+        }
+    }
+
+    const code = Number(trailers["grpc-status"] || 0);
+    if (code !== 0) {
+        const err = new Error(decodeURIComponent(trailers["grpc-message"] || `${method} failed with code ${code}`));
+        err.code = code;
+        throw err;
+    }
+    return messages;
+}
+
+async function login(email, password) {
+    const request = [...stringField(1, USER_TYPE_ADMIN), ...stringField(2, email), ...stringField(3, password)];
+    const [res] = await call("v1.WalletOperations/Login", request);
+    return str(res, 3);
+}
+
+async function isReadOnly() {
+    const [res] = await call("v1.WalletUI/IsReadOnly", []);
+    return num(res, 1) === 1;
+}
+
+async function listEngineSpecs() {
+    const res = await call("v1.WalletUI/ListEngineSpecs", []);
+    return res.map((spec) => ({
+        name: str(spec, 2),
+        path: str(spec, 3),
+        description: str(spec, 4),
+        arguments: list(spec, 5).map((argument) => ({
+            name: str(argument, 1),
+            required: num(argument, 2) === 1,
+            description: str(argument, 3),
+        })),
+    }));
+}
+
+function engineStatus(status) {
+    const metadata = msg(status, 2);
+    const conditions = msg(status, 4);
+    return {
+        name: str(status, 1),
+        spec: str(metadata, 7),
+        created: timestamp(metadata, 4),
+        finished: timestamp(metadata, 5),
+        phase: phases[num(status, 3)] || "unknown",
+        success: num(conditions, 1) === 1,
+        details: str(status, 5),
+    };
+}
+
+async function listEngines() {
+    const [res] = await call("v1.WalletService/ListEngines", varintField(4, 50));
+    return list(res, 2).map(engineStatus);
+}
+
+async function startEngine(path, args) {
+    const annotations = Object.entries(args)
+        .filter(([, value]) => value !== "")
+        .flatMap(([key, value]) => bytesField(6, [...stringField(1, key), ...stringField(2, value)]));
+    const metadata = [...varintField(3, TRIGGER_MANUAL), ...annotations];
+    const [res] = await call("v1.WalletService/StartEngine", [...bytesField(1, metadata), ...stringField(2, path)]);
+    return engineStatus(msg(res, 1));
+}
+
+async function stopEngine(name) {
+    await call("v1.WalletService/StopEngine", stringField(1, name));
+}
+
+async function listen(name) {
+    const res = await call("v1.WalletService/Listen", [...stringField(1, name), ...varintField(3, LOGS_UNSLICED)]);
+    const lines = [];
+    for (const event of res) {
+        if (!event[2]) {
+            continue;
+        }
+        const slice = msg(event, 2);
+        switch (num(slice, 2)) {
+        case SLICE_ABANDONED:
+        case SLICE_CONTENT:
+            lines.push(str(slice, 3));
+            break;
+        case SLICE_FAIL:
+            lines.push(`engine failed: ${str(slice, 3)}`);
+            break;
+        }
+    }
+    return lines;
+}
+
+// rendering
+
+function element(tag, props, ...children) {
+    const el = Object.assign(document.createElement(tag), props);
+    el.append(...children);
+    return el;
+}
+
+function formatTime(date) {
+    return date ? date.toLocaleString() : "";
+}
+
+function showError(err) {
+    const el = document.getElementById("error");
+    el.textContent = err ? err.message : "";
+    el.hidden = !err;
+}
+
+async function refreshEngines() {
+    const engines = await listEngines();
+    const rows = engines.map((engine) => {
+        const name = element("a", { textContent: engine.name, onclick: () => showLogs(engine.name) });
+        const actions = element("td");
+        if (!readOnly && engine.phase !== "done") {
+            actions.append(element("button", {
+                textContent: "Stop",
+                onclick: () => stopEngine(engine.name).then(refresh, showError),
+            }));
+        }
+        return element("tr", {},
+            element("td", {}, name),
+            element("td", { textContent: engine.spec }),
+            element("td", { textContent: engine.phase, className: `phase-${engine.phase}` }),
+            element("td", {
+                textContent: engine.phase === "done" ? String(engine.success) : "",
+                className: engine.phase === "done" && !engine.success ? "failed" : "",
+                title: engine.details,
+            }),
+            element("td", { textContent: formatTime(engine.created) }),
+            element("td", { textContent: formatTime(engine.finished) }),
+            actions);
+    });
+    document.getElementById("engines").replaceChildren(...rows);
+}
+
+async function showLogs(name) {
+    listening = name;
+    document.getElementById("logs").hidden = false;
+    document.getElementById("logs-engine").textContent = name;
+    await refreshLogs();
+}
+
+async function refreshLogs() {
+    if (!listening) {
+        return;
+    }
+    const lines = await listen(listening);
+    document.getElementById("logs-content").textContent = lines.join("\n");
+}
+
+function specForm(spec) {
+    const inputs = {};
+    const fields = spec.arguments.map((argument) => {
+        inputs[argument.name] = element("input", {
+            name: argument.name,
+            required: argument.required,
+            placeholder: argument.description,
+            size: 50,
+        });
+        return element("label", { textContent: argument.name }, inputs[argument.name]);
+    });
+
+    const form = element("form", {},
+        element("h3", { textContent: spec.name }),
+        element("p", { textContent: spec.description }),
+        ...fields);
+    if (!readOnly) {
+        form.append(element("button", { type: "submit", textContent: "Start" }));
+    }
+    form.onsubmit = (event) => {
+        event.preventDefault();
+        const args = Object.fromEntries(Object.entries(inputs).map(([name, input]) => [name, input.value.trim()]));
+        startEngine(spec.path, args)
+            .then((engine) => {
+                showError(null);
+                return showLogs(engine.name);
+            })
+            .then(refresh, showError);
+    };
+    return form;
+}
+
+async function refresh() {
+    try {
+        await refreshEngines();
+        await refreshLogs();
+        showError(null);
+    } catch (err) {
+        if (err.code === CODE_UNAUTHENTICATED || err.code === CODE_PERMISSION_DENIED) {
+            showLogin();
+        }
+        showError(err);
+    }
+}
+
+// showLogin asks for the credentials of an administrator, when there is no
+// token or the server refuses it
+function showLogin() {
+    token = "";
+    sessionStorage.removeItem("token");
+    clearInterval(refreshing);
+    document.getElementById("content").hidden = true;
+    document.getElementById("logout").hidden = true;
+    document.getElementById("login").hidden = false;
+}
+
+async function showContent() {
+    document.getElementById("login").hidden = true;
+    document.getElementById("content").hidden = false;
+    document.getElementById("logout").hidden = false;
+
+    await refresh();
+    refreshing = setInterval(refresh, 5000);
+}
+
+async function init() {
+    try {
+        readOnly = await isReadOnly();
+        document.getElementById("read-only").hidden = !readOnly;
+
+        const specs = await listEngineSpecs();
+        document.getElementById("specs").replaceChildren(...specs.map(specForm));
+    } catch (err) {
+        showError(err);
+    }
+
+    const form = document.getElementById("login");
+    form.onsubmit = (event) => {
+        event.preventDefault();
+        login(form.email.value, form.password.value)
+            .then((t) => {
+                token = t;
+                sessionStorage.setItem("token", t);
+                form.reset();
+                showError(null);
+                return showContent();
+            }, showError);
+    };
+    document.getElementById("logout").onclick = () => {
+        showError(null);
+        showLogin();
+    };
+
+    if (token) {
+        await showContent();
+    } else {
+        showLogin();
+    }
+}
+
+init();
@@ -0,0 +1,157 @@
+package worker
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Group runs the background workers of the domain, the listeners on its
+// channels and its scheduled jobs, so that they can be started and stopped
+// together
+type Group struct {
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+
+	mu sync.Mutex
+	// the workers waiting for Start, until the group is started
+	pending []worker
+	started bool
+	// the workers that returned before being told to stop
+	exited []string
+}
+
+type worker struct {
+	name string
+	run  func(done <-chan struct{})
+	// a task finishes on its own, it hasn't died when it returns
+	task bool
+}
+
+func NewGroup() *Group {
+	return &Group{done: make(chan struct{})}
+}
+
+// Go runs a worker in its own goroutine once the group is started, right away
+// if it is. The worker should return soon after done is closed, once it has
+// finished the work it has at hand.
+func (g *Group) Go(name string, run func(done <-chan struct{})) {
+	g.add(worker{name: name, run: run})
+}
+
+// GoTask runs a task that finishes on its own in its own goroutine, like Go.
+// Stop waits for it like for the workers, but it is not expected to keep running.
+func (g *Group) GoTask(name string, run func(done <-chan struct{})) {
+	g.add(worker{name: name, run: run, task: true})
+}
+
+func (g *Group) add(w worker) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	select {
+	case <-g.done:
+		// a stopped group runs nothing more
+		return
+	default:
+	}
+
+	if !g.started {
+		g.pending = append(g.pending, w)
+		return
+	}
+	g.start(w)
+}
+
+// Start runs the workers added so far. Workers of a group that is never
+// started never run, commands that only use the domain don't start them.
+func (g *Group) Start() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.started {
+		return
+	}
+	g.started = true
+	for _, w := range g.pending {
+		g.start(w)
+	}
+	g.pending = nil
+}
+
+func (g *Group) start(w worker) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		w.run(g.done)
+		if w.task {
+			log.Printf("task %s finished", w.name)
+			return
+		}
+		log.Printf("worker %s stopped", w.name)
+
+		select {
+		case <-g.done:
+		default:
+			g.mu.Lock()
+			g.exited = append(g.exited, w.name)
+			g.mu.Unlock()
+		}
+	}()
+}
+
+// Check tells whether the workers are alive, the ones that returned before
+// being told to stop have died
+func (g *Group) Check(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.exited) > 0 {
+		return fmt.Errorf("workers stopped unexpectedly: %s", strings.Join(g.exited, ", "))
+	}
+	return nil
+}
+
+// Stop tells the workers to stop and waits for them to return, or until ctx
+// ends. Workers still running then are left behind.
+func (g *Group) Stop(ctx context.Context) error {
+	g.mu.Lock()
+	g.stopOnce.Do(func() { close(g.done) })
+	g.mu.Unlock()
+
+	stopped := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("workers did not stop in time: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,116 @@
+package worker
+
+// Copyright (c) 2018 Bhojpur Consulting Private Limited, India. All rights reserved.
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStop(t *testing.T) {
+	group := NewGroup()
+
+	finished := false
+	group.Go("finishing", func(done <-chan struct{}) {
+		<-done
+		time.Sleep(10 * time.Millisecond)
+		finished = true
+	})
+	group.Start()
+
+	if err := group.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !finished {
+		t.Error("Stop returned before the worker finished")
+	}
+}
+
+func TestStart(t *testing.T) {
+	group := NewGroup()
+
+	started := make(chan struct{})
+	group.Go("waiting", func(done <-chan struct{}) {
+		close(started)
+		<-done
+	})
+
+	select {
+	case <-started:
+		t.Fatal("the worker ran before the group was started")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	group.Start()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("the worker didn't run once the group was started")
+	}
+
+	if err := group.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStopDeadline(t *testing.T) {
+	group := NewGroup()
+
+	block := make(chan struct{})
+	defer close(block)
+	group.Go("stuck", func(done <-chan struct{}) {
+		<-block
+	})
+	group.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := group.Stop(ctx); err == nil {
+		t.Error("no error, want Stop to give up on the stuck worker")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	group := NewGroup()
+
+	exited := make(chan struct{})
+	group.GoTask("task", func(done <-chan struct{}) {})
+	group.Go("running", func(done <-chan struct{}) {
+		<-done
+	})
+	group.Go("dying", func(done <-chan struct{}) {
+		close(exited)
+	})
+	group.Start()
+
+	<-exited
+	// the dying worker is recorded after it returns
+	time.Sleep(10 * time.Millisecond)
+	err := group.Check(context.Background())
+	if err == nil || err.Error() != "workers stopped unexpectedly: dying" {
+		t.Errorf("Check() = %v, want the dying worker reported", err)
+	}
+
+	if err := group.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
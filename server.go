@@ -26,7 +26,6 @@ package main
 import (
 	cmd "github.com/bhojpur/wallet/cmd/server"
 
-	_ "github.com/bhojpur/wallet/pkg/webui"
 	_ "github.com/lib/pq"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 )